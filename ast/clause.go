@@ -8,6 +8,7 @@ type TableName struct {
 	StartPos token.Pos
 	EndPos   token.Pos
 	Parts    []string // e.g., ["schema", "table"] or just ["table"]
+	Only     bool     // ONLY t: exclude inheritance children (PostgreSQL)
 }
 
 func (*TableName) tableExprNode()   {}
@@ -45,6 +46,7 @@ type AliasedTableExpr struct {
 	Expr     TableExpr
 	Alias    string
 	Hints    []*IndexHint // USE INDEX, FORCE INDEX, etc.
+	Sample   *TableSample // TABLESAMPLE or SAMPLE clause
 }
 
 func (*AliasedTableExpr) tableExprNode()   {}
@@ -77,6 +79,23 @@ const (
 	HintForGroupBy
 )
 
+// TableSample represents a TABLESAMPLE clause, or the SAMPLE clause of
+// Oracle and Snowflake:
+//
+//	TABLESAMPLE SYSTEM (10) REPEATABLE (42)
+//	TABLESAMPLE (100 ROWS)
+//	SAMPLE BLOCK (5) SEED (1)
+type TableSample struct {
+	StartPos   token.Pos
+	Keyword    string // TABLESAMPLE or SAMPLE
+	Method     string // SYSTEM, BERNOULLI, BLOCK, etc.; empty if omitted
+	Size       Expr   // a percentage unless Rows is set
+	Percent    bool   // explicit PERCENT
+	Rows       bool   // size is a row count
+	Repeatable Expr   // REPEATABLE (seed)
+	Seed       Expr   // SEED (seed), Oracle and Snowflake
+}
+
 // JoinExpr represents a JOIN.
 type JoinExpr struct {
 	StartPos token.Pos
@@ -99,6 +118,10 @@ const (
 	JoinRight
 	JoinFull
 	JoinCross
+	JoinLeftSemi  // LEFT SEMI JOIN (Spark SQL): left rows with a match
+	JoinLeftAnti  // LEFT ANTI JOIN (Spark SQL): left rows without a match
+	JoinRightSemi // RIGHT SEMI JOIN: right rows with a match
+	JoinRightAnti // RIGHT ANTI JOIN: right rows without a match
 )
 
 func (j JoinType) String() string {
@@ -113,6 +136,14 @@ func (j JoinType) String() string {
 		return "FULL"
 	case JoinCross:
 		return "CROSS"
+	case JoinLeftSemi:
+		return "LEFT SEMI"
+	case JoinLeftAnti:
+		return "LEFT ANTI"
+	case JoinRightSemi:
+		return "RIGHT SEMI"
+	case JoinRightAnti:
+		return "RIGHT ANTI"
 	default:
 		return "UNKNOWN"
 	}
@@ -139,9 +170,19 @@ type OrderByExpr struct {
 	EndPos     token.Pos
 	Expr       Expr
 	Desc       bool
-	NullsFirst *bool // nil = unspecified, true = NULLS FIRST, false = NULLS LAST
+	Direction  SortDirection // direction as written; Desc is set for SortDesc
+	NullsFirst *bool         // nil = unspecified, true = NULLS FIRST, false = NULLS LAST
 }
 
+// SortDirection records the sort direction keyword of an ORDER BY item.
+type SortDirection int
+
+const (
+	SortDefault SortDirection = iota // no ASC or DESC
+	SortAsc
+	SortDesc
+)
+
 func (o *OrderByExpr) Pos() token.Pos { return o.StartPos }
 func (o *OrderByExpr) End() token.Pos { return o.EndPos }
 
@@ -168,12 +209,34 @@ func (*AliasedExpr) selectExprNode()  {}
 func (a *AliasedExpr) Pos() token.Pos { return a.StartPos }
 func (a *AliasedExpr) End() token.Pos { return a.EndPos }
 
-// StarExpr represents * or table.*.
+// StarExpr represents * or a qualified star such as table.* or schema.table.*,
+// optionally followed by BigQuery/DuckDB column modifiers:
+// * EXCEPT (a, b) REPLACE (x + 1 AS x).
 type StarExpr struct {
-	StartPos     token.Pos
-	EndPos       token.Pos
-	TableName    string // table name for qualified table.*
-	HasQualifier bool   // true if table qualifier was provided (even if empty)
+	StartPos       token.Pos
+	EndPos         token.Pos
+	QualifierParts []string      // qualifier before .*, e.g. ["schema", "table"]; empty for bare *
+	Except         []string      // columns left out by EXCEPT (...) or EXCLUDE (...)
+	Exclude        bool          // Except was written EXCLUDE (DuckDB)
+	Replace        []ReplaceItem // columns replaced by REPLACE (expr AS column, ...)
+}
+
+// ReplaceItem is one entry of a star's REPLACE list: Expr takes the place
+// of Column.
+type ReplaceItem struct {
+	Expr   Expr
+	Column string
+}
+
+// HasQualifier reports whether the star is qualified.
+func (s *StarExpr) HasQualifier() bool { return len(s.QualifierParts) > 0 }
+
+// TableName returns the last qualifier part, or "" for a bare *.
+func (s *StarExpr) TableName() string {
+	if len(s.QualifierParts) == 0 {
+		return ""
+	}
+	return s.QualifierParts[len(s.QualifierParts)-1]
 }
 
 func (*StarExpr) selectExprNode()  {}
@@ -181,6 +244,41 @@ func (*StarExpr) exprNode()        {} // Can appear as expression too (COUNT(*))
 func (s *StarExpr) Pos() token.Pos { return s.StartPos }
 func (s *StarExpr) End() token.Pos { return s.EndPos }
 
+// GroupingSetType indicates the kind of grouping set in GROUP BY.
+type GroupingSetType int
+
+const (
+	GroupingSets GroupingSetType = iota // GROUPING SETS (...)
+	Rollup                              // ROLLUP (...)
+	Cube                                // CUBE (...)
+)
+
+// String returns the SQL keyword for the grouping set type.
+func (t GroupingSetType) String() string {
+	switch t {
+	case Rollup:
+		return "ROLLUP"
+	case Cube:
+		return "CUBE"
+	default:
+		return "GROUPING SETS"
+	}
+}
+
+// GroupingSetExpr represents ROLLUP, CUBE, or GROUPING SETS in GROUP BY.
+// Each entry in Sets is one grouping list; an empty entry is the empty
+// grouping set ().
+type GroupingSetExpr struct {
+	StartPos token.Pos
+	EndPos   token.Pos
+	Type     GroupingSetType
+	Sets     [][]Expr
+}
+
+func (*GroupingSetExpr) exprNode()        {}
+func (g *GroupingSetExpr) Pos() token.Pos { return g.StartPos }
+func (g *GroupingSetExpr) End() token.Pos { return g.EndPos }
+
 // WindowSpec represents window function specification.
 type WindowSpec struct {
 	StartPos    token.Pos
@@ -202,9 +300,10 @@ type WindowDef struct {
 
 // WindowFrame represents window frame specification.
 type WindowFrame struct {
-	Type  FrameType // ROWS, RANGE, GROUPS
-	Start *FrameBound
-	End   *FrameBound
+	Type    FrameType // ROWS, RANGE, GROUPS
+	Start   *FrameBound
+	End     *FrameBound
+	Exclude FrameExclude // EXCLUDE clause (SQL:2011)
 }
 
 // FrameType indicates the type of window frame.
@@ -216,6 +315,17 @@ const (
 	FrameGroups
 )
 
+// FrameExclude indicates the frame exclusion of a window frame.
+type FrameExclude int
+
+const (
+	ExcludeNone FrameExclude = iota // no EXCLUDE clause
+	ExcludeCurrentRow
+	ExcludeGroup
+	ExcludeTies
+	ExcludeNoOthers
+)
+
 // FrameBound represents a window frame boundary.
 type FrameBound struct {
 	Type   BoundType
@@ -1,13 +1,18 @@
 package ast
 
-import "github.com/freeeve/machparse/token"
+import (
+	"strings"
+
+	"github.com/freeeve/machparse/token"
+)
 
 // ColName represents a column reference with optional qualifiers.
 // Supports multi-level identifiers like catalog.schema.table.column.
 type ColName struct {
-	StartPos token.Pos
-	EndPos   token.Pos
-	Parts    []string // e.g., ["schema", "table", "column"] or just ["column"]
+	StartPos  token.Pos
+	EndPos    token.Pos
+	Parts     []string // e.g., ["schema", "table", "column"] or just ["column"]
+	OuterJoin bool     // Oracle (+) outer join marker: t.id(+)
 }
 
 func (*ColName) exprNode()        {}
@@ -46,12 +51,23 @@ func (c *ColName) Catalog() string {
 	return c.Parts[len(c.Parts)-4]
 }
 
+// IsExcluded reports whether the column references the PostgreSQL EXCLUDED
+// pseudo-table, as used in ON CONFLICT DO UPDATE (e.g. EXCLUDED.name).
+func (c *ColName) IsExcluded() bool {
+	return len(c.Parts) == 2 && strings.EqualFold(c.Parts[0], "excluded")
+}
+
 // Literal represents a literal value.
 type Literal struct {
 	StartPos token.Pos
 	EndPos   token.Pos
 	Type     LiteralType
 	Value    string
+
+	// DollarQuoted marks a string written $tag$...$tag$ (PostgreSQL),
+	// with DollarTag the tag between the dollar signs, "" for $$...$$.
+	DollarQuoted bool
+	DollarTag    string
 }
 
 // LiteralType indicates the type of literal.
@@ -63,13 +79,27 @@ const (
 	LiteralFloat
 	LiteralString
 	LiteralBool
-	LiteralBlob
+	LiteralBlob    // X'...' or b'...', Value holds the literal as written
+	LiteralDefault // DEFAULT keyword in VALUES rows and expressions
 )
 
 func (*Literal) exprNode()        {}
 func (l *Literal) Pos() token.Pos { return l.StartPos }
 func (l *Literal) End() token.Pos { return l.EndPos }
 
+// TypedLiteral represents a string literal prefixed with its type, as in
+// DATE '2024-01-01', TIME '12:00:00' or TIMESTAMP '2024-01-01 00:00:00'.
+type TypedLiteral struct {
+	StartPos token.Pos
+	EndPos   token.Pos
+	Type     string // DATE, TIME or TIMESTAMP
+	Value    string // string contents, without quotes
+}
+
+func (*TypedLiteral) exprNode()        {}
+func (t *TypedLiteral) Pos() token.Pos { return t.StartPos }
+func (t *TypedLiteral) End() token.Pos { return t.EndPos }
+
 // BinaryExpr represents a binary operation.
 type BinaryExpr struct {
 	StartPos token.Pos
@@ -83,6 +113,29 @@ func (*BinaryExpr) exprNode()        {}
 func (b *BinaryExpr) Pos() token.Pos { return b.StartPos }
 func (b *BinaryExpr) End() token.Pos { return b.EndPos }
 
+// ExprPrecedence returns the binding precedence of e when used as an operand
+// of an operator (see token.Precedence). Non-operator expressions bind
+// tightest and return token.PrecHighest.
+func ExprPrecedence(e Expr) int {
+	switch n := e.(type) {
+	case *BinaryExpr:
+		return token.Precedence(n.Op)
+	case *UnaryExpr:
+		if n.Op == token.NOT {
+			return token.PrecNot
+		}
+		return token.PrecUnary
+	case *PostfixExpr:
+		return token.PrecUnary
+	case *IsExpr, *InExpr, *BetweenExpr, *LikeExpr, *PeriodExpr:
+		return token.PrecComparison
+	case *CollateExpr:
+		return token.PrecCollate
+	default:
+		return token.PrecHighest
+	}
+}
+
 // UnaryExpr represents a unary operation.
 type UnaryExpr struct {
 	StartPos token.Pos
@@ -95,6 +148,19 @@ func (*UnaryExpr) exprNode()        {}
 func (u *UnaryExpr) Pos() token.Pos { return u.StartPos }
 func (u *UnaryExpr) End() token.Pos { return u.EndPos }
 
+// PostfixExpr represents a postfix operation such as PostgreSQL's
+// deprecated factorial operator (5!). Only ! is supported.
+type PostfixExpr struct {
+	StartPos token.Pos
+	EndPos   token.Pos
+	Op       token.Token // BANG
+	Operand  Expr
+}
+
+func (*PostfixExpr) exprNode()        {}
+func (p *PostfixExpr) Pos() token.Pos { return p.StartPos }
+func (p *PostfixExpr) End() token.Pos { return p.EndPos }
+
 // ParenExpr represents a parenthesized expression.
 type ParenExpr struct {
 	StartPos token.Pos
@@ -106,34 +172,96 @@ func (*ParenExpr) exprNode()        {}
 func (p *ParenExpr) Pos() token.Pos { return p.StartPos }
 func (p *ParenExpr) End() token.Pos { return p.EndPos }
 
-// FuncExpr represents a function call.
-type FuncExpr struct {
+// TupleExpr represents a parenthesized expression list, a row constructor
+// such as (1, 2) in (1, 2)::point or (a, b) = (1, 2).
+type TupleExpr struct {
 	StartPos token.Pos
 	EndPos   token.Pos
-	Name     string
-	Distinct bool // COUNT(DISTINCT ...)
-	Args     []Expr
-	OrderBy  []*OrderByExpr // For aggregate functions with ORDER BY
-	Filter   Expr           // FILTER (WHERE ...) clause
-	Over     *WindowSpec    // Window function OVER clause
+	Exprs    []Expr
+}
+
+func (*TupleExpr) exprNode()        {}
+func (t *TupleExpr) Pos() token.Pos { return t.StartPos }
+func (t *TupleExpr) End() token.Pos { return t.EndPos }
+
+// FuncExpr represents a function call.
+type FuncExpr struct {
+	StartPos     token.Pos
+	EndPos       token.Pos
+	Name         string
+	Distinct     bool // COUNT(DISTINCT ...)
+	Args         []Expr
+	ArgNames     []string       // name => arg names parallel to Args, "" for positional; nil if none are named
+	OrderBy      []*OrderByExpr // For aggregate functions with ORDER BY
+	WithinGroup  []*OrderByExpr // WITHIN GROUP (ORDER BY ...) of an ordered-set aggregate
+	IgnoreNulls  bool           // IGNORE NULLS after the arguments (LAG, FIRST_VALUE, ...)
+	RespectNulls bool           // RESPECT NULLS, the default, written explicitly
+	Filter       Expr           // FILTER (WHERE ...) clause
+	Over         *WindowSpec    // Window function OVER clause
 }
 
 func (*FuncExpr) exprNode()        {}
 func (f *FuncExpr) Pos() token.Pos { return f.StartPos }
 func (f *FuncExpr) End() token.Pos { return f.EndPos }
 
+// IsAggregate reports whether the function is a known aggregate function.
+func (f *FuncExpr) IsAggregate() bool {
+	return IsAggregateFunc(f.Name)
+}
+
+// aggregateFuncs lists known aggregate function names (uppercase).
+// GROUPING is included because, like an aggregate, it is only valid in the
+// select list, HAVING, and ORDER BY of a grouped query.
+var aggregateFuncs = map[string]bool{
+	"COUNT": true, "SUM": true, "AVG": true, "MIN": true, "MAX": true,
+	"ARRAY_AGG": true, "STRING_AGG": true, "GROUP_CONCAT": true, "LISTAGG": true,
+	"JSON_AGG": true, "JSONB_AGG": true, "JSON_OBJECT_AGG": true, "JSONB_OBJECT_AGG": true,
+	"JSON_ARRAYAGG": true, "JSON_OBJECTAGG": true, "XMLAGG": true,
+	"BOOL_AND": true, "BOOL_OR": true, "EVERY": true,
+	"BIT_AND": true, "BIT_OR": true, "BIT_XOR": true,
+	"STDDEV": true, "STDDEV_POP": true, "STDDEV_SAMP": true,
+	"VARIANCE": true, "VAR_POP": true, "VAR_SAMP": true,
+	"CORR": true, "COVAR_POP": true, "COVAR_SAMP": true,
+	"PERCENTILE_CONT": true, "PERCENTILE_DISC": true, "MODE": true,
+	"ANY_VALUE": true, "GROUPING": true,
+}
+
+// IsAggregateFunc reports whether name (case-insensitive) is a known
+// aggregate function.
+func IsAggregateFunc(name string) bool {
+	return aggregateFuncs[strings.ToUpper(name)]
+}
+
 // CastExpr represents CAST(expr AS type).
 type CastExpr struct {
 	StartPos token.Pos
 	EndPos   token.Pos
 	Expr     Expr
 	Type     *DataType
+	Postgres bool // written expr::type rather than CAST(expr AS type)
 }
 
 func (*CastExpr) exprNode()        {}
 func (c *CastExpr) Pos() token.Pos { return c.StartPos }
 func (c *CastExpr) End() token.Pos { return c.EndPos }
 
+// ConvertExpr represents CONVERT in its dialect forms: MySQL's
+// CONVERT(expr USING charset) and CONVERT(expr, type), and SQL Server's
+// CONVERT(type, expr [, style]).
+type ConvertExpr struct {
+	StartPos  token.Pos
+	EndPos    token.Pos
+	Expr      Expr
+	Type      *DataType // nil in the USING form
+	Charset   string    // USING form only
+	Style     Expr      // SQL Server style argument (optional)
+	TypeFirst bool      // written CONVERT(type, expr)
+}
+
+func (*ConvertExpr) exprNode()        {}
+func (c *ConvertExpr) Pos() token.Pos { return c.StartPos }
+func (c *ConvertExpr) End() token.Pos { return c.EndPos }
+
 // CaseExpr represents CASE expressions.
 type CaseExpr struct {
 	StartPos token.Pos
@@ -182,14 +310,21 @@ func (b *BetweenExpr) Pos() token.Pos { return b.StartPos }
 func (b *BetweenExpr) End() token.Pos { return b.EndPos }
 
 // LikeExpr represents LIKE/ILIKE expression.
+//
+// With a Quantifier, the expression matches any or all of several
+// patterns: Pattern holds the array or subquery operand of
+// LIKE ANY (ARRAY['a%', 'b%']) or LIKE ALL (SELECT ...), and Patterns
+// holds a pattern list such as LIKE ANY ('a%', 'b%') instead.
 type LikeExpr struct {
-	StartPos token.Pos
-	EndPos   token.Pos
-	Expr     Expr
-	Pattern  Expr
-	Not      bool
-	Escape   Expr // ESCAPE character
-	ILike    bool // case-insensitive (PostgreSQL)
+	StartPos   token.Pos
+	EndPos     token.Pos
+	Expr       Expr
+	Pattern    Expr
+	Not        bool
+	Escape     Expr   // ESCAPE character
+	ILike      bool   // case-insensitive (PostgreSQL)
+	Quantifier string // "ANY", "SOME" or "ALL"; empty for a single pattern
+	Patterns   []Expr // pattern list of a quantified LIKE, when Pattern is nil
 }
 
 func (*LikeExpr) exprNode()        {}
@@ -219,6 +354,53 @@ func (*IsExpr) exprNode()        {}
 func (i *IsExpr) Pos() token.Pos { return i.StartPos }
 func (i *IsExpr) End() token.Pos { return i.EndPos }
 
+// PeriodExpr represents a period predicate comparing two periods, given
+// as row values (start, end) or, in SQL:2011, as period names.
+//
+//	(a, b) OVERLAPS (c, d)
+//	period CONTAINS (c, d)
+//	p1 IMMEDIATELY PRECEDES p2
+type PeriodExpr struct {
+	StartPos token.Pos
+	EndPos   token.Pos
+	Left     Expr
+	Op       PeriodOp
+	Right    Expr
+}
+
+// PeriodOp indicates the period predicate.
+type PeriodOp int
+
+const (
+	PeriodOverlaps PeriodOp = iota
+	PeriodContains
+	PeriodPrecedes
+	PeriodSucceeds
+	PeriodImmediatelyPrecedes
+	PeriodImmediatelySucceeds
+)
+
+var periodOpNames = [...]string{
+	PeriodOverlaps:            "OVERLAPS",
+	PeriodContains:            "CONTAINS",
+	PeriodPrecedes:            "PRECEDES",
+	PeriodSucceeds:            "SUCCEEDS",
+	PeriodImmediatelyPrecedes: "IMMEDIATELY PRECEDES",
+	PeriodImmediatelySucceeds: "IMMEDIATELY SUCCEEDS",
+}
+
+// String returns the predicate keywords, e.g. "IMMEDIATELY PRECEDES".
+func (op PeriodOp) String() string {
+	if op >= 0 && int(op) < len(periodOpNames) {
+		return periodOpNames[op]
+	}
+	return "UNKNOWN"
+}
+
+func (*PeriodExpr) exprNode()        {}
+func (p *PeriodExpr) Pos() token.Pos { return p.StartPos }
+func (p *PeriodExpr) End() token.Pos { return p.EndPos }
+
 // Subquery represents a subquery expression.
 type Subquery struct {
 	StartPos token.Pos
@@ -243,6 +425,18 @@ func (*ExistsExpr) exprNode()        {}
 func (e *ExistsExpr) Pos() token.Pos { return e.StartPos }
 func (e *ExistsExpr) End() token.Pos { return e.EndPos }
 
+// UniqueExpr represents the SQL-standard UNIQUE (subquery) predicate, which
+// is true when the subquery returns no duplicate rows.
+type UniqueExpr struct {
+	StartPos token.Pos
+	EndPos   token.Pos
+	Subquery *Subquery
+}
+
+func (*UniqueExpr) exprNode()        {}
+func (u *UniqueExpr) Pos() token.Pos { return u.StartPos }
+func (u *UniqueExpr) End() token.Pos { return u.EndPos }
+
 // Param represents a query parameter.
 type Param struct {
 	StartPos token.Pos
@@ -294,13 +488,51 @@ type IntervalExpr struct {
 	StartPos token.Pos
 	EndPos   token.Pos
 	Value    Expr
-	Unit     string // YEAR, MONTH, DAY, etc.
+	Unit     string // YEAR, DAY_HOUR, DAY TO SECOND, etc.; see IsIntervalUnit
 }
 
 func (*IntervalExpr) exprNode()        {}
 func (i *IntervalExpr) Pos() token.Pos { return i.StartPos }
 func (i *IntervalExpr) End() token.Pos { return i.EndPos }
 
+// intervalUnits holds the recognized interval fields: the single fields,
+// MySQL composites such as DAY_HOUR and SQL-standard ranges such as
+// DAY TO SECOND.
+var intervalUnits = map[string]bool{
+	"MICROSECOND": true, "SECOND": true, "MINUTE": true, "HOUR": true,
+	"DAY": true, "WEEK": true, "MONTH": true, "QUARTER": true, "YEAR": true,
+
+	"SECOND_MICROSECOND": true, "MINUTE_MICROSECOND": true, "MINUTE_SECOND": true,
+	"HOUR_MICROSECOND": true, "HOUR_SECOND": true, "HOUR_MINUTE": true,
+	"DAY_MICROSECOND": true, "DAY_SECOND": true, "DAY_MINUTE": true,
+	"DAY_HOUR": true, "YEAR_MONTH": true,
+
+	"YEAR TO MONTH": true, "DAY TO HOUR": true, "DAY TO MINUTE": true,
+	"DAY TO SECOND": true, "HOUR TO MINUTE": true, "HOUR TO SECOND": true,
+	"MINUTE TO SECOND": true,
+}
+
+// IsIntervalUnit reports whether unit, in any case, is a recognized
+// IntervalExpr unit.
+func IsIntervalUnit(unit string) bool {
+	return intervalUnits[strings.ToUpper(unit)]
+}
+
+// orderedSetAggregates are the functions that take WITHIN GROUP: the
+// standard ordered-set and hypothetical-set aggregates, plus LISTAGG and
+// the SQL Server form of STRING_AGG.
+var orderedSetAggregates = map[string]bool{
+	"PERCENTILE_CONT": true, "PERCENTILE_DISC": true, "MODE": true,
+	"RANK": true, "DENSE_RANK": true, "PERCENT_RANK": true, "CUME_DIST": true,
+	"LISTAGG": true, "STRING_AGG": true,
+}
+
+// IsOrderedSetAggregate reports whether name, in any case, is a function
+// that accepts WITHIN GROUP.
+func IsOrderedSetAggregate(name string) bool {
+	return orderedSetAggregates[strings.ToUpper(name)]
+}
+
 // ExtractExpr represents EXTRACT(field FROM source).
 type ExtractExpr struct {
 	StartPos token.Pos
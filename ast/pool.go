@@ -317,9 +317,17 @@ func ReleaseAST(node Node) {
 		ReleaseAST(n.Operand)
 		ReleaseUnaryExpr(n)
 
+	case *PostfixExpr:
+		ReleaseAST(n.Operand)
+
 	case *ParenExpr:
 		ReleaseAST(n.Expr)
 
+	case *TupleExpr:
+		for _, e := range n.Exprs {
+			ReleaseAST(e)
+		}
+
 	case *Subquery:
 		ReleaseAST(n.Select)
 
@@ -31,6 +31,20 @@ type SelectInto struct {
 	Vars     []string
 }
 
+// TableStmt represents TABLE t, shorthand for SELECT * FROM t (PostgreSQL,
+// MySQL 8).
+type TableStmt struct {
+	StartPos token.Pos
+	EndPos   token.Pos
+	Table    *TableName
+	OrderBy  []*OrderByExpr // ORDER BY expressions
+	Limit    *Limit         // LIMIT clause (optional)
+}
+
+func (*TableStmt) statementNode()   {}
+func (t *TableStmt) Pos() token.Pos { return t.StartPos }
+func (t *TableStmt) End() token.Pos { return t.EndPos }
+
 // InsertStmt represents an INSERT statement.
 type InsertStmt struct {
 	StartPos          token.Pos
@@ -40,8 +54,11 @@ type InsertStmt struct {
 	Ignore            bool        // INSERT IGNORE (MySQL)
 	Table             *TableName
 	Columns           []*ColName    // Column list (optional)
-	Values            [][]Expr      // VALUES rows
+	EmptyColumns      bool          // Empty column list: INSERT INTO t () (MySQL)
+	Values            [][]Expr      // VALUES rows; a row may be empty: VALUES () (MySQL)
+	DefaultValues     bool          // DEFAULT VALUES
 	Select            *SelectStmt   // INSERT ... SELECT
+	SetAssignments    []*UpdateExpr // INSERT ... SET a = 1 (MySQL)
 	OnDuplicateUpdate []*UpdateExpr // ON DUPLICATE KEY UPDATE (MySQL)
 	OnConflict        *OnConflict   // ON CONFLICT (PostgreSQL)
 	Returning         []SelectExpr  // RETURNING clause (PostgreSQL)
@@ -53,10 +70,11 @@ func (i *InsertStmt) End() token.Pos { return i.EndPos }
 
 // OnConflict represents PostgreSQL ON CONFLICT clause.
 type OnConflict struct {
-	Columns   []string // Conflict columns
-	Where     Expr     // Optional WHERE for partial index
-	DoNothing bool
-	Updates   []*UpdateExpr // SET expressions for DO UPDATE
+	Columns     []*IndexColumn // Conflict target (columns or index expressions)
+	Where       Expr           // Optional WHERE for partial index
+	DoNothing   bool
+	Updates     []*UpdateExpr // SET expressions for DO UPDATE
+	UpdateWhere Expr          // Optional WHERE for DO UPDATE
 }
 
 // UpdateStmt represents an UPDATE statement.
@@ -87,7 +105,8 @@ type UpdateExpr struct {
 type DeleteStmt struct {
 	StartPos  token.Pos
 	EndPos    token.Pos
-	With      *WithClause // WITH clause (CTEs)
+	With      *WithClause  // WITH clause (CTEs)
+	Targets   []*TableName // MySQL multi-table form: DELETE t1, t2 FROM ...
 	Table     TableExpr
 	Using     TableExpr // USING clause (PostgreSQL)
 	Where     Expr
@@ -133,9 +152,10 @@ type WithClause struct {
 
 // CTE represents a single common table expression.
 type CTE struct {
-	Name    string
-	Columns []string
-	Query   Statement
+	Name         string
+	Columns      []string
+	Materialized *bool // nil = unspecified, true = MATERIALIZED, false = NOT MATERIALIZED
+	Query        Statement
 }
 
 // CreateTableStmt represents CREATE TABLE.
@@ -147,6 +167,9 @@ type CreateTableStmt struct {
 	Table       *TableName
 	Columns     []*ColumnDef
 	Constraints []*TableConstraint
+	With        []*Option // PostgreSQL WITH (storage_parameter = value, ...)
+	Tablespace  string    // TABLESPACE name
+	Storage     string    // MySQL STORAGE DISK or MEMORY
 	Options     []*TableOption
 	As          *SelectStmt // CREATE TABLE AS SELECT
 }
@@ -160,10 +183,12 @@ type ColumnDef struct {
 	Name        string
 	Type        *DataType
 	Constraints []*ColumnConstraint
+	Comment     string // MySQL COMMENT 'text'
 }
 
 // DataType represents a SQL data type.
 type DataType struct {
+	Schema    string // schema qualifier for user-defined types (myschema.mytype)
 	Name      string // INT, VARCHAR, etc.
 	Length    *int   // VARCHAR(255)
 	Precision *int   // DECIMAL(10,2)
@@ -206,11 +231,12 @@ type GeneratedColumn struct {
 
 // TableConstraint represents a table-level constraint.
 type TableConstraint struct {
-	Name       string
-	Type       ConstraintType
-	Columns    []string
-	References *ForeignKeyRef
-	Check      Expr
+	Name            string
+	Type            ConstraintType
+	Columns         []string
+	References      *ForeignKeyRef
+	Check           Expr
+	IndexTablespace string // PostgreSQL USING INDEX TABLESPACE of a PRIMARY KEY or UNIQUE constraint
 }
 
 // ForeignKeyRef represents foreign key reference.
@@ -232,12 +258,18 @@ const (
 	RefRestrict
 )
 
-// TableOption represents a table option.
-type TableOption struct {
-	Name  string
-	Value string
+// Option represents a name/value option in DDL, such as a MySQL table
+// option or a PostgreSQL storage parameter. Options keep source order.
+type Option struct {
+	Name    string
+	Value   string
+	Quoted  bool // Value was written as a string literal
+	Default bool // written after DEFAULT, as in DEFAULT CHARSET=utf8mb4
 }
 
+// TableOption represents a table option.
+type TableOption = Option
+
 // AlterTableStmt represents ALTER TABLE.
 type AlterTableStmt struct {
 	StartPos token.Pos
@@ -327,6 +359,121 @@ func (*DropTableStmt) statementNode()   {}
 func (d *DropTableStmt) Pos() token.Pos { return d.StartPos }
 func (d *DropTableStmt) End() token.Pos { return d.EndPos }
 
+// CreateDatabaseStmt represents CREATE DATABASE.
+//
+//	CREATE DATABASE [IF NOT EXISTS] name [CHARACTER SET cs] [COLLATE co]
+type CreateDatabaseStmt struct {
+	StartPos    token.Pos
+	EndPos      token.Pos
+	IfNotExists bool
+	Name        string
+	Options     []*Option // CHARSET, COLLATE and other NAME=VALUE options
+}
+
+func (*CreateDatabaseStmt) statementNode()   {}
+func (c *CreateDatabaseStmt) Pos() token.Pos { return c.StartPos }
+func (c *CreateDatabaseStmt) End() token.Pos { return c.EndPos }
+
+// DropDatabaseStmt represents DROP DATABASE.
+type DropDatabaseStmt struct {
+	StartPos token.Pos
+	EndPos   token.Pos
+	IfExists bool
+	Name     string
+}
+
+func (*DropDatabaseStmt) statementNode()   {}
+func (d *DropDatabaseStmt) Pos() token.Pos { return d.StartPos }
+func (d *DropDatabaseStmt) End() token.Pos { return d.EndPos }
+
+// CreateSchemaStmt represents CREATE SCHEMA.
+//
+//	CREATE SCHEMA [IF NOT EXISTS] name [AUTHORIZATION role]  -- PostgreSQL
+//	CREATE SCHEMA AUTHORIZATION role                         -- PostgreSQL
+//	CREATE SCHEMA [IF NOT EXISTS] name [CHARACTER SET cs]    -- MySQL
+type CreateSchemaStmt struct {
+	StartPos      token.Pos
+	EndPos        token.Pos
+	IfNotExists   bool
+	Name          string // empty when only AUTHORIZATION is given
+	Authorization string
+	Options       []*Option // MySQL CHARSET and COLLATE
+}
+
+func (*CreateSchemaStmt) statementNode()   {}
+func (c *CreateSchemaStmt) Pos() token.Pos { return c.StartPos }
+func (c *CreateSchemaStmt) End() token.Pos { return c.EndPos }
+
+// DropSchemaStmt represents DROP SCHEMA.
+type DropSchemaStmt struct {
+	StartPos token.Pos
+	EndPos   token.Pos
+	IfExists bool
+	Names    []string
+	Cascade  bool
+	Restrict bool
+}
+
+func (*DropSchemaStmt) statementNode()   {}
+func (d *DropSchemaStmt) Pos() token.Pos { return d.StartPos }
+func (d *DropSchemaStmt) End() token.Pos { return d.EndPos }
+
+// CreateFunctionStmt represents CREATE FUNCTION and CREATE PROCEDURE. The
+// routine body is kept as the string literal it was written as and is not
+// parsed.
+//
+//	CREATE [OR REPLACE] {FUNCTION | PROCEDURE} name ([arg, ...])
+//	    [RETURNS {[SETOF] type | TABLE (column type, ...)}]
+//	    {LANGUAGE lang | AS 'body' | RETURN expr | characteristic} ...
+type CreateFunctionStmt struct {
+	StartPos  token.Pos
+	EndPos    token.Pos
+	OrReplace bool
+	Procedure bool
+	Name      *TableName
+	Args      []*FuncArg
+	Returns   *DataType  // nil when RETURNS is not given
+	SetOf     bool       // RETURNS SETOF type
+	Table     []*FuncArg // RETURNS TABLE (column type, ...); Returns is nil
+	Language  string
+	Options   []*Option // IMMUTABLE, SECURITY DEFINER, COST 100, ...
+	Body      *Literal  // AS 'body' or AS $$body$$
+	Return    Expr      // RETURN expr
+}
+
+func (*CreateFunctionStmt) statementNode()   {}
+func (c *CreateFunctionStmt) Pos() token.Pos { return c.StartPos }
+func (c *CreateFunctionStmt) End() token.Pos { return c.EndPos }
+
+// CreateSequenceStmt represents CREATE SEQUENCE. Sequence options are kept
+// in source order, named by their normalized keywords: INCREMENT [BY] 5
+// becomes INCREMENT BY with value 5, and NOCYCLE becomes NO CYCLE.
+//
+//	CREATE [TEMPORARY] SEQUENCE [IF NOT EXISTS] name
+//	    [AS type] [INCREMENT [BY] n] [MINVALUE n | NO MINVALUE]
+//	    [MAXVALUE n | NO MAXVALUE] [START [WITH] n] [CACHE n | NOCACHE]
+//	    [[NO] CYCLE] [OWNED BY {table.column | NONE}]
+type CreateSequenceStmt struct {
+	StartPos    token.Pos
+	EndPos      token.Pos
+	Temporary   bool
+	IfNotExists bool
+	Name        *TableName
+	Options     []*Option
+}
+
+func (*CreateSequenceStmt) statementNode()   {}
+func (c *CreateSequenceStmt) Pos() token.Pos { return c.StartPos }
+func (c *CreateSequenceStmt) End() token.Pos { return c.EndPos }
+
+// FuncArg is a parameter in CREATE FUNCTION or CREATE PROCEDURE.
+type FuncArg struct {
+	Mode    string // IN, OUT, INOUT or VARIADIC; empty when not given
+	Name    string // empty for an unnamed parameter
+	Type    *DataType
+	Default Expr
+}
+
 // CreateIndexStmt represents CREATE INDEX.
 type CreateIndexStmt struct {
 	StartPos    token.Pos
@@ -337,8 +484,12 @@ type CreateIndexStmt struct {
 	Name        string
 	Table       *TableName
 	Columns     []*IndexColumn
-	Using       string // btree, hash, etc.
-	Where       Expr   // Partial index (PostgreSQL)
+	Include     []string  // INCLUDE (col, ...) non-key columns of a covering index
+	Using       string    // btree, hash, etc.
+	With        []*Option // PostgreSQL WITH (storage_parameter = value, ...)
+	Tablespace  string    // PostgreSQL TABLESPACE name
+	Options     []*Option // MySQL index options (COMMENT, KEY_BLOCK_SIZE, ...)
+	Where       Expr      // Partial index (PostgreSQL)
 }
 
 func (*CreateIndexStmt) statementNode()   {}
@@ -347,10 +498,11 @@ func (c *CreateIndexStmt) End() token.Pos { return c.EndPos }
 
 // IndexColumn represents a column in an index.
 type IndexColumn struct {
-	Column string
-	Expr   Expr // Expression index
-	Desc   bool
-	Nulls  string // FIRST, LAST
+	Column  string
+	Expr    Expr   // Expression index
+	OpClass string // PostgreSQL operator class, e.g. text_pattern_ops
+	Desc    bool
+	Nulls   string // FIRST, LAST
 }
 
 // DropIndexStmt represents DROP INDEX.
@@ -380,13 +532,176 @@ func (*TruncateStmt) statementNode()   {}
 func (t *TruncateStmt) Pos() token.Pos { return t.StartPos }
 func (t *TruncateStmt) End() token.Pos { return t.EndPos }
 
+// CopyStmt represents PostgreSQL COPY.
+//
+//	COPY t (a, b) FROM STDIN WITH (FORMAT csv, HEADER true)
+//	COPY (SELECT ...) TO '/path' CSV HEADER
+type CopyStmt struct {
+	StartPos      token.Pos
+	EndPos        token.Pos
+	Table         *TableName // COPY table; nil when Query is set
+	Columns       []string
+	Query         Statement // COPY (query) TO ...
+	From          bool      // FROM (load); false for TO (unload)
+	File          string    // file name, or the command when Program is set
+	Program       bool      // PROGRAM 'command'
+	Stdio         bool      // STDIN for FROM, STDOUT for TO
+	Options       []*Option // FORMAT csv, DELIMITER ',', ...
+	LegacyOptions bool      // options were written unparenthesized (CSV HEADER)
+}
+
+func (*CopyStmt) statementNode()   {}
+func (c *CopyStmt) Pos() token.Pos { return c.StartPos }
+func (c *CopyStmt) End() token.Pos { return c.EndPos }
+
+// VacuumStmt represents PostgreSQL VACUUM.
+//
+//	VACUUM [FULL] [VERBOSE] [ANALYZE] [table [, ...]]
+type VacuumStmt struct {
+	StartPos token.Pos
+	EndPos   token.Pos
+	Full     bool
+	Verbose  bool
+	Analyze  bool
+	Tables   []*TableName // empty to vacuum every table
+}
+
+func (*VacuumStmt) statementNode()   {}
+func (v *VacuumStmt) Pos() token.Pos { return v.StartPos }
+func (v *VacuumStmt) End() token.Pos { return v.EndPos }
+
+// AnalyzeStmt represents the ANALYZE maintenance command, which collects
+// table statistics. EXPLAIN ANALYZE is an ExplainStmt.
+//
+//	ANALYZE [VERBOSE] [table [, ...]]   -- PostgreSQL
+//	ANALYZE TABLE table [, ...]         -- MySQL
+type AnalyzeStmt struct {
+	StartPos token.Pos
+	EndPos   token.Pos
+	Table    bool // MySQL TABLE keyword
+	Verbose  bool
+	Tables   []*TableName // empty to analyze every table
+}
+
+func (*AnalyzeStmt) statementNode()   {}
+func (a *AnalyzeStmt) Pos() token.Pos { return a.StartPos }
+func (a *AnalyzeStmt) End() token.Pos { return a.EndPos }
+
+// ShowStmt represents MySQL SHOW.
+//
+//	SHOW [FULL] TABLES [FROM db] [LIKE 'pattern' | WHERE expr]
+//	SHOW [FULL] COLUMNS FROM t [FROM db]
+//	SHOW INDEX FROM t
+//	SHOW CREATE TABLE t
+//	SHOW [GLOBAL | SESSION] VARIABLES [LIKE 'pattern']
+//
+// Other variants keep their leading words in Kind.
+type ShowStmt struct {
+	StartPos token.Pos
+	EndPos   token.Pos
+	Full     bool
+	Scope    string     // GLOBAL, SESSION or LOCAL
+	Kind     string     // upper-case words, e.g. "TABLES" or "CREATE TABLE"
+	Table    *TableName // COLUMNS, INDEX and CREATE variants
+	Database string     // FROM db
+	Like     Expr
+	Where    Expr
+}
+
+func (*ShowStmt) statementNode()   {}
+func (s *ShowStmt) Pos() token.Pos { return s.StartPos }
+func (s *ShowStmt) End() token.Pos { return s.EndPos }
+
+// UseStmt represents MySQL USE db.
+type UseStmt struct {
+	StartPos token.Pos
+	EndPos   token.Pos
+	Database string
+}
+
+func (*UseStmt) statementNode()   {}
+func (u *UseStmt) Pos() token.Pos { return u.StartPos }
+func (u *UseStmt) End() token.Pos { return u.EndPos }
+
+// SetSessionStmt represents a SET statement that changes session state,
+// as opposed to the SET clause of UPDATE.
+//
+//	SET [SESSION | GLOBAL | LOCAL] name = value [, name = value ...]  -- MySQL
+//	SET [SESSION | LOCAL] name {= | TO} value [, value ...]          -- PostgreSQL
+//	SET NAMES 'utf8mb4'
+//	SET TIME ZONE 'UTC'
+type SetSessionStmt struct {
+	StartPos token.Pos
+	EndPos   token.Pos
+	Scope    string // SESSION, GLOBAL or LOCAL; empty if not given
+	Vars     []*SetVar
+}
+
+func (*SetSessionStmt) statementNode()   {}
+func (s *SetSessionStmt) Pos() token.Pos { return s.StartPos }
+func (s *SetSessionStmt) End() token.Pos { return s.EndPos }
+
+// SetVar is one assignment in a SetSessionStmt.
+type SetVar struct {
+	Name   string // as written: search_path, @user_var, @@global.sql_mode, NAMES, TIME ZONE
+	To     bool   // PostgreSQL TO instead of =
+	Values []Expr // several for PostgreSQL list values such as search_path
+}
+
+// DeclareCursorStmt represents DECLARE ... CURSOR.
+//
+//	DECLARE name [BINARY] [INSENSITIVE] [[NO] SCROLL] CURSOR [{WITH | WITHOUT} HOLD] FOR query  -- PostgreSQL
+//	DECLARE name CURSOR FOR query                                                              -- MySQL
+type DeclareCursorStmt struct {
+	StartPos    token.Pos
+	EndPos      token.Pos
+	Name        string
+	Binary      bool
+	Insensitive bool
+	Scroll      string // SCROLL or NO SCROLL; empty if not given
+	Hold        string // WITH HOLD or WITHOUT HOLD; empty if not given
+	Query       Statement
+}
+
+func (*DeclareCursorStmt) statementNode()   {}
+func (d *DeclareCursorStmt) Pos() token.Pos { return d.StartPos }
+func (d *DeclareCursorStmt) End() token.Pos { return d.EndPos }
+
+// FetchStmt represents a cursor FETCH statement. The FETCH FIRST n ROWS
+// clause of SELECT is a Limit, not a FetchStmt.
+//
+//	FETCH [direction] [FROM | IN] cursor  -- PostgreSQL
+//	FETCH [[NEXT] FROM] cursor INTO var [, var ...]  -- MySQL
+//
+// Direction is NEXT, PRIOR, FIRST, LAST, ABSOLUTE, RELATIVE, ALL, FORWARD,
+// FORWARD ALL, BACKWARD or BACKWARD ALL. Count holds n for ABSOLUTE n,
+// RELATIVE n, FORWARD n and BACKWARD n, or for a bare FETCH n with an
+// empty Direction.
+type FetchStmt struct {
+	StartPos  token.Pos
+	EndPos    token.Pos
+	Direction string
+	Count     Expr
+	FromWord  string // FROM or IN; empty if omitted
+	Cursor    string
+	Into      []string // MySQL INTO variables
+}
+
+func (*FetchStmt) statementNode()   {}
+func (f *FetchStmt) Pos() token.Pos { return f.StartPos }
+func (f *FetchStmt) End() token.Pos { return f.EndPos }
+
 // ExplainStmt represents EXPLAIN.
+//
+//	EXPLAIN [ANALYZE] [VERBOSE] [FORMAT=format] stmt  -- MySQL
+//	EXPLAIN (option [, ...]) stmt                    -- PostgreSQL
 type ExplainStmt struct {
 	StartPos token.Pos
 	EndPos   token.Pos
 	Analyze  bool
 	Verbose  bool
 	Format   string // TEXT, JSON, YAML, XML
+	Parens   bool   // options written in parentheses, PostgreSQL style
 	Stmt     Statement
 }
 
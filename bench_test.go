@@ -5,6 +5,8 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+
+	"github.com/freeeve/machparse/format"
 )
 
 var benchQueries = map[string]string{
@@ -151,6 +153,17 @@ func BenchmarkParseByQuery(b *testing.B) {
 	}
 }
 
+func BenchmarkStatementType(b *testing.B) {
+	for name, query := range benchQueries {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = StatementType(query)
+			}
+		})
+	}
+}
+
 func BenchmarkFormatByQuery(b *testing.B) {
 	stmts := make(map[string]Statement)
 	for name, query := range benchQueries {
@@ -168,6 +181,41 @@ func BenchmarkFormatByQuery(b *testing.B) {
 	}
 }
 
+// Benchmark formatting with a new Formatter per statement against one
+// reused through Reset
+func BenchmarkFormatReuse(b *testing.B) {
+	stmt, _ := Parse(benchQueries["complex"])
+
+	b.Run("new", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			f := format.New(format.DefaultOptions)
+			f.Format(stmt)
+			_ = f.String()
+		}
+	})
+
+	b.Run("reset", func(b *testing.B) {
+		f := format.New(format.DefaultOptions)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			f.Reset()
+			f.Format(stmt)
+			_ = f.String()
+		}
+	})
+
+	b.Run("pool", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			f := format.Get(format.DefaultOptions)
+			f.Format(stmt)
+			_ = f.String()
+			format.Put(f)
+		}
+	})
+}
+
 func BenchmarkRoundTrip(b *testing.B) {
 	for name, query := range benchQueries {
 		b.Run(name, func(b *testing.B) {
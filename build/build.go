@@ -0,0 +1,280 @@
+// Package build provides constructors for assembling ASTs programmatically.
+//
+// The helpers return ordinary ast nodes, inserting ParenExpr nodes where
+// operator precedence requires them, so the result formats to SQL that
+// parses back to the same tree:
+//
+//	stmt := build.Select(build.Col("u", "id"), build.Col("u", "name")).
+//		From(build.TableAs(build.Table("users"), "u")).
+//		Where(build.And(
+//			build.Eq(build.Col("u", "active"), build.Bool(true)),
+//			build.Or(build.Gt(build.Col("u", "age"), build.Int(18)), build.IsNull(build.Col("u", "age"))),
+//		)).
+//		Stmt()
+//	sql := format.String(stmt)
+package build
+
+import (
+	"strconv"
+
+	"github.com/freeeve/machparse/ast"
+	"github.com/freeeve/machparse/token"
+)
+
+// Col returns a column reference. Qualifiers come first: Col("t", "x") is t.x.
+func Col(parts ...string) *ast.ColName {
+	return &ast.ColName{Parts: parts}
+}
+
+// Table returns a table name. Qualifiers come first: Table("s", "t") is s.t.
+func Table(parts ...string) *ast.TableName {
+	return &ast.TableName{Parts: parts}
+}
+
+// TableAs returns a table expression with an alias.
+func TableAs(table ast.TableExpr, alias string) *ast.AliasedTableExpr {
+	return &ast.AliasedTableExpr{Expr: table, Alias: alias}
+}
+
+// Star returns an unqualified *.
+func Star() *ast.StarExpr {
+	return &ast.StarExpr{}
+}
+
+// Int returns an integer literal.
+func Int(n int64) *ast.Literal {
+	return &ast.Literal{Type: ast.LiteralInt, Value: strconv.FormatInt(n, 10)}
+}
+
+// Float returns a floating-point literal.
+func Float(f float64) *ast.Literal {
+	return &ast.Literal{Type: ast.LiteralFloat, Value: strconv.FormatFloat(f, 'g', -1, 64)}
+}
+
+// Str returns a string literal. The value is unescaped; the formatter quotes it.
+func Str(s string) *ast.Literal {
+	return &ast.Literal{Type: ast.LiteralString, Value: s}
+}
+
+// Bool returns TRUE or FALSE.
+func Bool(b bool) *ast.Literal {
+	if b {
+		return &ast.Literal{Type: ast.LiteralBool, Value: "TRUE"}
+	}
+	return &ast.Literal{Type: ast.LiteralBool, Value: "FALSE"}
+}
+
+// Null returns NULL.
+func Null() *ast.Literal {
+	return &ast.Literal{Type: ast.LiteralNull, Value: "NULL"}
+}
+
+// Func returns a function call.
+func Func(name string, args ...ast.Expr) *ast.FuncExpr {
+	return &ast.FuncExpr{Name: name, Args: args}
+}
+
+// Binary returns left op right, parenthesizing operands as needed.
+func Binary(op token.Token, left, right ast.Expr) *ast.BinaryExpr {
+	prec := token.Precedence(op)
+	return &ast.BinaryExpr{
+		Op:    op,
+		Left:  paren(left, prec, false),
+		Right: paren(right, prec, true),
+	}
+}
+
+// Eq returns left = right.
+func Eq(left, right ast.Expr) *ast.BinaryExpr { return Binary(token.EQ, left, right) }
+
+// Neq returns left <> right.
+func Neq(left, right ast.Expr) *ast.BinaryExpr { return Binary(token.NEQ, left, right) }
+
+// Lt returns left < right.
+func Lt(left, right ast.Expr) *ast.BinaryExpr { return Binary(token.LT, left, right) }
+
+// Lte returns left <= right.
+func Lte(left, right ast.Expr) *ast.BinaryExpr { return Binary(token.LTE, left, right) }
+
+// Gt returns left > right.
+func Gt(left, right ast.Expr) *ast.BinaryExpr { return Binary(token.GT, left, right) }
+
+// Gte returns left >= right.
+func Gte(left, right ast.Expr) *ast.BinaryExpr { return Binary(token.GTE, left, right) }
+
+// Add returns left + right.
+func Add(left, right ast.Expr) *ast.BinaryExpr { return Binary(token.PLUS, left, right) }
+
+// Sub returns left - right.
+func Sub(left, right ast.Expr) *ast.BinaryExpr { return Binary(token.MINUS, left, right) }
+
+// Mul returns left * right.
+func Mul(left, right ast.Expr) *ast.BinaryExpr { return Binary(token.ASTERISK, left, right) }
+
+// Div returns left / right.
+func Div(left, right ast.Expr) *ast.BinaryExpr { return Binary(token.SLASH, left, right) }
+
+// And joins exprs with AND. It returns nil for no exprs and the expression
+// itself for one.
+func And(exprs ...ast.Expr) ast.Expr { return chain(token.AND, exprs) }
+
+// Or joins exprs with OR. It returns nil for no exprs and the expression
+// itself for one.
+func Or(exprs ...ast.Expr) ast.Expr { return chain(token.OR, exprs) }
+
+// Not returns NOT expr.
+func Not(expr ast.Expr) *ast.UnaryExpr {
+	return &ast.UnaryExpr{Op: token.NOT, Operand: paren(expr, token.PrecNot, false)}
+}
+
+// IsNull returns expr IS NULL.
+func IsNull(expr ast.Expr) *ast.IsExpr {
+	return &ast.IsExpr{Expr: paren(expr, token.PrecComparison, false), What: ast.IsNull}
+}
+
+// IsNotNull returns expr IS NOT NULL.
+func IsNotNull(expr ast.Expr) *ast.IsExpr {
+	return &ast.IsExpr{Expr: paren(expr, token.PrecComparison, false), Not: true, What: ast.IsNull}
+}
+
+// In returns expr IN (values...).
+func In(expr ast.Expr, values ...ast.Expr) *ast.InExpr {
+	return &ast.InExpr{Expr: paren(expr, token.PrecComparison, false), Values: values}
+}
+
+// Like returns expr LIKE pattern.
+func Like(expr, pattern ast.Expr) *ast.LikeExpr {
+	return &ast.LikeExpr{
+		Expr:    paren(expr, token.PrecComparison, false),
+		Pattern: paren(pattern, token.PrecComparison, true),
+	}
+}
+
+// Asc returns an ORDER BY item with an explicit ASC.
+func Asc(expr ast.Expr) *ast.OrderByExpr {
+	return &ast.OrderByExpr{Expr: expr, Direction: ast.SortAsc}
+}
+
+// Desc returns a descending ORDER BY item.
+func Desc(expr ast.Expr) *ast.OrderByExpr {
+	return &ast.OrderByExpr{Expr: expr, Desc: true, Direction: ast.SortDesc}
+}
+
+// Join returns left INNER JOIN right ON on.
+func Join(left, right ast.TableExpr, on ast.Expr) *ast.JoinExpr {
+	return &ast.JoinExpr{Type: ast.JoinInner, Left: left, Right: right, On: on}
+}
+
+// LeftJoin returns left LEFT JOIN right ON on.
+func LeftJoin(left, right ast.TableExpr, on ast.Expr) *ast.JoinExpr {
+	return &ast.JoinExpr{Type: ast.JoinLeft, Left: left, Right: right, On: on}
+}
+
+// SelectBuilder assembles a SELECT statement.
+type SelectBuilder struct {
+	stmt *ast.SelectStmt
+}
+
+// Select starts a SELECT of the given columns.
+func Select(cols ...ast.Expr) *SelectBuilder {
+	b := &SelectBuilder{stmt: &ast.SelectStmt{}}
+	for _, col := range cols {
+		b.Column(col, "")
+	}
+	return b
+}
+
+// Column adds a select expression with an optional alias.
+func (b *SelectBuilder) Column(expr ast.Expr, alias string) *SelectBuilder {
+	if star, ok := expr.(*ast.StarExpr); ok && alias == "" {
+		b.stmt.Columns = append(b.stmt.Columns, star)
+	} else {
+		b.stmt.Columns = append(b.stmt.Columns, &ast.AliasedExpr{Expr: expr, Alias: alias})
+	}
+	return b
+}
+
+// Distinct marks the SELECT as DISTINCT.
+func (b *SelectBuilder) Distinct() *SelectBuilder {
+	b.stmt.Distinct = true
+	return b
+}
+
+// From sets the FROM clause.
+func (b *SelectBuilder) From(table ast.TableExpr) *SelectBuilder {
+	b.stmt.From = table
+	return b
+}
+
+// Where sets the WHERE clause, ANDing it with any existing condition.
+func (b *SelectBuilder) Where(cond ast.Expr) *SelectBuilder {
+	if b.stmt.Where != nil {
+		cond = And(b.stmt.Where, cond)
+	}
+	b.stmt.Where = cond
+	return b
+}
+
+// GroupBy adds GROUP BY expressions.
+func (b *SelectBuilder) GroupBy(exprs ...ast.Expr) *SelectBuilder {
+	b.stmt.GroupBy = append(b.stmt.GroupBy, exprs...)
+	return b
+}
+
+// Having sets the HAVING clause.
+func (b *SelectBuilder) Having(cond ast.Expr) *SelectBuilder {
+	b.stmt.Having = cond
+	return b
+}
+
+// OrderBy adds ORDER BY items.
+func (b *SelectBuilder) OrderBy(items ...*ast.OrderByExpr) *SelectBuilder {
+	b.stmt.OrderBy = append(b.stmt.OrderBy, items...)
+	return b
+}
+
+// Limit sets LIMIT n.
+func (b *SelectBuilder) Limit(n int64) *SelectBuilder {
+	if b.stmt.Limit == nil {
+		b.stmt.Limit = &ast.Limit{}
+	}
+	b.stmt.Limit.Count = Int(n)
+	return b
+}
+
+// Offset sets OFFSET n.
+func (b *SelectBuilder) Offset(n int64) *SelectBuilder {
+	if b.stmt.Limit == nil {
+		b.stmt.Limit = &ast.Limit{}
+	}
+	b.stmt.Limit.Offset = Int(n)
+	return b
+}
+
+// Stmt returns the assembled statement.
+func (b *SelectBuilder) Stmt() *ast.SelectStmt {
+	return b.stmt
+}
+
+// chain joins exprs left-associatively with op.
+func chain(op token.Token, exprs []ast.Expr) ast.Expr {
+	if len(exprs) == 0 {
+		return nil
+	}
+	result := exprs[0]
+	for _, e := range exprs[1:] {
+		result = Binary(op, result, e)
+	}
+	return result
+}
+
+// paren wraps e in a ParenExpr if it binds looser than an operator of the
+// given precedence. Right operands of equal precedence are also wrapped,
+// since operators are left-associative.
+func paren(e ast.Expr, prec int, right bool) ast.Expr {
+	p := ast.ExprPrecedence(e)
+	if p < prec || (right && p == prec) {
+		return &ast.ParenExpr{Expr: e}
+	}
+	return e
+}
@@ -0,0 +1,97 @@
+package build_test
+
+import (
+	"testing"
+
+	"github.com/freeeve/machparse"
+	"github.com/freeeve/machparse/ast"
+	"github.com/freeeve/machparse/build"
+	"github.com/freeeve/machparse/format"
+)
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		name     string
+		node     ast.Node
+		expected string
+	}{
+		{
+			name: "select where",
+			node: build.Select(build.Col("u", "id"), build.Col("u", "name")).
+				From(build.TableAs(build.Table("users"), "u")).
+				Where(build.Eq(build.Col("u", "id"), build.Int(1))).
+				Stmt(),
+			expected: "SELECT u.id, u.name FROM users AS u WHERE u.id = 1",
+		},
+		{
+			name: "or inside and",
+			node: build.Select(build.Star()).
+				From(build.Table("t")).
+				Where(build.And(
+					build.Gt(build.Col("a"), build.Int(1)),
+					build.Or(build.IsNull(build.Col("b")), build.Eq(build.Col("b"), build.Str("x"))),
+				)).
+				Stmt(),
+			expected: "SELECT * FROM t WHERE a > 1 AND (b IS NULL OR b = 'x')",
+		},
+		{
+			name: "repeated where",
+			node: build.Select(build.Col("a")).
+				From(build.Table("t")).
+				Where(build.Eq(build.Col("a"), build.Int(1))).
+				Where(build.Not(build.Eq(build.Col("b"), build.Null()))).
+				Stmt(),
+			expected: "SELECT a FROM t WHERE a = 1 AND NOT b = NULL",
+		},
+		{
+			name:     "arithmetic",
+			node:     build.Mul(build.Add(build.Col("a"), build.Int(1)), build.Sub(build.Col("b"), build.Col("c"))),
+			expected: "(a + 1) * (b - c)",
+		},
+		{
+			name:     "right associativity",
+			node:     build.Sub(build.Col("a"), build.Sub(build.Col("b"), build.Col("c"))),
+			expected: "a - (b - c)",
+		},
+		{
+			name: "join group order limit",
+			node: build.Select(build.Col("u", "id"), build.Func("COUNT", build.Star())).
+				From(build.LeftJoin(
+					build.TableAs(build.Table("users"), "u"),
+					build.TableAs(build.Table("orders"), "o"),
+					build.Eq(build.Col("o", "user_id"), build.Col("u", "id")),
+				)).
+				GroupBy(build.Col("u", "id")).
+				Having(build.Gt(build.Func("COUNT", build.Star()), build.Int(5))).
+				OrderBy(build.Desc(build.Col("u", "id"))).
+				Limit(10).
+				Offset(20).
+				Stmt(),
+			expected: "SELECT u.id, COUNT(*) FROM users AS u LEFT JOIN orders AS o ON o.user_id = u.id GROUP BY u.id HAVING COUNT(*) > 5 ORDER BY u.id DESC LIMIT 10 OFFSET 20",
+		},
+		{
+			name:     "in and like",
+			node:     build.And(build.In(build.Col("a"), build.Int(1), build.Int(2)), build.Like(build.Col("b"), build.Str("x%"))),
+			expected: "a IN (1, 2) AND b LIKE 'x%'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := format.String(tt.node)
+			if got != tt.expected {
+				t.Errorf("got:\n  %s\nwant:\n  %s", got, tt.expected)
+			}
+			if _, ok := tt.node.(ast.Statement); !ok {
+				return
+			}
+			stmt, err := machparse.Parse(got)
+			if err != nil {
+				t.Fatalf("reparse error: %v", err)
+			}
+			if again := format.String(stmt); again != got {
+				t.Errorf("reparse mismatch:\n  %s\nwant:\n  %s", again, got)
+			}
+		})
+	}
+}
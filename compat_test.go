@@ -16,6 +16,18 @@ func TestVitessCompatibility(t *testing.T) {
 		{"select star", "select * from t"},
 		{"select qualified star", "select a.* from t"},
 		{"select qualified star 2 levels", "select a.b.* from t"},
+		{"select qualified star 3 levels", "select a.b.c.* from t"},
+		{"postfix factorial", "select 5!, (-5)!, 3! * 2 from t"},
+		{"negative default", "create table t (a int default -1, b float default -1.5)"},
+		{"negative limit", "select * from t limit -1"},
+		{"row constructor cast", "select (1, 2)::point from t"},
+		{"array type cast", "select data::jsonb[] from t"},
+		{"qualified type cast", "select x::myschema.addr, y::myschema.addr[], row(1, 2)::rec from t"},
+		{"row comparison", "select * from t where (a, b) = (1, 2) and (a, b) in ((1, 2), (3, 4))"},
+		{"update order by nulls", "update t set a = 1 order by b desc nulls last limit 1"},
+		{"delete order by nulls", "delete from t order by b nulls first limit 1"},
+		{"window order by nulls", "select row_number() over (partition by a order by b desc nulls last) from t"},
+		{"oracle outer join", "select * from a, b where a.id = b.id(+) and b.kind(+) = 'x'"},
 		{"select distinct", "select distinct 1 from t"},
 		{"column alias", "select a as b from t"},
 		{"column alias without as", "select a b from t"},
@@ -24,6 +36,8 @@ func TestVitessCompatibility(t *testing.T) {
 		{"where equals", "select * from t where a = 1"},
 		{"where and", "select * from t where a = 1 and b = 2"},
 		{"where or", "select * from t where a = 1 or b = 2"},
+		{"where xor", "select * from t where a = 1 xor b = 2 xor c = 3"},
+		{"bitwise xor", "select a ^ b from t"},
 		{"where in", "select * from t where a in (1, 2, 3)"},
 		{"where not in", "select * from t where a not in (1, 2, 3)"},
 		{"where between", "select * from t where a between 1 and 10"},
@@ -69,6 +83,9 @@ func TestVitessCompatibility(t *testing.T) {
 		{"group by", "select a, count(*) from t group by a"},
 		{"group by multiple", "select a, b, count(*) from t group by a, b"},
 		{"having", "select a, count(*) from t group by a having count(*) > 5"},
+		{"group by rollup", "select a, b, grouping(a) from t group by rollup(a, b) having grouping(a) = 0"},
+		{"group by cube", "select a, b, count(*) from t group by cube((a, b), c)"},
+		{"group by grouping sets", "select a, b from t group by grouping sets ((a, b), (a), rollup(b), ())"},
 
 		// ORDER BY / LIMIT
 		{"order by", "select * from t order by a"},
@@ -138,8 +155,20 @@ func TestVitessCompatibility(t *testing.T) {
 		{"replace", "replace into t (a, b) values (1, 2)"},
 		{"insert on duplicate", "insert into t (a) values (1) on duplicate key update a = 2"},
 		{"insert returning", "insert into t (a) values (1) returning id"},
+		{"insert set", "insert into t set a = 1, b = 'x'"},
+		{"case then default", "select case when x then default else default end from t"},
+		{"insert values default", "insert into t values (default, 1), (2, default)"},
+		{"values statement default", "values (default, 1), (2, default)"},
+		{"from values default", "select * from (values (1, default)) as v"},
+		{"insert set on duplicate", "insert into t set a = 1 on duplicate key update a = a + 1"},
+		{"replace returning", "replace into t (a) values (1) returning id"},
+		{"insert ignore returning", "insert ignore into t (a) values (1) returning id"},
 		{"insert on conflict do nothing", "insert into t (a) values (1) on conflict (a) do nothing"},
 		{"insert on conflict do update", "insert into t (a) values (1) on conflict (a) do update set b = 2"},
+		{"insert on conflict excluded", "insert into t (id, name) values (1, 'a') on conflict (id) do update set name = excluded.name"},
+		{"insert on conflict update where", "insert into t (id, a) values (1, 2) on conflict (id) where active do update set a = 2 where t.a < 2"},
+		{"insert on conflict expression", "insert into t (a) values (1) on conflict ((lower(email))) do nothing"},
+		{"insert on conflict function target", "insert into t (a) values (1) on conflict (lower(email), b) do update set b = 2"},
 
 		// UPDATE variations
 		{"update", "update t set a = 1"},
@@ -147,12 +176,16 @@ func TestVitessCompatibility(t *testing.T) {
 		{"update multiple", "update t set a = 1, b = 2 where c = 3"},
 		{"update with from", "update t set a = t2.a from t2 where t.id = t2.id"},
 		{"update returning", "update t set a = 1 returning *"},
+		{"update multi-table join", "update t1 join t2 on t1.id = t2.id set t1.x = t2.y"},
+		{"update multi-table left join", "update t1 as a left join t2 b on a.id = b.id set a.x = b.y, a.z = 1 where b.w > 0"},
 
 		// DELETE variations
 		{"delete", "delete from t"},
 		{"delete where", "delete from t where a = 1"},
 		{"delete using", "delete from t using t2 where t.id = t2.id"},
 		{"delete returning", "delete from t where a = 1 returning *"},
+		{"delete multi-table", "delete t1 from t1 join t2 on t1.id = t2.id where t2.a = 1"},
+		{"delete multi-table targets", "delete t1, t2 from t1 inner join t2 on t1.id = t2.id"},
 
 		// CREATE TABLE variations
 		{"create table simple", "create table t (id int)"},
@@ -186,6 +219,10 @@ func TestVitessCompatibility(t *testing.T) {
 		{"row_number partition by", "select row_number() over (partition by type order by id) from t"},
 		{"sum over", "select sum(a) over (partition by b) from t"},
 		{"avg over window", "select avg(a) over (order by b rows between 1 preceding and 1 following) from t"},
+		{"window exclude current row", "select sum(a) over (order by b rows between 1 preceding and 1 following exclude current row) from t"},
+		{"window exclude group", "select sum(a) over (order by b groups unbounded preceding exclude group) from t"},
+		{"window exclude ties", "select sum(a) over (order by b range between unbounded preceding and current row exclude ties) from t"},
+		{"window exclude no others", "select sum(a) over (order by b rows unbounded preceding exclude no others) from t"},
 
 		// Locking
 		{"for update", "select * from t for update"},
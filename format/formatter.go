@@ -3,16 +3,56 @@ package format
 
 import (
 	"bytes"
+	"io"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/freeeve/machparse/ast"
 	"github.com/freeeve/machparse/token"
 )
 
-// Options controls formatting behavior.
+// Options controls formatting behavior. No option alters the contents of
+// string literals; they are escaped but otherwise written byte for byte.
 type Options struct {
 	Uppercase bool   // Uppercase keywords
 	Indent    string // Indentation string (unused for single-line output)
+
+	// MinimalParens parenthesizes operator operands based on precedence
+	// instead of the tree's ParenExpr nodes, emitting only the parentheses
+	// needed to preserve the tree's grouping.
+	MinimalParens bool
+	// FullParens parenthesizes every operator operand that is itself an
+	// operator expression. It takes priority over MinimalParens.
+	FullParens bool
+
+	// AlignConditions lays out SELECT WHERE and HAVING conditions that
+	// are AND/OR chains one term per line, with the operators aligned one
+	// Indent in. Nested AND/OR groups are parenthesized with their terms
+	// indented a level further, and the clauses after a laid-out
+	// condition each start on a new line.
+	AlignConditions bool
+
+	// ConcatFunction renders the || operator as a CONCAT(a, b, ...) call,
+	// for dialects without || string concatenation (e.g. MySQL).
+	ConcatFunction bool
+
+	// DollarQuotes writes string literals that contain single quotes
+	// dollar-quoted ($$it's$$, PostgreSQL) instead of doubling the quotes.
+	// Literals parsed from dollar-quoted strings are written dollar-quoted
+	// with their tag regardless.
+	DollarQuotes bool
+
+	// BackslashEscapes doubles backslashes in string literals, for SQL
+	// read with backslash escapes, as MySQL does by default. Otherwise
+	// backslashes are written as is, as standard SQL reads them.
+	BackslashEscapes bool
+
+	// IdentHook, if set, renders every identifier in place of the default
+	// quoting. It receives the unquoted name and returns the text to write
+	// verbatim, so it can transform names a target can't represent or
+	// record them for reporting. QuoteIdent gives the default rendering.
+	IdentHook func(name string) string
 }
 
 // DefaultOptions are the default formatting options.
@@ -25,18 +65,94 @@ var DefaultOptions = Options{
 type Formatter struct {
 	buf  bytes.Buffer
 	opts Options
+
+	// indent is the indentation depth of the condition being laid out by
+	// AlignConditions, so conditions of nested subqueries indent further.
+	indent int
+
+	// w, while FormatTo runs, receives buf whenever it grows past
+	// flushSize; err holds the first error writing to it.
+	w   io.Writer
+	err error
 }
 
+// flushSize is how much output FormatTo buffers before writing it out.
+const flushSize = 4096
+
 // New creates a new formatter with the given options.
 func New(opts Options) *Formatter {
 	return &Formatter{opts: opts}
 }
 
+var formatterPool = sync.Pool{
+	New: func() any { return &Formatter{} },
+}
+
+// maxPooledSize is the largest buffer Put keeps, so that formatting one
+// huge statement doesn't pin its buffer in the pool.
+const maxPooledSize = 64 << 10
+
+// Get returns an empty formatter from the pool with the given options.
+// Call Put(f) when done to return it to the pool.
+func Get(opts Options) *Formatter {
+	f := formatterPool.Get().(*Formatter)
+	f.Reset()
+	f.opts = opts
+	return f
+}
+
+// Put returns the formatter to the pool. Strings already returned by
+// f.String stay valid.
+func Put(f *Formatter) {
+	if f.buf.Cap() > maxPooledSize {
+		return
+	}
+	// Drop the options too, so a pooled formatter doesn't keep an
+	// IdentHook closure (and whatever it captures) alive.
+	f.w, f.opts = nil, Options{}
+	formatterPool.Put(f)
+}
+
+// Reset discards the formatted output, keeping the buffer's capacity, so
+// the formatter can be reused for another node.
+func (f *Formatter) Reset() {
+	f.buf.Reset()
+	f.indent = 0
+	f.w, f.err = nil, nil
+}
+
 // String formats an AST node to a SQL string.
 func String(node ast.Node) string {
-	f := New(DefaultOptions)
+	f := Get(DefaultOptions)
+	f.Format(node)
+	s := f.String()
+	Put(f)
+	return s
+}
+
+// Fprint formats an AST node to w with the default options.
+func Fprint(w io.Writer, node ast.Node) error {
+	return New(DefaultOptions).FormatTo(w, node)
+}
+
+// FormatTo formats a node directly to w, writing the output in chunks as
+// it is produced rather than building the whole statement in memory. Any
+// output already in the internal buffer is written first. It returns the
+// first error from w.
+func (f *Formatter) FormatTo(w io.Writer, node ast.Node) error {
+	f.w, f.err = w, nil
 	f.Format(node)
-	return f.String()
+	f.flush()
+	f.w = nil
+	return f.err
+}
+
+// flush writes the buffered output to f.w.
+func (f *Formatter) flush() {
+	if f.err == nil {
+		_, f.err = f.buf.WriteTo(f.w)
+	}
+	f.buf.Reset()
 }
 
 // Format formats a node to the internal buffer.
@@ -44,6 +160,13 @@ func (f *Formatter) Format(node ast.Node) {
 	if node == nil {
 		return
 	}
+	if f.w != nil {
+		defer func() {
+			if f.buf.Len() >= flushSize {
+				f.flush()
+			}
+		}()
+	}
 
 	switch n := node.(type) {
 	case *ast.SelectStmt:
@@ -64,8 +187,38 @@ func (f *Formatter) Format(node ast.Node) {
 		f.formatCreateIndex(n)
 	case *ast.DropIndexStmt:
 		f.formatDropIndex(n)
+	case *ast.CreateDatabaseStmt:
+		f.formatCreateDatabase(n)
+	case *ast.DropDatabaseStmt:
+		f.formatDropDatabase(n)
+	case *ast.CreateSchemaStmt:
+		f.formatCreateSchema(n)
+	case *ast.DropSchemaStmt:
+		f.formatDropSchema(n)
+	case *ast.CreateSequenceStmt:
+		f.formatCreateSequence(n)
+	case *ast.CreateFunctionStmt:
+		f.formatCreateFunction(n)
 	case *ast.TruncateStmt:
 		f.formatTruncate(n)
+	case *ast.CopyStmt:
+		f.formatCopy(n)
+	case *ast.VacuumStmt:
+		f.formatVacuum(n)
+	case *ast.AnalyzeStmt:
+		f.formatAnalyze(n)
+	case *ast.ShowStmt:
+		f.formatShow(n)
+	case *ast.UseStmt:
+		f.writeKeyword("USE")
+		f.write(" ")
+		f.writeIdent(n.Database)
+	case *ast.SetSessionStmt:
+		f.formatSetSession(n)
+	case *ast.DeclareCursorStmt:
+		f.formatDeclareCursor(n)
+	case *ast.FetchStmt:
+		f.formatFetch(n)
 	case *ast.ExplainStmt:
 		f.formatExplain(n)
 	case *ast.SetOp:
@@ -74,20 +227,41 @@ func (f *Formatter) Format(node ast.Node) {
 		f.formatBinaryExpr(n)
 	case *ast.UnaryExpr:
 		f.formatUnaryExpr(n)
+	case *ast.PostfixExpr:
+		f.formatPostfixExpr(n)
 	case *ast.ParenExpr:
+		// A subquery brings its own parentheses, so ((SELECT 1)) is
+		// written with a single pair.
+		if sub, ok := unwrapParens(n).(*ast.Subquery); ok {
+			f.Format(sub)
+			break
+		}
 		f.write("(")
 		f.Format(n.Expr)
 		f.write(")")
+	case *ast.TupleExpr:
+		f.write("(")
+		for i, e := range n.Exprs {
+			if i > 0 {
+				f.write(", ")
+			}
+			f.Format(e)
+		}
+		f.write(")")
 	case *ast.FuncExpr:
 		f.formatFuncExpr(n)
 	case *ast.CaseExpr:
 		f.formatCaseExpr(n)
 	case *ast.CastExpr:
 		f.formatCastExpr(n)
+	case *ast.ConvertExpr:
+		f.formatConvertExpr(n)
 	case *ast.ColName:
 		f.formatColName(n)
 	case *ast.Literal:
 		f.formatLiteral(n)
+	case *ast.TypedLiteral:
+		f.formatTypedLiteral(n)
 	case *ast.Param:
 		f.formatParam(n)
 	case *ast.TableName:
@@ -113,11 +287,7 @@ func (f *Formatter) Format(node ast.Node) {
 			f.writeIdent(n.Alias)
 		}
 	case *ast.StarExpr:
-		if n.HasQualifier {
-			f.writeIdent(n.TableName)
-			f.write(".")
-		}
-		f.write("*")
+		f.formatStarExpr(n)
 	case *ast.InExpr:
 		f.formatInExpr(n)
 	case *ast.BetweenExpr:
@@ -126,8 +296,18 @@ func (f *Formatter) Format(node ast.Node) {
 		f.formatLikeExpr(n)
 	case *ast.IsExpr:
 		f.formatIsExpr(n)
+	case *ast.PeriodExpr:
+		f.formatPredicateOperand(n.Left)
+		f.write(" ")
+		f.writeKeyword(n.Op.String())
+		f.write(" ")
+		f.formatPredicateOperand(n.Right)
 	case *ast.ExistsExpr:
 		f.formatExistsExpr(n)
+	case *ast.UniqueExpr:
+		f.writeKeyword("UNIQUE")
+		f.write(" ")
+		f.Format(n.Subquery)
 	case *ast.IntervalExpr:
 		f.formatIntervalExpr(n)
 	case *ast.ExtractExpr:
@@ -154,7 +334,34 @@ func (f *Formatter) Format(node ast.Node) {
 		f.write(n.Collation)
 	case *ast.ValuesStmt:
 		f.formatValuesStmt(n)
+	case *ast.TableStmt:
+		f.formatTableStmt(n)
+	case *ast.GroupingSetExpr:
+		f.formatGroupingSetExpr(n)
+	}
+}
+
+func (f *Formatter) formatGroupingSetExpr(g *ast.GroupingSetExpr) {
+	f.writeKeyword(g.Type.String())
+	f.write(" (")
+	for i, set := range g.Sets {
+		if i > 0 {
+			f.write(", ")
+		}
+		if len(set) == 1 {
+			f.Format(set[0])
+			continue
+		}
+		f.write("(")
+		for j, expr := range set {
+			if j > 0 {
+				f.write(", ")
+			}
+			f.Format(expr)
+		}
+		f.write(")")
 	}
+	f.write(")")
 }
 
 // String returns the formatted SQL.
@@ -175,6 +382,10 @@ func (f *Formatter) writeKeyword(kw string) {
 }
 
 func (f *Formatter) writeIdent(id string) {
+	if f.opts.IdentHook != nil {
+		f.buf.WriteString(f.opts.IdentHook(id))
+		return
+	}
 	if needsQuoting(id) {
 		f.buf.WriteByte('"')
 		f.buf.WriteString(strings.ReplaceAll(id, `"`, `""`))
@@ -184,6 +395,15 @@ func (f *Formatter) writeIdent(id string) {
 	}
 }
 
+// QuoteIdent returns id as the formatter writes it by default, double-quoted
+// if it is a keyword or contains characters that require quoting.
+func QuoteIdent(id string) string {
+	if needsQuoting(id) {
+		return `"` + strings.ReplaceAll(id, `"`, `""`) + `"`
+	}
+	return id
+}
+
 // writeFuncName writes a function name. Unlike writeIdent, it doesn't quote
 // keywords since many SQL functions have keyword names (ANY, ALL, COUNT, etc.)
 func (f *Formatter) writeFuncName(name string) {
@@ -228,16 +448,14 @@ func (f *Formatter) formatSelect(s *ast.SelectStmt) {
 	}
 
 	// WHERE
+	multiline := false
 	if s.Where != nil {
-		f.write(" ")
-		f.writeKeyword("WHERE")
-		f.write(" ")
-		f.Format(s.Where)
+		multiline = f.formatConditionClause("WHERE", s.Where, false)
 	}
 
 	// GROUP BY
 	if len(s.GroupBy) > 0 {
-		f.write(" ")
+		f.clauseSep(multiline)
 		f.writeKeyword("GROUP BY")
 		f.write(" ")
 		for i, expr := range s.GroupBy {
@@ -250,49 +468,27 @@ func (f *Formatter) formatSelect(s *ast.SelectStmt) {
 
 	// HAVING
 	if s.Having != nil {
-		f.write(" ")
-		f.writeKeyword("HAVING")
-		f.write(" ")
-		f.Format(s.Having)
+		multiline = f.formatConditionClause("HAVING", s.Having, multiline)
 	}
 
 	// ORDER BY
 	if len(s.OrderBy) > 0 {
-		f.write(" ")
+		f.clauseSep(multiline)
 		f.writeKeyword("ORDER BY")
 		f.write(" ")
-		for i, ob := range s.OrderBy {
-			if i > 0 {
-				f.write(", ")
-			}
-			f.Format(ob.Expr)
-			if ob.Desc {
-				f.write(" ")
-				f.writeKeyword("DESC")
-			}
-			if ob.NullsFirst != nil {
-				f.write(" ")
-				f.writeKeyword("NULLS")
-				f.write(" ")
-				if *ob.NullsFirst {
-					f.writeKeyword("FIRST")
-				} else {
-					f.writeKeyword("LAST")
-				}
-			}
-		}
+		f.formatOrderByItems(s.OrderBy)
 	}
 
 	// LIMIT
 	if s.Limit != nil {
 		if s.Limit.Count != nil {
-			f.write(" ")
+			f.clauseSep(multiline)
 			f.writeKeyword("LIMIT")
 			f.write(" ")
 			f.Format(s.Limit.Count)
 		}
 		if s.Limit.Offset != nil {
-			f.write(" ")
+			f.clauseSep(multiline)
 			f.writeKeyword("OFFSET")
 			f.write(" ")
 			f.Format(s.Limit.Offset)
@@ -301,13 +497,145 @@ func (f *Formatter) formatSelect(s *ast.SelectStmt) {
 
 	// FOR UPDATE/SHARE
 	if s.Lock != "" {
-		f.write(" ")
+		f.clauseSep(multiline)
 		f.writeKeyword("FOR")
 		f.write(" ")
 		f.writeKeyword(s.Lock)
 	}
 }
 
+// clauseSep writes the separator before a SELECT clause: a space, or a
+// new line once AlignConditions has laid out a condition over several.
+func (f *Formatter) clauseSep(multiline bool) {
+	if multiline {
+		f.newline(0)
+	} else {
+		f.write(" ")
+	}
+}
+
+// newline starts a new line indented depth levels past f.indent.
+func (f *Formatter) newline(depth int) {
+	f.write("\n")
+	f.write(strings.Repeat(f.opts.Indent, f.indent+depth))
+}
+
+// formatConditionClause writes a WHERE or HAVING clause, laying the
+// condition out over several lines when AlignConditions is set and it is
+// an AND/OR chain. It reports whether the clause spans several lines, or
+// follows a clause that did, which moves the following clauses to lines
+// of their own.
+func (f *Formatter) formatConditionClause(keyword string, cond ast.Expr, multiline bool) bool {
+	op, terms := conditionTerms(cond)
+	if !f.opts.AlignConditions || len(terms) < 2 {
+		f.clauseSep(multiline)
+		f.writeKeyword(keyword)
+		f.write(" ")
+		f.Format(cond)
+		return multiline
+	}
+	f.newline(0)
+	f.writeKeyword(keyword)
+	f.write(" ")
+	f.formatConditionTerms(op, terms, 1)
+	return true
+}
+
+// formatConditionTerms writes terms joined by op, one per line at depth.
+// A term that is itself an AND/OR chain becomes a parenthesized group
+// with its terms a level deeper.
+func (f *Formatter) formatConditionTerms(op token.Token, terms []ast.Expr, depth int) {
+	for i, term := range terms {
+		if i > 0 {
+			f.newline(depth)
+			f.writeKeyword(op.String())
+			f.write(" ")
+		}
+
+		inner := term
+		if p, ok := term.(*ast.ParenExpr); ok {
+			inner = p.Expr
+		}
+		innerOp, innerTerms := conditionTerms(inner)
+		if len(innerTerms) < 2 {
+			// Subqueries in the term lay out their conditions from here.
+			f.indent += depth
+			f.Format(term)
+			f.indent -= depth
+			continue
+		}
+		f.write("(")
+		f.newline(depth + 1)
+		f.formatConditionTerms(innerOp, innerTerms, depth+1)
+		f.newline(depth)
+		f.write(")")
+	}
+}
+
+// conditionTerms splits a left-deep AND or OR chain into its operator and
+// terms. Any other expression is a single term.
+func conditionTerms(e ast.Expr) (token.Token, []ast.Expr) {
+	bin, ok := e.(*ast.BinaryExpr)
+	if !ok || (bin.Op != token.AND && bin.Op != token.OR) {
+		return token.ILLEGAL, []ast.Expr{e}
+	}
+	op := bin.Op
+	var terms []ast.Expr
+	for {
+		terms = append(terms, bin.Right)
+		left, ok := bin.Left.(*ast.BinaryExpr)
+		if !ok || left.Op != op {
+			terms = append(terms, bin.Left)
+			break
+		}
+		bin = left
+	}
+	slices.Reverse(terms)
+	return op, terms
+}
+
+func (f *Formatter) formatStarExpr(s *ast.StarExpr) {
+	for _, part := range s.QualifierParts {
+		f.writeIdent(part)
+		f.write(".")
+	}
+	f.write("*")
+
+	if len(s.Except) > 0 {
+		f.write(" ")
+		if s.Exclude {
+			f.writeKeyword("EXCLUDE")
+		} else {
+			f.writeKeyword("EXCEPT")
+		}
+		f.write(" (")
+		for i, col := range s.Except {
+			if i > 0 {
+				f.write(", ")
+			}
+			f.writeIdent(col)
+		}
+		f.write(")")
+	}
+
+	if len(s.Replace) > 0 {
+		f.write(" ")
+		f.writeKeyword("REPLACE")
+		f.write(" (")
+		for i, item := range s.Replace {
+			if i > 0 {
+				f.write(", ")
+			}
+			f.Format(item.Expr)
+			f.write(" ")
+			f.writeKeyword("AS")
+			f.write(" ")
+			f.writeIdent(item.Column)
+		}
+		f.write(")")
+	}
+}
+
 func (f *Formatter) formatWithClause(w *ast.WithClause) {
 	f.writeKeyword("WITH")
 	if w.Recursive {
@@ -332,6 +660,14 @@ func (f *Formatter) formatWithClause(w *ast.WithClause) {
 		}
 		f.write(" ")
 		f.writeKeyword("AS")
+		if cte.Materialized != nil {
+			if !*cte.Materialized {
+				f.write(" ")
+				f.writeKeyword("NOT")
+			}
+			f.write(" ")
+			f.writeKeyword("MATERIALIZED")
+		}
 		f.write(" (")
 		f.Format(cte.Query)
 		f.write(")")
@@ -369,11 +705,21 @@ func (f *Formatter) formatInsert(s *ast.InsertStmt) {
 			f.writeIdent(col.Name())
 		}
 		f.write(")")
+	} else if s.EmptyColumns {
+		f.write(" ()")
 	}
 
 	if s.Select != nil {
 		f.write(" ")
 		f.Format(s.Select)
+	} else if s.DefaultValues {
+		f.write(" ")
+		f.writeKeyword("DEFAULT VALUES")
+	} else if len(s.SetAssignments) > 0 {
+		f.write(" ")
+		f.writeKeyword("SET")
+		f.write(" ")
+		f.formatUpdateExprs(s.SetAssignments)
 	} else if len(s.Values) > 0 {
 		f.write(" ")
 		f.writeKeyword("VALUES")
@@ -416,10 +762,16 @@ func (f *Formatter) formatInsert(s *ast.InsertStmt) {
 				if i > 0 {
 					f.write(", ")
 				}
-				f.writeIdent(col)
+				f.formatIndexColumn(col)
 			}
 			f.write(")")
 		}
+		if s.OnConflict.Where != nil {
+			f.write(" ")
+			f.writeKeyword("WHERE")
+			f.write(" ")
+			f.Format(s.OnConflict.Where)
+		}
 		f.write(" ")
 		f.writeKeyword("DO")
 		f.write(" ")
@@ -436,6 +788,12 @@ func (f *Formatter) formatInsert(s *ast.InsertStmt) {
 				f.write(" = ")
 				f.Format(ue.Expr)
 			}
+			if s.OnConflict.UpdateWhere != nil {
+				f.write(" ")
+				f.writeKeyword("WHERE")
+				f.write(" ")
+				f.Format(s.OnConflict.UpdateWhere)
+			}
 		}
 	}
 
@@ -464,15 +822,7 @@ func (f *Formatter) formatUpdate(s *ast.UpdateStmt) {
 	f.write(" ")
 	f.writeKeyword("SET")
 	f.write(" ")
-
-	for i, ue := range s.Set {
-		if i > 0 {
-			f.write(", ")
-		}
-		f.formatColName(ue.Column)
-		f.write(" = ")
-		f.Format(ue.Expr)
-	}
+	f.formatUpdateExprs(s.Set)
 
 	if s.From != nil {
 		f.write(" ")
@@ -492,16 +842,7 @@ func (f *Formatter) formatUpdate(s *ast.UpdateStmt) {
 		f.write(" ")
 		f.writeKeyword("ORDER BY")
 		f.write(" ")
-		for i, ob := range s.OrderBy {
-			if i > 0 {
-				f.write(", ")
-			}
-			f.Format(ob.Expr)
-			if ob.Desc {
-				f.write(" ")
-				f.writeKeyword("DESC")
-			}
-		}
+		f.formatOrderByItems(s.OrderBy)
 	}
 
 	if s.Limit != nil && s.Limit.Count != nil {
@@ -524,13 +865,36 @@ func (f *Formatter) formatUpdate(s *ast.UpdateStmt) {
 	}
 }
 
+// formatUpdateExprs writes a comma-separated list of column = value assignments.
+func (f *Formatter) formatUpdateExprs(exprs []*ast.UpdateExpr) {
+	for i, ue := range exprs {
+		if i > 0 {
+			f.write(", ")
+		}
+		f.formatColName(ue.Column)
+		f.write(" = ")
+		f.Format(ue.Expr)
+	}
+}
+
 func (f *Formatter) formatDelete(s *ast.DeleteStmt) {
 	if s.With != nil {
 		f.formatWithClause(s.With)
 		f.write(" ")
 	}
 
-	f.writeKeyword("DELETE FROM")
+	f.writeKeyword("DELETE")
+	f.write(" ")
+	for i, target := range s.Targets {
+		if i > 0 {
+			f.write(", ")
+		}
+		f.Format(target)
+	}
+	if len(s.Targets) > 0 {
+		f.write(" ")
+	}
+	f.writeKeyword("FROM")
 	f.write(" ")
 	f.Format(s.Table)
 
@@ -552,16 +916,7 @@ func (f *Formatter) formatDelete(s *ast.DeleteStmt) {
 		f.write(" ")
 		f.writeKeyword("ORDER BY")
 		f.write(" ")
-		for i, ob := range s.OrderBy {
-			if i > 0 {
-				f.write(", ")
-			}
-			f.Format(ob.Expr)
-			if ob.Desc {
-				f.write(" ")
-				f.writeKeyword("DESC")
-			}
-		}
+		f.formatOrderByItems(s.OrderBy)
 	}
 
 	if s.Limit != nil && s.Limit.Count != nil {
@@ -624,14 +979,73 @@ func (f *Formatter) formatCreateTable(s *ast.CreateTableStmt) {
 	}
 	f.write(")")
 
-	for _, opt := range s.Options {
+	if len(s.With) > 0 {
+		f.write(" ")
+		f.formatWithOptions(s.With)
+	}
+
+	if s.Tablespace != "" {
+		f.formatTablespace(s.Tablespace)
+		if s.Storage != "" {
+			f.write(" ")
+			f.writeKeyword("STORAGE")
+			f.write(" ")
+			f.writeKeyword(s.Storage)
+		}
+	}
+
+	f.formatNamedOptions(s.Options)
+}
+
+// formatTablespace writes TABLESPACE name, preceded by a space.
+func (f *Formatter) formatTablespace(name string) {
+	f.write(" ")
+	f.writeKeyword("TABLESPACE")
+	f.write(" ")
+	f.writeIdent(name)
+}
+
+// formatNamedOptions writes space-separated NAME=VALUE options, each
+// preceded by a space.
+func (f *Formatter) formatNamedOptions(opts []*ast.Option) {
+	for _, opt := range opts {
 		f.write(" ")
+		if opt.Default {
+			f.writeKeyword("DEFAULT")
+			f.write(" ")
+		}
 		f.write(opt.Name)
 		f.write("=")
+		f.formatOptionValue(opt)
+	}
+}
+
+// formatOptionValue writes an option value, quoting it if it was quoted in the source.
+func (f *Formatter) formatOptionValue(opt *ast.Option) {
+	if opt.Quoted {
+		f.formatStringLiteral(opt.Value)
+	} else {
 		f.write(opt.Value)
 	}
 }
 
+// formatWithOptions writes a PostgreSQL storage parameter list.
+func (f *Formatter) formatWithOptions(opts []*ast.Option) {
+	f.writeKeyword("WITH")
+	f.write(" (")
+	for i, opt := range opts {
+		if i > 0 {
+			f.write(", ")
+		}
+		f.write(opt.Name)
+		if opt.Value != "" || opt.Quoted {
+			f.write("=")
+			f.formatOptionValue(opt)
+		}
+	}
+	f.write(")")
+}
+
 func (f *Formatter) formatColumnDef(col *ast.ColumnDef) {
 	f.writeIdent(col.Name)
 	f.write(" ")
@@ -641,14 +1055,26 @@ func (f *Formatter) formatColumnDef(col *ast.ColumnDef) {
 		f.write(" ")
 		f.formatColumnConstraint(cons)
 	}
+	if col.Comment != "" {
+		f.write(" ")
+		f.writeKeyword("COMMENT")
+		f.write(" ")
+		f.formatStringLiteral(col.Comment)
+	}
 }
 
 func (f *Formatter) formatDataType(dt *ast.DataType) {
 	if dt == nil {
 		return
 	}
-	// Use writeIdent to handle quoted identifiers as type names
-	if needsQuoting(dt.Name) {
+	if dt.Schema != "" {
+		// User-defined type: keep the name's case
+		f.writeIdent(dt.Schema)
+		f.write(".")
+		f.writeIdent(dt.Name)
+	} else if needsQuotingNonKeyword(dt.Name) {
+		// Use writeIdent to handle quoted identifiers as type names. Type
+		// names such as INT and DATE are keywords but need no quotes.
 		f.writeIdent(dt.Name)
 	} else {
 		f.writeKeyword(dt.Name)
@@ -763,6 +1189,12 @@ func (f *Formatter) formatTableConstraint(cons *ast.TableConstraint) {
 		f.Format(cons.Check)
 		f.write(")")
 	}
+
+	if cons.IndexTablespace != "" {
+		f.write(" ")
+		f.writeKeyword("USING INDEX")
+		f.formatTablespace(cons.IndexTablespace)
+	}
 }
 
 func (f *Formatter) formatAlterTable(s *ast.AlterTableStmt) {
@@ -869,18 +1301,29 @@ func (f *Formatter) formatDropTable(s *ast.DropTableStmt) {
 	}
 }
 
-func (f *Formatter) formatCreateIndex(s *ast.CreateIndexStmt) {
-	f.writeKeyword("CREATE")
-	if s.Unique {
+func (f *Formatter) formatCreateDatabase(s *ast.CreateDatabaseStmt) {
+	f.writeKeyword("CREATE DATABASE")
+	if s.IfNotExists {
 		f.write(" ")
-		f.writeKeyword("UNIQUE")
+		f.writeKeyword("IF NOT EXISTS")
 	}
 	f.write(" ")
-	f.writeKeyword("INDEX")
-	if s.Concurrent {
+	f.writeIdent(s.Name)
+	f.formatNamedOptions(s.Options)
+}
+
+func (f *Formatter) formatDropDatabase(s *ast.DropDatabaseStmt) {
+	f.writeKeyword("DROP DATABASE")
+	if s.IfExists {
 		f.write(" ")
-		f.writeKeyword("CONCURRENTLY")
+		f.writeKeyword("IF EXISTS")
 	}
+	f.write(" ")
+	f.writeIdent(s.Name)
+}
+
+func (f *Formatter) formatCreateSchema(s *ast.CreateSchemaStmt) {
+	f.writeKeyword("CREATE SCHEMA")
 	if s.IfNotExists {
 		f.write(" ")
 		f.writeKeyword("IF NOT EXISTS")
@@ -889,32 +1332,218 @@ func (f *Formatter) formatCreateIndex(s *ast.CreateIndexStmt) {
 		f.write(" ")
 		f.writeIdent(s.Name)
 	}
-	f.write(" ")
-	f.writeKeyword("ON")
-	f.write(" ")
-	f.Format(s.Table)
-	if s.Using != "" {
+	if s.Authorization != "" {
 		f.write(" ")
-		f.writeKeyword("USING")
+		f.writeKeyword("AUTHORIZATION")
 		f.write(" ")
-		f.write(s.Using)
+		f.writeIdent(s.Authorization)
 	}
-	f.write(" (")
-	for i, col := range s.Columns {
-		if i > 0 {
-			f.write(", ")
-		}
-		if col.Expr != nil {
-			f.Format(col.Expr)
-		} else {
-			f.writeIdent(col.Column)
-		}
-		if col.Desc {
+	f.formatNamedOptions(s.Options)
+}
+
+func (f *Formatter) formatDropSchema(s *ast.DropSchemaStmt) {
+	f.writeKeyword("DROP SCHEMA")
+	if s.IfExists {
+		f.write(" ")
+		f.writeKeyword("IF EXISTS")
+	}
+	f.write(" ")
+	for i, name := range s.Names {
+		if i > 0 {
+			f.write(", ")
+		}
+		f.writeIdent(name)
+	}
+	if s.Cascade {
+		f.write(" ")
+		f.writeKeyword("CASCADE")
+	} else if s.Restrict {
+		f.write(" ")
+		f.writeKeyword("RESTRICT")
+	}
+}
+
+func (f *Formatter) formatCreateSequence(s *ast.CreateSequenceStmt) {
+	f.writeKeyword("CREATE")
+	if s.Temporary {
+		f.write(" ")
+		f.writeKeyword("TEMPORARY")
+	}
+	f.write(" ")
+	f.writeKeyword("SEQUENCE")
+	if s.IfNotExists {
+		f.write(" ")
+		f.writeKeyword("IF NOT EXISTS")
+	}
+	f.write(" ")
+	f.formatTableName(s.Name)
+	for _, opt := range s.Options {
+		f.write(" ")
+		f.writeKeyword(opt.Name)
+		if opt.Value != "" {
 			f.write(" ")
-			f.writeKeyword("DESC")
+			f.write(opt.Value)
+		}
+	}
+}
+
+func (f *Formatter) formatCreateFunction(s *ast.CreateFunctionStmt) {
+	f.writeKeyword("CREATE")
+	if s.OrReplace {
+		f.write(" ")
+		f.writeKeyword("OR REPLACE")
+	}
+	f.write(" ")
+	if s.Procedure {
+		f.writeKeyword("PROCEDURE")
+	} else {
+		f.writeKeyword("FUNCTION")
+	}
+	f.write(" ")
+	f.formatTableName(s.Name)
+	f.write("(")
+	for i, arg := range s.Args {
+		if i > 0 {
+			f.write(", ")
+		}
+		if arg.Mode != "" {
+			f.writeKeyword(arg.Mode)
+			f.write(" ")
+		}
+		if arg.Name != "" {
+			f.writeIdent(arg.Name)
+			f.write(" ")
+		}
+		f.formatDataType(arg.Type)
+		if arg.Default != nil {
+			f.write(" ")
+			f.writeKeyword("DEFAULT")
+			f.write(" ")
+			f.Format(arg.Default)
+		}
+	}
+	f.write(")")
+
+	if s.Returns != nil {
+		f.write(" ")
+		f.writeKeyword("RETURNS")
+		f.write(" ")
+		if s.SetOf {
+			f.writeKeyword("SETOF")
+			f.write(" ")
+		}
+		f.formatDataType(s.Returns)
+	}
+	if len(s.Table) > 0 {
+		f.write(" ")
+		f.writeKeyword("RETURNS TABLE")
+		f.write(" (")
+		for i, col := range s.Table {
+			if i > 0 {
+				f.write(", ")
+			}
+			f.writeIdent(col.Name)
+			f.write(" ")
+			f.formatDataType(col.Type)
+		}
+		f.write(")")
+	}
+	if s.Language != "" {
+		f.write(" ")
+		f.writeKeyword("LANGUAGE")
+		f.write(" ")
+		f.write(s.Language)
+	}
+	for _, opt := range s.Options {
+		f.write(" ")
+		f.writeKeyword(opt.Name)
+		if opt.Value != "" || opt.Quoted {
+			f.write(" ")
+			f.formatOptionValue(opt)
 		}
 	}
+	if s.Body != nil {
+		f.write(" ")
+		f.writeKeyword("AS")
+		f.write(" ")
+		f.formatLiteral(s.Body)
+	}
+	if s.Return != nil {
+		f.write(" ")
+		f.writeKeyword("RETURN")
+		f.write(" ")
+		f.Format(s.Return)
+	}
+}
+
+func (f *Formatter) formatCreateIndex(s *ast.CreateIndexStmt) {
+	f.writeKeyword("CREATE")
+	if s.Unique {
+		f.write(" ")
+		f.writeKeyword("UNIQUE")
+	}
+	f.write(" ")
+	f.writeKeyword("INDEX")
+	if s.Concurrent {
+		f.write(" ")
+		f.writeKeyword("CONCURRENTLY")
+	}
+	if s.IfNotExists {
+		f.write(" ")
+		f.writeKeyword("IF NOT EXISTS")
+	}
+	if s.Name != "" {
+		f.write(" ")
+		f.writeIdent(s.Name)
+	}
+	f.write(" ")
+	f.writeKeyword("ON")
+	f.write(" ")
+	f.Format(s.Table)
+	if s.Using != "" {
+		f.write(" ")
+		f.writeKeyword("USING")
+		f.write(" ")
+		f.write(s.Using)
+	}
+	f.write(" (")
+	for i, col := range s.Columns {
+		if i > 0 {
+			f.write(", ")
+		}
+		f.formatIndexColumn(col)
+	}
 	f.write(")")
+	if len(s.Include) > 0 {
+		f.write(" ")
+		f.writeKeyword("INCLUDE")
+		f.write(" (")
+		for i, col := range s.Include {
+			if i > 0 {
+				f.write(", ")
+			}
+			f.writeIdent(col)
+		}
+		f.write(")")
+	}
+	if len(s.With) > 0 {
+		f.write(" ")
+		f.formatWithOptions(s.With)
+	}
+	if s.Tablespace != "" {
+		f.formatTablespace(s.Tablespace)
+	}
+	for _, opt := range s.Options {
+		f.write(" ")
+		f.write(opt.Name)
+		// MySQL takes COMMENT 'text' without =, unlike the table option.
+		if opt.Name == "COMMENT" {
+			f.write(" ")
+		} else {
+			f.write("=")
+		}
+		f.formatOptionValue(opt)
+	}
 	if s.Where != nil {
 		f.write(" ")
 		f.writeKeyword("WHERE")
@@ -923,63 +1552,356 @@ func (f *Formatter) formatCreateIndex(s *ast.CreateIndexStmt) {
 	}
 }
 
-func (f *Formatter) formatDropIndex(s *ast.DropIndexStmt) {
-	f.writeKeyword("DROP INDEX")
-	if s.Concurrent {
+// formatIndexColumn writes an index element (column or expression).
+func (f *Formatter) formatIndexColumn(col *ast.IndexColumn) {
+	if col.Expr != nil {
+		f.Format(col.Expr)
+	} else {
+		f.writeIdent(col.Column)
+	}
+	if col.OpClass != "" {
+		f.write(" ")
+		f.writeIdent(col.OpClass)
+	}
+	if col.Desc {
+		f.write(" ")
+		f.writeKeyword("DESC")
+	}
+	if col.Nulls != "" {
+		f.write(" ")
+		f.writeKeyword("NULLS")
+		f.write(" ")
+		f.writeKeyword(col.Nulls)
+	}
+}
+
+func (f *Formatter) formatDropIndex(s *ast.DropIndexStmt) {
+	f.writeKeyword("DROP INDEX")
+	if s.Concurrent {
+		f.write(" ")
+		f.writeKeyword("CONCURRENTLY")
+	}
+	if s.IfExists {
+		f.write(" ")
+		f.writeKeyword("IF EXISTS")
+	}
+	f.write(" ")
+	f.writeIdent(s.Name)
+	if s.Table != nil {
+		f.write(" ")
+		f.writeKeyword("ON")
+		f.write(" ")
+		f.Format(s.Table)
+	}
+	if s.Cascade {
+		f.write(" ")
+		f.writeKeyword("CASCADE")
+	}
+}
+
+func (f *Formatter) formatTruncate(s *ast.TruncateStmt) {
+	f.writeKeyword("TRUNCATE TABLE")
+	f.write(" ")
+	for i, t := range s.Tables {
+		if i > 0 {
+			f.write(", ")
+		}
+		f.Format(t)
+	}
+	if s.Cascade {
+		f.write(" ")
+		f.writeKeyword("CASCADE")
+	}
+}
+
+func (f *Formatter) formatCopy(s *ast.CopyStmt) {
+	f.writeKeyword("COPY")
+	f.write(" ")
+	if s.Query != nil {
+		f.write("(")
+		f.Format(s.Query)
+		f.write(")")
+	} else {
+		f.Format(s.Table)
+		if len(s.Columns) > 0 {
+			f.write(" (")
+			for i, col := range s.Columns {
+				if i > 0 {
+					f.write(", ")
+				}
+				f.writeIdent(col)
+			}
+			f.write(")")
+		}
+	}
+
+	f.write(" ")
+	if s.From {
+		f.writeKeyword("FROM")
+	} else {
+		f.writeKeyword("TO")
+	}
+	f.write(" ")
+	switch {
+	case s.Stdio && s.From:
+		f.writeKeyword("STDIN")
+	case s.Stdio:
+		f.writeKeyword("STDOUT")
+	case s.Program:
+		f.writeKeyword("PROGRAM")
+		f.write(" ")
+		f.formatStringLiteral(s.File)
+	default:
+		f.formatStringLiteral(s.File)
+	}
+
+	if len(s.Options) == 0 {
+		return
+	}
+	if s.LegacyOptions {
+		for _, opt := range s.Options {
+			f.write(" ")
+			f.write(opt.Name)
+			if opt.Value != "" || opt.Quoted {
+				f.write(" ")
+				f.formatOptionValue(opt)
+			}
+		}
+		return
+	}
+	f.write(" ")
+	f.writeKeyword("WITH")
+	f.write(" (")
+	for i, opt := range s.Options {
+		if i > 0 {
+			f.write(", ")
+		}
+		f.write(opt.Name)
+		if opt.Value != "" || opt.Quoted {
+			f.write(" ")
+			f.formatOptionValue(opt)
+		}
+	}
+	f.write(")")
+}
+
+func (f *Formatter) formatVacuum(s *ast.VacuumStmt) {
+	f.writeKeyword("VACUUM")
+	if s.Full {
+		f.write(" ")
+		f.writeKeyword("FULL")
+	}
+	if s.Verbose {
+		f.write(" ")
+		f.writeKeyword("VERBOSE")
+	}
+	if s.Analyze {
+		f.write(" ")
+		f.writeKeyword("ANALYZE")
+	}
+	f.formatMaintenanceTables(s.Tables)
+}
+
+func (f *Formatter) formatAnalyze(s *ast.AnalyzeStmt) {
+	f.writeKeyword("ANALYZE")
+	if s.Table {
+		f.write(" ")
+		f.writeKeyword("TABLE")
+	}
+	if s.Verbose {
+		f.write(" ")
+		f.writeKeyword("VERBOSE")
+	}
+	f.formatMaintenanceTables(s.Tables)
+}
+
+func (f *Formatter) formatMaintenanceTables(tables []*ast.TableName) {
+	for i, t := range tables {
+		if i > 0 {
+			f.write(",")
+		}
+		f.write(" ")
+		f.Format(t)
+	}
+}
+
+func (f *Formatter) formatShow(s *ast.ShowStmt) {
+	f.writeKeyword("SHOW")
+	if s.Full {
+		f.write(" ")
+		f.writeKeyword("FULL")
+	}
+	if s.Scope != "" {
+		f.write(" ")
+		f.writeKeyword(s.Scope)
+	}
+	f.write(" ")
+	f.writeKeyword(s.Kind)
+	if s.Table != nil {
+		if !strings.HasPrefix(s.Kind, "CREATE ") {
+			f.write(" ")
+			f.writeKeyword("FROM")
+		}
+		f.write(" ")
+		f.Format(s.Table)
+	}
+	if s.Database != "" {
+		f.write(" ")
+		f.writeKeyword("FROM")
+		f.write(" ")
+		f.writeIdent(s.Database)
+	}
+	if s.Like != nil {
+		f.write(" ")
+		f.writeKeyword("LIKE")
+		f.write(" ")
+		f.Format(s.Like)
+	} else if s.Where != nil {
+		f.write(" ")
+		f.writeKeyword("WHERE")
+		f.write(" ")
+		f.Format(s.Where)
+	}
+}
+
+func (f *Formatter) formatSetSession(s *ast.SetSessionStmt) {
+	f.writeKeyword("SET")
+	if s.Scope != "" {
+		f.write(" ")
+		f.writeKeyword(s.Scope)
+	}
+	for i, v := range s.Vars {
+		if i > 0 {
+			f.write(",")
+		}
+		f.write(" ")
+		switch {
+		case v.Name == "NAMES" || v.Name == "TIME ZONE":
+			f.writeKeyword(v.Name)
+			f.write(" ")
+		case v.To:
+			f.write(v.Name)
+			f.write(" ")
+			f.writeKeyword("TO")
+			f.write(" ")
+		default:
+			f.write(v.Name)
+			f.write(" = ")
+		}
+		for j, value := range v.Values {
+			if j > 0 {
+				f.write(", ")
+			}
+			f.Format(value)
+		}
+	}
+}
+
+func (f *Formatter) formatDeclareCursor(s *ast.DeclareCursorStmt) {
+	f.writeKeyword("DECLARE")
+	f.write(" ")
+	f.writeIdent(s.Name)
+	if s.Binary {
 		f.write(" ")
-		f.writeKeyword("CONCURRENTLY")
+		f.writeKeyword("BINARY")
 	}
-	if s.IfExists {
+	if s.Insensitive {
 		f.write(" ")
-		f.writeKeyword("IF EXISTS")
+		f.writeKeyword("INSENSITIVE")
 	}
-	f.write(" ")
-	f.writeIdent(s.Name)
-	if s.Table != nil {
+	if s.Scroll != "" {
 		f.write(" ")
-		f.writeKeyword("ON")
-		f.write(" ")
-		f.Format(s.Table)
+		f.writeKeyword(s.Scroll)
 	}
-	if s.Cascade {
+	f.write(" ")
+	f.writeKeyword("CURSOR")
+	if s.Hold != "" {
 		f.write(" ")
-		f.writeKeyword("CASCADE")
+		f.writeKeyword(s.Hold)
 	}
+	f.write(" ")
+	f.writeKeyword("FOR")
+	f.write(" ")
+	f.Format(s.Query)
 }
 
-func (f *Formatter) formatTruncate(s *ast.TruncateStmt) {
-	f.writeKeyword("TRUNCATE TABLE")
-	f.write(" ")
-	for i, t := range s.Tables {
-		if i > 0 {
-			f.write(", ")
-		}
-		f.Format(t)
+func (f *Formatter) formatFetch(s *ast.FetchStmt) {
+	f.writeKeyword("FETCH")
+	if s.Direction != "" {
+		f.write(" ")
+		f.writeKeyword(s.Direction)
 	}
-	if s.Cascade {
+	if s.Count != nil {
 		f.write(" ")
-		f.writeKeyword("CASCADE")
+		f.Format(s.Count)
+	}
+	if s.FromWord != "" {
+		f.write(" ")
+		f.writeKeyword(s.FromWord)
+	}
+	f.write(" ")
+	f.writeIdent(s.Cursor)
+	if len(s.Into) > 0 {
+		f.write(" ")
+		f.writeKeyword("INTO")
+		for i, name := range s.Into {
+			if i > 0 {
+				f.write(",")
+			}
+			f.write(" ")
+			f.writeIdent(name)
+		}
 	}
 }
 
 func (f *Formatter) formatExplain(s *ast.ExplainStmt) {
 	f.writeKeyword("EXPLAIN")
+	if s.Parens {
+		f.formatExplainOptionList(s)
+	} else {
+		if s.Analyze {
+			f.write(" ")
+			f.writeKeyword("ANALYZE")
+		}
+		if s.Verbose {
+			f.write(" ")
+			f.writeKeyword("VERBOSE")
+		}
+		if s.Format != "" {
+			f.write(" ")
+			f.writeKeyword("FORMAT")
+			f.write("=")
+			f.write(s.Format)
+		}
+	}
+	f.write(" ")
+	f.Format(s.Stmt)
+}
+
+// formatExplainOptionList writes PostgreSQL's parenthesized EXPLAIN
+// options, omitting the list when no option is set.
+func (f *Formatter) formatExplainOptionList(s *ast.ExplainStmt) {
+	sep := " ("
 	if s.Analyze {
-		f.write(" ")
+		f.write(sep)
 		f.writeKeyword("ANALYZE")
+		sep = ", "
 	}
 	if s.Verbose {
-		f.write(" ")
+		f.write(sep)
 		f.writeKeyword("VERBOSE")
+		sep = ", "
 	}
 	if s.Format != "" {
-		f.write(" ")
+		f.write(sep)
 		f.writeKeyword("FORMAT")
 		f.write(" ")
 		f.write(s.Format)
+		sep = ", "
+	}
+	if sep == ", " {
+		f.write(")")
 	}
-	f.write(" ")
-	f.Format(s.Stmt)
 }
 
 func (f *Formatter) formatSetOp(s *ast.SetOp) {
@@ -1002,11 +1924,105 @@ func (f *Formatter) formatSetOp(s *ast.SetOp) {
 }
 
 func (f *Formatter) formatBinaryExpr(e *ast.BinaryExpr) {
-	f.Format(e.Left)
+	if e.Op == token.CONCAT && f.opts.ConcatFunction {
+		f.formatConcatFunc(e)
+		return
+	}
+
+	prec := token.Precedence(e.Op)
+	f.formatOperand(e.Left, prec, false)
 	f.write(" ")
 	f.writeKeyword(tokenToString(e.Op))
 	f.write(" ")
-	f.Format(e.Right)
+	f.formatOperand(e.Right, prec, true)
+}
+
+// formatConcatFunc writes a || chain as a single CONCAT(...) call.
+func (f *Formatter) formatConcatFunc(e *ast.BinaryExpr) {
+	f.writeFuncName("CONCAT")
+	f.write("(")
+	for i, arg := range concatArgs(e, nil) {
+		if i > 0 {
+			f.write(", ")
+		}
+		f.Format(arg)
+	}
+	f.write(")")
+}
+
+// concatArgs flattens nested || operators into their operands.
+func concatArgs(e ast.Expr, args []ast.Expr) []ast.Expr {
+	if bin, ok := e.(*ast.BinaryExpr); ok && bin.Op == token.CONCAT {
+		args = concatArgs(bin.Left, args)
+		return concatArgs(bin.Right, args)
+	}
+	return append(args, e)
+}
+
+// formatOperand writes an operand of an operator with precedence parentPrec.
+// With MinimalParens or FullParens set, existing ParenExpr wrappers are
+// dropped and parentheses are added based on precedence instead.
+func (f *Formatter) formatOperand(e ast.Expr, parentPrec int, right bool) {
+	if !f.opts.MinimalParens && !f.opts.FullParens {
+		f.Format(e)
+		return
+	}
+
+	e = f.stripParens(e)
+	prec := ast.ExprPrecedence(e)
+	var wrap bool
+	if f.opts.FullParens {
+		wrap = prec < token.PrecHighest
+	} else {
+		// Operators are left-associative, so an equal-precedence right
+		// operand must keep its grouping. Comparisons are kept apart on
+		// either side: (a = b) = c reads as a chain when written a = b = c,
+		// and several dialects reject that form.
+		wrap = prec < parentPrec ||
+			prec == parentPrec && (right || prec == token.PrecComparison)
+	}
+
+	if wrap {
+		f.write("(")
+		f.Format(e)
+		f.write(")")
+	} else {
+		f.Format(e)
+	}
+}
+
+// formatPredicateOperand writes an operand of IS, IN, BETWEEN or LIKE.
+// These predicates don't chain, so with MinimalParens an operand at
+// comparison precedence or lower, such as a OR b in (a OR b) IS TRUE, is
+// parenthesized whichever side it is on.
+func (f *Formatter) formatPredicateOperand(e ast.Expr) {
+	f.formatOperand(e, token.PrecComparison, true)
+}
+
+// unwrapParens returns e with every enclosing ParenExpr removed.
+func unwrapParens(e ast.Expr) ast.Expr {
+	for {
+		paren, ok := e.(*ast.ParenExpr)
+		if !ok {
+			return e
+		}
+		e = paren.Expr
+	}
+}
+
+// stripParens removes ParenExpr wrappers when parentheses are derived from
+// precedence (MinimalParens or FullParens); otherwise it returns e unchanged.
+func (f *Formatter) stripParens(e ast.Expr) ast.Expr {
+	if !f.opts.MinimalParens && !f.opts.FullParens {
+		return e
+	}
+	for {
+		paren, ok := e.(*ast.ParenExpr)
+		if !ok || paren.Expr == nil {
+			return e
+		}
+		e = paren.Expr
+	}
 }
 
 func (f *Formatter) formatUnaryExpr(e *ast.UnaryExpr) {
@@ -1016,16 +2032,88 @@ func (f *Formatter) formatUnaryExpr(e *ast.UnaryExpr) {
 		f.write(" ")
 	case token.MINUS:
 		f.write("-")
-		// Add space if operand is also unary minus to avoid -- comment syntax
-		if inner, ok := e.Operand.(*ast.UnaryExpr); ok && inner.Op == token.MINUS {
-			f.write(" ")
+		// Add space if operand also starts with a minus to avoid -- comment syntax
+		switch inner := f.stripParens(e.Operand).(type) {
+		case *ast.UnaryExpr:
+			if inner.Op == token.MINUS {
+				f.write(" ")
+			}
+		case *ast.Literal:
+			if strings.HasPrefix(inner.Value, "-") && inner.Type != ast.LiteralString {
+				f.write(" ")
+			}
 		}
 	case token.BITNOT:
 		f.write("~")
 	}
+	prec := token.PrecUnary
+	if e.Op == token.NOT {
+		prec = token.PrecNot
+	}
+	if f.opts.MinimalParens || f.opts.FullParens {
+		f.formatOperand(e.Operand, prec, false)
+		return
+	}
+
+	// Unlike binary operands, a unary operand is parenthesized by
+	// precedence in every mode: a tree for NOT (a OR b) built without a
+	// ParenExpr would otherwise print as NOT a OR b.
+	if _, ok := e.Operand.(*ast.ParenExpr); !ok && ast.ExprPrecedence(e.Operand) < prec {
+		f.write("(")
+		f.Format(e.Operand)
+		f.write(")")
+		return
+	}
 	f.Format(e.Operand)
 }
 
+func (f *Formatter) formatPostfixExpr(e *ast.PostfixExpr) {
+	if f.opts.MinimalParens || f.opts.FullParens {
+		f.formatOperand(e.Operand, token.PrecUnary, true)
+		f.write(tokenToString(e.Op))
+		return
+	}
+
+	// A prefix operand needs parentheses in every mode: (-5)! differs
+	// from -5!, which parses as -(5!).
+	if _, ok := e.Operand.(*ast.ParenExpr); !ok && ast.ExprPrecedence(e.Operand) <= token.PrecUnary {
+		f.write("(")
+		f.Format(e.Operand)
+		f.write(")")
+	} else {
+		f.Format(e.Operand)
+	}
+	f.write(tokenToString(e.Op))
+}
+
+// formatOrderByItems writes a comma-separated ORDER BY list, without the
+// ORDER BY keyword.
+func (f *Formatter) formatOrderByItems(items []*ast.OrderByExpr) {
+	for i, ob := range items {
+		if i > 0 {
+			f.write(", ")
+		}
+		f.Format(ob.Expr)
+		if ob.Desc {
+			f.write(" ")
+			f.writeKeyword("DESC")
+		} else if ob.Direction == ast.SortAsc {
+			f.write(" ")
+			f.writeKeyword("ASC")
+		}
+		if ob.NullsFirst != nil {
+			f.write(" ")
+			f.writeKeyword("NULLS")
+			f.write(" ")
+			if *ob.NullsFirst {
+				f.writeKeyword("FIRST")
+			} else {
+				f.writeKeyword("LAST")
+			}
+		}
+	}
+}
+
 func (f *Formatter) formatFuncExpr(e *ast.FuncExpr) {
 	f.writeFuncName(e.Name)
 	f.write("(")
@@ -1037,9 +2125,29 @@ func (f *Formatter) formatFuncExpr(e *ast.FuncExpr) {
 		if i > 0 {
 			f.write(", ")
 		}
+		if i < len(e.ArgNames) && e.ArgNames[i] != "" {
+			f.writeIdent(e.ArgNames[i])
+			f.write(" => ")
+		}
 		f.Format(arg)
 	}
 	f.write(")")
+	if len(e.WithinGroup) > 0 {
+		f.write(" ")
+		f.writeKeyword("WITHIN GROUP")
+		f.write(" (")
+		f.writeKeyword("ORDER BY")
+		f.write(" ")
+		f.formatOrderByItems(e.WithinGroup)
+		f.write(")")
+	}
+	if e.IgnoreNulls {
+		f.write(" ")
+		f.writeKeyword("IGNORE NULLS")
+	} else if e.RespectNulls {
+		f.write(" ")
+		f.writeKeyword("RESPECT NULLS")
+	}
 	if e.Filter != nil {
 		f.write(" ")
 		f.writeKeyword("FILTER")
@@ -1085,16 +2193,7 @@ func (f *Formatter) formatWindowSpec(spec *ast.WindowSpec) {
 		}
 		f.writeKeyword("ORDER BY")
 		f.write(" ")
-		for i, ob := range spec.OrderBy {
-			if i > 0 {
-				f.write(", ")
-			}
-			f.Format(ob.Expr)
-			if ob.Desc {
-				f.write(" ")
-				f.writeKeyword("DESC")
-			}
-		}
+		f.formatOrderByItems(spec.OrderBy)
 	}
 	if spec.Frame != nil {
 		f.write(" ")
@@ -1124,6 +2223,21 @@ func (f *Formatter) formatWindowFrame(frame *ast.WindowFrame) {
 	} else {
 		f.formatFrameBound(frame.Start)
 	}
+
+	switch frame.Exclude {
+	case ast.ExcludeCurrentRow:
+		f.write(" ")
+		f.writeKeyword("EXCLUDE CURRENT ROW")
+	case ast.ExcludeGroup:
+		f.write(" ")
+		f.writeKeyword("EXCLUDE GROUP")
+	case ast.ExcludeTies:
+		f.write(" ")
+		f.writeKeyword("EXCLUDE TIES")
+	case ast.ExcludeNoOthers:
+		f.write(" ")
+		f.writeKeyword("EXCLUDE NO OTHERS")
+	}
 }
 
 func (f *Formatter) formatFrameBound(bound *ast.FrameBound) {
@@ -1172,6 +2286,21 @@ func (f *Formatter) formatCaseExpr(e *ast.CaseExpr) {
 }
 
 func (f *Formatter) formatCastExpr(e *ast.CastExpr) {
+	if e.Postgres {
+		// :: binds tighter than any operator, so a compound operand
+		// keeps its parentheses: (a + b)::INT.
+		operand := f.stripParens(e.Expr)
+		if _, ok := operand.(*ast.ParenExpr); !ok && ast.ExprPrecedence(operand) < token.PrecHighest {
+			f.write("(")
+			f.Format(operand)
+			f.write(")")
+		} else {
+			f.Format(operand)
+		}
+		f.write("::")
+		f.formatDataType(e.Type)
+		return
+	}
 	f.writeKeyword("CAST")
 	f.write("(")
 	f.Format(e.Expr)
@@ -1182,6 +2311,32 @@ func (f *Formatter) formatCastExpr(e *ast.CastExpr) {
 	f.write(")")
 }
 
+func (f *Formatter) formatConvertExpr(e *ast.ConvertExpr) {
+	f.writeKeyword("CONVERT")
+	f.write("(")
+	switch {
+	case e.Type == nil:
+		f.Format(e.Expr)
+		f.write(" ")
+		f.writeKeyword("USING")
+		f.write(" ")
+		f.write(e.Charset)
+	case e.TypeFirst:
+		f.formatDataType(e.Type)
+		f.write(", ")
+		f.Format(e.Expr)
+		if e.Style != nil {
+			f.write(", ")
+			f.Format(e.Style)
+		}
+	default:
+		f.Format(e.Expr)
+		f.write(", ")
+		f.formatDataType(e.Type)
+	}
+	f.write(")")
+}
+
 func (f *Formatter) formatColName(c *ast.ColName) {
 	for i, part := range c.Parts {
 		if i > 0 {
@@ -1189,9 +2344,16 @@ func (f *Formatter) formatColName(c *ast.ColName) {
 		}
 		f.writeIdent(part)
 	}
+	if c.OuterJoin {
+		f.write("(+)")
+	}
 }
 
 func (f *Formatter) formatTableName(t *ast.TableName) {
+	if t.Only {
+		f.writeKeyword("ONLY")
+		f.write(" ")
+	}
 	for i, part := range t.Parts {
 		if i > 0 {
 			f.write(".")
@@ -1204,26 +2366,73 @@ func (f *Formatter) formatLiteral(l *ast.Literal) {
 	switch l.Type {
 	case ast.LiteralNull:
 		f.writeKeyword("NULL")
+	case ast.LiteralDefault:
+		f.writeKeyword("DEFAULT")
 	case ast.LiteralString:
-		f.formatStringLiteral(l.Value)
+		if l.DollarQuoted || f.opts.DollarQuotes && strings.Contains(l.Value, "'") {
+			f.formatDollarQuoted(l.Value, l.DollarTag)
+		} else {
+			f.formatStringLiteral(l.Value)
+		}
 	case ast.LiteralBool:
 		f.writeKeyword(l.Value)
+	case ast.LiteralBlob:
+		// Value is the literal as written (X'1F', b'101'), kept so a bit
+		// string is not turned into a hex or decimal one.
+		f.write(l.Value)
 	default:
 		f.write(l.Value)
 	}
 }
 
+func (f *Formatter) formatTypedLiteral(l *ast.TypedLiteral) {
+	f.writeKeyword(l.Type)
+	f.write(" ")
+	f.formatStringLiteral(l.Value)
+}
+
+// formatStringLiteral writes s as a quoted literal. The content is never
+// normalized: whitespace, newlines and arbitrary bytes are written as is,
+// so parsing the output yields exactly s.
 func (f *Formatter) formatStringLiteral(s string) {
 	// The lexer returns string content without enclosing quotes.
 	// We need to add quotes and escape any internal quotes/backslashes.
 	f.write("'")
-	// Escape both single quotes and backslashes for round-trip safety
-	escaped := strings.ReplaceAll(s, "\\", "\\\\")
+	escaped := s
+	if f.opts.BackslashEscapes {
+		escaped = strings.ReplaceAll(escaped, "\\", "\\\\")
+	}
 	escaped = strings.ReplaceAll(escaped, "'", "''")
 	f.write(escaped)
 	f.write("'")
 }
 
+// formatDollarQuoted writes s between $tag$ delimiters. If s contains the
+// closing delimiter, or ends in a way that would run into it, tags q, q1,
+// q2, ... are tried instead.
+func (f *Formatter) formatDollarQuoted(s, tag string) {
+	for i := 0; !dollarQuotable(s, tag); i++ {
+		tag = "q"
+		if i > 0 {
+			tag += itoa(i)
+		}
+	}
+	f.write("$")
+	f.write(tag)
+	f.write("$")
+	f.write(s)
+	f.write("$")
+	f.write(tag)
+	f.write("$")
+}
+
+// dollarQuotable reports whether $tag$s$tag$ scans back as s: the first
+// closing delimiter must be the one after s.
+func dollarQuotable(s, tag string) bool {
+	delim := "$" + tag + "$"
+	return strings.Index(s+delim, delim) == len(s)
+}
+
 func (f *Formatter) formatParam(p *ast.Param) {
 	switch p.Type {
 	case ast.ParamQuestion:
@@ -1248,6 +2457,42 @@ func (f *Formatter) formatAliasedTableExpr(a *ast.AliasedTableExpr) {
 		f.write(" ")
 		f.writeIdent(a.Alias)
 	}
+	if a.Sample != nil {
+		f.write(" ")
+		f.formatTableSample(a.Sample)
+	}
+}
+
+func (f *Formatter) formatTableSample(s *ast.TableSample) {
+	f.writeKeyword(s.Keyword)
+	if s.Method != "" {
+		f.write(" ")
+		f.writeKeyword(s.Method)
+	}
+	f.write(" (")
+	f.Format(s.Size)
+	if s.Percent {
+		f.write(" ")
+		f.writeKeyword("PERCENT")
+	} else if s.Rows {
+		f.write(" ")
+		f.writeKeyword("ROWS")
+	}
+	f.write(")")
+	if s.Repeatable != nil {
+		f.write(" ")
+		f.writeKeyword("REPEATABLE")
+		f.write(" (")
+		f.Format(s.Repeatable)
+		f.write(")")
+	}
+	if s.Seed != nil {
+		f.write(" ")
+		f.writeKeyword("SEED")
+		f.write(" (")
+		f.Format(s.Seed)
+		f.write(")")
+	}
 }
 
 func (f *Formatter) formatJoinExpr(j *ast.JoinExpr) {
@@ -1268,6 +2513,14 @@ func (f *Formatter) formatJoinExpr(j *ast.JoinExpr) {
 		f.writeKeyword("FULL JOIN")
 	case ast.JoinCross:
 		f.writeKeyword("CROSS JOIN")
+	case ast.JoinLeftSemi:
+		f.writeKeyword("LEFT SEMI JOIN")
+	case ast.JoinLeftAnti:
+		f.writeKeyword("LEFT ANTI JOIN")
+	case ast.JoinRightSemi:
+		f.writeKeyword("RIGHT SEMI JOIN")
+	case ast.JoinRightAnti:
+		f.writeKeyword("RIGHT ANTI JOIN")
 	}
 	f.write(" ")
 	f.Format(j.Right)
@@ -1292,7 +2545,7 @@ func (f *Formatter) formatJoinExpr(j *ast.JoinExpr) {
 }
 
 func (f *Formatter) formatInExpr(e *ast.InExpr) {
-	f.Format(e.Expr)
+	f.formatPredicateOperand(e.Expr)
 	if e.Not {
 		f.write(" ")
 		f.writeKeyword("NOT")
@@ -1314,7 +2567,7 @@ func (f *Formatter) formatInExpr(e *ast.InExpr) {
 }
 
 func (f *Formatter) formatBetweenExpr(e *ast.BetweenExpr) {
-	f.Format(e.Expr)
+	f.formatPredicateOperand(e.Expr)
 	if e.Not {
 		f.write(" ")
 		f.writeKeyword("NOT")
@@ -1322,15 +2575,15 @@ func (f *Formatter) formatBetweenExpr(e *ast.BetweenExpr) {
 	f.write(" ")
 	f.writeKeyword("BETWEEN")
 	f.write(" ")
-	f.Format(e.Low)
+	f.formatPredicateOperand(e.Low)
 	f.write(" ")
 	f.writeKeyword("AND")
 	f.write(" ")
-	f.Format(e.High)
+	f.formatPredicateOperand(e.High)
 }
 
 func (f *Formatter) formatLikeExpr(e *ast.LikeExpr) {
-	f.Format(e.Expr)
+	f.formatPredicateOperand(e.Expr)
 	if e.Not {
 		f.write(" ")
 		f.writeKeyword("NOT")
@@ -1342,7 +2595,30 @@ func (f *Formatter) formatLikeExpr(e *ast.LikeExpr) {
 		f.writeKeyword("LIKE")
 	}
 	f.write(" ")
-	f.Format(e.Pattern)
+	switch {
+	case e.Quantifier == "":
+		f.formatPredicateOperand(e.Pattern)
+	case e.Patterns != nil:
+		f.writeKeyword(e.Quantifier)
+		f.write(" (")
+		for i, pattern := range e.Patterns {
+			if i > 0 {
+				f.write(", ")
+			}
+			f.Format(pattern)
+		}
+		f.write(")")
+	default:
+		f.writeKeyword(e.Quantifier)
+		f.write(" ")
+		if _, ok := e.Pattern.(*ast.Subquery); ok {
+			f.Format(e.Pattern)
+		} else {
+			f.write("(")
+			f.Format(e.Pattern)
+			f.write(")")
+		}
+	}
 	if e.Escape != nil {
 		f.write(" ")
 		f.writeKeyword("ESCAPE")
@@ -1352,7 +2628,7 @@ func (f *Formatter) formatLikeExpr(e *ast.LikeExpr) {
 }
 
 func (f *Formatter) formatIsExpr(e *ast.IsExpr) {
-	f.Format(e.Expr)
+	f.formatPredicateOperand(e.Expr)
 	f.write(" ")
 	f.writeKeyword("IS")
 	if e.Not {
@@ -1395,8 +2671,13 @@ func (f *Formatter) formatIntervalExpr(e *ast.IntervalExpr) {
 func (f *Formatter) formatExtractExpr(e *ast.ExtractExpr) {
 	f.writeKeyword("EXTRACT")
 	f.write("(")
-	// Use writeIdent to handle empty or special field names
-	f.writeIdent(e.Field)
+	// Fields such as YEAR are keywords but need no quotes; use writeIdent
+	// only for empty or special field names
+	if needsQuotingNonKeyword(e.Field) {
+		f.writeIdent(e.Field)
+	} else {
+		f.writeKeyword(e.Field)
+	}
 	f.write(" ")
 	f.writeKeyword("FROM")
 	f.write(" ")
@@ -1480,6 +2761,32 @@ func (f *Formatter) formatValuesStmt(s *ast.ValuesStmt) {
 	}
 }
 
+func (f *Formatter) formatTableStmt(s *ast.TableStmt) {
+	f.writeKeyword("TABLE")
+	f.write(" ")
+	f.formatTableName(s.Table)
+	if len(s.OrderBy) > 0 {
+		f.write(" ")
+		f.writeKeyword("ORDER BY")
+		f.write(" ")
+		f.formatOrderByItems(s.OrderBy)
+	}
+	if s.Limit != nil {
+		if s.Limit.Count != nil {
+			f.write(" ")
+			f.writeKeyword("LIMIT")
+			f.write(" ")
+			f.Format(s.Limit.Count)
+		}
+		if s.Limit.Offset != nil {
+			f.write(" ")
+			f.writeKeyword("OFFSET")
+			f.write(" ")
+			f.Format(s.Limit.Offset)
+		}
+	}
+}
+
 func needsQuoting(id string) bool {
 	if needsQuotingNonKeyword(id) {
 		return true
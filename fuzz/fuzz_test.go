@@ -480,10 +480,41 @@ func FuzzWalk(f *testing.F) {
 			return true
 		})
 
-		// Walk with early termination
+		// Walk skipping the children of every node after the fifth. It
+		// never visits more than the full walk, and visits fewer as soon
+		// as one of the skipped nodes has children of its own.
+		seen, pruned := 0, false
 		machparse.Walk(stmt, func(n machparse.Node) bool {
-			return count < 5
+			seen++
+			if seen < 5 {
+				return true
+			}
+			if !pruned {
+				sub := 0
+				machparse.Walk(n, func(machparse.Node) bool {
+					sub++
+					return true
+				})
+				pruned = sub > 1
+			}
+			return false
+		})
+		if seen > count || (pruned && seen == count) {
+			t.Errorf("Walk skipping children visited %d of %d nodes", seen, count)
+		}
+
+		// Walk with early termination
+		seen = 0
+		err = machparse.WalkErr(stmt, func(n machparse.Node) error {
+			seen++
+			if seen == 5 {
+				return machparse.SkipAll
+			}
+			return nil
 		})
+		if err != nil || seen > 5 || seen > count {
+			t.Errorf("WalkErr with SkipAll visited %d of %d nodes, err %v", seen, count, err)
+		}
 	})
 }
 
@@ -674,6 +705,49 @@ func FuzzFormat(f *testing.F) {
 	})
 }
 
+// FuzzStringLiteral tests that formatting a string literal and parsing it
+// back yields exactly the original bytes.
+func FuzzStringLiteral(f *testing.F) {
+	seeds := []string{
+		"",
+		"plain",
+		"it's",
+		"''",
+		`back\slash`,
+		`trailing\`,
+		`\n is not a newline`,
+		"  leading and  doubled  spaces  ",
+		"tab\there",
+		"line\none\r\ntwo",
+		"\x00nul",
+		`"double" quotes`,
+		"$$dollar$$",
+		"-- not a comment",
+		"/* not a comment */",
+		"unicode: \u00e9\u4e2d",
+		"\xff\xfe invalid utf-8",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, value string) {
+		lit := &machparse.Literal{Type: machparse.LiteralString, Value: value}
+		sql := "SELECT " + machparse.String(lit)
+		stmt, err := machparse.Parse(sql)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", sql, err)
+		}
+		got, ok := stmt.(*machparse.SelectStmt).Columns[0].(*machparse.AliasedExpr).Expr.(*machparse.Literal)
+		if !ok || got.Type != machparse.LiteralString {
+			t.Fatalf("Parse(%q) did not yield a string literal", sql)
+		}
+		if got.Value != value {
+			t.Errorf("Round trip changed value:\nOriginal: %q\nFormatted: %s\nParsed:   %q", value, sql, got.Value)
+		}
+	})
+}
+
 // FuzzPooling tests that AST pooling works correctly.
 // Nodes can be returned to the pool via Repool() for reuse.
 func FuzzPooling(f *testing.F) {
@@ -2,6 +2,7 @@
 package lexer
 
 import (
+	"strings"
 	"sync"
 
 	"github.com/freeeve/machparse/token"
@@ -16,6 +17,20 @@ type Lexer struct {
 	linePos int        // position of current line start
 	item    token.Item // most recently scanned item
 	peeked  bool       // whether item contains a peeked token
+	itemEnd int        // offset just past item
+	end     int        // offset just past the token last returned by Next
+
+	// keywords holds the caller's keywords, lowercased, consulted before
+	// the built-in table.
+	keywords map[string]token.Token
+
+	// standardStrings takes backslashes in single-quoted strings
+	// literally; see SetStandardConformingStrings.
+	standardStrings bool
+
+	// doubleQuoteStrings scans "..." as a string literal rather than a
+	// quoted identifier; see SetDoubleQuoteIsString.
+	doubleQuoteStrings bool
 }
 
 var lexerPool = sync.Pool{
@@ -25,9 +40,10 @@ var lexerPool = sync.Pool{
 // New creates a new Lexer for the input string.
 func New(input string) *Lexer {
 	return &Lexer{
-		input:   input,
-		line:    1,
-		linePos: 0,
+		input:           input,
+		line:            1,
+		linePos:         0,
+		standardStrings: true,
 	}
 }
 
@@ -43,24 +59,87 @@ func Put(l *Lexer) {
 	lexerPool.Put(l)
 }
 
-// Reset resets the lexer to scan new input.
+// Reset resets the lexer to scan new input, dropping any keywords added
+// with SetKeywords and restoring standard conforming strings and quoted
+// identifiers.
 func (l *Lexer) Reset(input string) {
 	l.input = input
+	l.keywords = nil
+	l.standardStrings = true
+	l.doubleQuoteStrings = false
+	l.rewind()
+}
+
+// SetStandardConformingStrings sets whether backslashes in single-quoted
+// strings are literal characters, as in standard SQL and PostgreSQL with
+// standard_conforming_strings on, the default: 'a\nb' is the four
+// characters a, \, n, b and only a doubled quote is an escape. Turned
+// off, C-style escapes are interpreted as in MySQL: \n, \t, \r, \\, \'
+// and \"; other backslashes are kept with the character after them.
+// Scanning restarts at the beginning of the input.
+func (l *Lexer) SetStandardConformingStrings(on bool) {
+	l.standardStrings = on
+	l.rewind()
+}
+
+// SetDoubleQuoteIsString sets whether "..." is a string literal, as in
+// MySQL without the ANSI_QUOTES SQL mode, instead of a quoted identifier
+// as in standard SQL, the default. Such strings follow the same escape
+// rules as single-quoted ones. Scanning restarts at the beginning of the
+// input.
+func (l *Lexer) SetDoubleQuoteIsString(on bool) {
+	l.doubleQuoteStrings = on
+	l.rewind()
+}
+
+// SetKeywords adds dialect keywords to those the lexer recognizes,
+// mapping each word, matched case-insensitively, to its token. They take
+// precedence over the built-in keywords; map a word to token.RESERVED to
+// reserve it without giving it any other meaning. Scanning restarts at
+// the beginning of the input so every token sees the new keywords.
+//
+// The built-in keyword table is never modified: the words are copied into
+// this lexer, so lexers with different keywords can run concurrently.
+func (l *Lexer) SetKeywords(keywords map[string]token.Token) {
+	l.keywords = make(map[string]token.Token, len(keywords))
+	for word, tok := range keywords {
+		l.keywords[strings.ToLower(word)] = tok
+	}
+	l.rewind()
+}
+
+// rewind restarts scanning at the beginning of the input.
+func (l *Lexer) rewind() {
 	l.start = 0
 	l.pos = 0
 	l.line = 1
 	l.linePos = 0
 	l.item = token.Item{}
 	l.peeked = false
+	l.itemEnd = 0
+	l.end = 0
+}
+
+// DollarTag reports whether item, a string scanned by l, was dollar-quoted
+// and returns its tag: "" for $$...$$ and tag for $tag$...$tag$.
+func (l *Lexer) DollarTag(item token.Item) (string, bool) {
+	off := item.Pos.Offset
+	if item.Type != token.STRING || off >= len(l.input) || l.input[off] != '$' {
+		return "", false
+	}
+	end := strings.IndexByte(l.input[off+1:], '$')
+	return l.input[off+1 : off+1+end], true
 }
 
 // Next returns the next token.
 func (l *Lexer) Next() token.Item {
 	if l.peeked {
 		l.peeked = false
-		return l.item
+	} else {
+		l.item = l.scan()
+		l.itemEnd = l.pos
 	}
-	l.item = l.scan()
+	l.end = l.itemEnd
 	return l.item
 }
 
@@ -68,11 +147,24 @@ func (l *Lexer) Next() token.Item {
 func (l *Lexer) Peek() token.Item {
 	if !l.peeked {
 		l.item = l.scan()
+		l.itemEnd = l.pos
 		l.peeked = true
 	}
 	return l.item
 }
 
+// End returns the byte offset just past the token most recently returned
+// by Next, so that input[item.Pos.Offset:End()] is its source text.
+func (l *Lexer) End() int {
+	return l.end
+}
+
+// Source returns the input from byte offset start up to end, such as the
+// text between two tokens.
+func (l *Lexer) Source(start, end int) string {
+	return l.input[start:end]
+}
+
 // scan performs the actual lexical analysis.
 func (l *Lexer) scan() token.Item {
 	l.skipWhitespace()
@@ -87,6 +179,11 @@ func (l *Lexer) scan() token.Item {
 	// Fast path for common single-character tokens
 	switch ch {
 	case '(':
+		// Oracle outer join marker: col(+)
+		if l.pos+2 < len(l.input) && l.input[l.pos+1] == '+' && l.input[l.pos+2] == ')' {
+			l.pos += 3
+			return l.makeItem(token.OUTERJOIN, "(+)")
+		}
 		l.pos++
 		return l.makeItem(token.LPAREN, "(")
 	case ')':
@@ -134,11 +231,18 @@ func (l *Lexer) scan() token.Item {
 	case '\'':
 		return l.scanString('\'')
 	case '"':
+		if l.doubleQuoteStrings {
+			return l.scanString('"')
+		}
 		return l.scanQuotedIdentifier()
 	case '`':
 		return l.scanBacktickIdentifier()
 	case '=':
 		l.pos++
+		if l.pos < len(l.input) && l.input[l.pos] == '>' {
+			l.pos++
+			return l.makeItem(token.FATARROW, "=>")
+		}
 		return l.makeItem(token.EQ, "=")
 	case '<':
 		return l.scanLessThan()
@@ -204,10 +308,23 @@ func (l *Lexer) skipWhitespace() {
 }
 
 func (l *Lexer) scanIdentifier() token.Item {
+	if l.pos+1 < len(l.input) && l.input[l.pos+1] == '\'' {
+		switch l.input[l.pos] {
+		case 'x', 'X':
+			return l.scanBlob(isHexDigit)
+		case 'b', 'B':
+			return l.scanBlob(isBinaryDigit)
+		}
+	}
 	for l.pos < len(l.input) && isIdentChar(l.input[l.pos]) {
 		l.pos++
 	}
 	val := l.input[l.start:l.pos]
+	if l.keywords != nil {
+		if tok, ok := l.keywords[strings.ToLower(val)]; ok {
+			return l.makeItem(tok, val)
+		}
+	}
 	tok := token.LookupIdent(val)
 	return l.makeItem(tok, val)
 }
@@ -259,6 +376,11 @@ func (l *Lexer) scanNumber() token.Item {
 }
 
 func (l *Lexer) scanString(quote byte) token.Item {
+	if l.standardStrings {
+		return l.scanLiteralString(quote)
+	}
+	line, linePos := l.line, l.linePos
+	escapedQuote := false
 	l.pos++ // skip opening quote
 	var buf []byte
 	for l.pos < len(l.input) {
@@ -288,6 +410,7 @@ func (l *Lexer) scanString(quote byte) token.Item {
 				buf = append(buf, '\\')
 			case '\'':
 				buf = append(buf, '\'')
+				escapedQuote = true
 			case '"':
 				buf = append(buf, '"')
 			default:
@@ -304,9 +427,57 @@ func (l *Lexer) scanString(quote byte) token.Item {
 		buf = append(buf, ch)
 		l.pos++
 	}
+	if escapedQuote {
+		// A backslash before the closing quote, as in the standard SQL
+		// ESCAPE '\', left the string unterminated: read it again with
+		// backslashes taken literally.
+		l.pos, l.line, l.linePos = l.start, line, linePos
+		return l.scanLiteralString(quote)
+	}
 	return l.makeItem(token.ILLEGAL, l.input[l.start:l.pos])
 }
 
+// scanLiteralString scans a string in which only a doubled quote is an
+// escape, as standard SQL defines them.
+func (l *Lexer) scanLiteralString(quote byte) token.Item {
+	l.pos++ // skip opening quote
+	var buf []byte
+	for l.pos < len(l.input) {
+		ch := l.input[l.pos]
+		if ch == quote {
+			if l.pos+1 < len(l.input) && l.input[l.pos+1] == quote {
+				buf = append(buf, quote)
+				l.pos += 2
+				continue
+			}
+			l.pos++
+			return l.makeItem(token.STRING, string(buf))
+		}
+		if ch == '\n' {
+			l.line++
+			l.linePos = l.pos + 1
+		}
+		buf = append(buf, ch)
+		l.pos++
+	}
+	return l.makeItem(token.ILLEGAL, l.input[l.start:l.pos])
+}
+
+// scanBlob scans a hex (X'1F') or bit (b'101') string literal. The value
+// is the literal as written, prefix and quotes included, so the formatter
+// can emit it unchanged.
+func (l *Lexer) scanBlob(isValid func(byte) bool) token.Item {
+	l.pos += 2 // skip prefix and opening quote
+	for l.pos < len(l.input) && isValid(l.input[l.pos]) {
+		l.pos++
+	}
+	if l.pos >= len(l.input) || l.input[l.pos] != '\'' {
+		return l.makeItem(token.ILLEGAL, l.input[l.start:l.pos])
+	}
+	l.pos++
+	return l.makeItem(token.BLOB, l.input[l.start:l.pos])
+}
+
 func (l *Lexer) scanQuotedIdentifier() token.Item {
 	l.pos++ // skip opening "
 	var buf []byte
@@ -490,7 +661,7 @@ func (l *Lexer) scanBang() token.Item {
 		l.pos++
 		return l.makeItem(token.NEQ, "!=")
 	}
-	return l.makeItem(token.ILLEGAL, "!")
+	return l.makeItem(token.BANG, "!")
 }
 
 func (l *Lexer) scanPipe() token.Item {
@@ -676,3 +847,7 @@ func isDigit(ch byte) bool {
 func isHexDigit(ch byte) bool {
 	return isDigit(ch) || (ch >= 'a' && ch <= 'f') || (ch >= 'A' && ch <= 'F')
 }
+
+func isBinaryDigit(ch byte) bool {
+	return ch == '0' || ch == '1'
+}
@@ -1,6 +1,7 @@
 package lexer
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/freeeve/machparse/token"
@@ -63,6 +64,30 @@ func TestLexerBasicTokens(t *testing.T) {
 				{Type: token.EOF, Value: ""},
 			},
 		},
+		{
+			input: "a.id = b.id(+)",
+			expected: []token.Item{
+				{Type: token.IDENT, Value: "a"},
+				{Type: token.DOT, Value: "."},
+				{Type: token.IDENT, Value: "id"},
+				{Type: token.EQ, Value: "="},
+				{Type: token.IDENT, Value: "b"},
+				{Type: token.DOT, Value: "."},
+				{Type: token.IDENT, Value: "id"},
+				{Type: token.OUTERJOIN, Value: "(+)"},
+				{Type: token.EOF, Value: ""},
+			},
+		},
+		{
+			input: "5! != 3",
+			expected: []token.Item{
+				{Type: token.INT, Value: "5"},
+				{Type: token.BANG, Value: "!"},
+				{Type: token.NEQ, Value: "!="},
+				{Type: token.INT, Value: "3"},
+				{Type: token.EOF, Value: ""},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -111,6 +136,34 @@ func TestLexerNumbers(t *testing.T) {
 	}
 }
 
+func TestLexerBlobs(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected token.Item
+	}{
+		{"X'1F'", token.Item{Type: token.BLOB, Value: "X'1F'"}},
+		{"x'0a1B'", token.Item{Type: token.BLOB, Value: "x'0a1B'"}},
+		{"b'101'", token.Item{Type: token.BLOB, Value: "b'101'"}},
+		{"B''", token.Item{Type: token.BLOB, Value: "B''"}},
+		{"b'102'", token.Item{Type: token.ILLEGAL, Value: "b'10"}},
+		{"x'1G'", token.Item{Type: token.ILLEGAL, Value: "x'1"}},
+		{"xb", token.Item{Type: token.IDENT, Value: "xb"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			l := New(tt.input)
+			got := l.Next()
+			if got.Type != tt.expected.Type {
+				t.Errorf("expected type %v, got %v", tt.expected.Type, got.Type)
+			}
+			if got.Value != tt.expected.Value {
+				t.Errorf("expected value %q, got %q", tt.expected.Value, got.Value)
+			}
+		})
+	}
+}
+
 func TestLexerStrings(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -120,12 +173,42 @@ func TestLexerStrings(t *testing.T) {
 		{"'hello world'", token.Item{Type: token.STRING, Value: "hello world"}},
 		{"'it''s'", token.Item{Type: token.STRING, Value: "it's"}},
 		{"'line1\nline2'", token.Item{Type: token.STRING, Value: "line1\nline2"}},
+		{"'not\\nescaped'", token.Item{Type: token.STRING, Value: "not\\nescaped"}},
+		{"'\\'", token.Item{Type: token.STRING, Value: "\\"}},
+		{"'a\\''b'", token.Item{Type: token.STRING, Value: "a\\'b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			l := New(tt.input)
+			got := l.Next()
+			if got.Type != tt.expected.Type {
+				t.Errorf("expected type %v, got %v", tt.expected.Type, got.Type)
+			}
+			if got.Value != tt.expected.Value {
+				t.Errorf("expected value %q, got %q", tt.expected.Value, got.Value)
+			}
+		})
+	}
+}
+
+func TestLexerBackslashEscapes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected token.Item
+	}{
 		{"'escaped\\nchar'", token.Item{Type: token.STRING, Value: "escaped\nchar"}},
+		{"'it\\'s'", token.Item{Type: token.STRING, Value: "it's"}},
+		{"'back\\\\slash'", token.Item{Type: token.STRING, Value: "back\\slash"}},
+		{"'\\_kept'", token.Item{Type: token.STRING, Value: "\\_kept"}},
+		{"'\\'", token.Item{Type: token.STRING, Value: "\\"}},
+		{"'a\\b\\'", token.Item{Type: token.STRING, Value: "a\\b\\"}},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.input, func(t *testing.T) {
 			l := New(tt.input)
+			l.SetStandardConformingStrings(false)
 			got := l.Next()
 			if got.Type != tt.expected.Type {
 				t.Errorf("expected type %v, got %v", tt.expected.Type, got.Type)
@@ -163,6 +246,44 @@ func TestLexerQuotedIdentifiers(t *testing.T) {
 	}
 }
 
+func TestLexerDoubleQuoteStrings(t *testing.T) {
+	tests := []struct {
+		input           string
+		standardStrings bool
+		expected        token.Item
+	}{
+		{`"hello"`, true, token.Item{Type: token.STRING, Value: "hello"}},
+		{`"it's"`, true, token.Item{Type: token.STRING, Value: "it's"}},
+		{`"say ""hi"""`, true, token.Item{Type: token.STRING, Value: `say "hi"`}},
+		{`"a\nb"`, true, token.Item{Type: token.STRING, Value: `a\nb`}},
+		{`"a\nb"`, false, token.Item{Type: token.STRING, Value: "a\nb"}},
+		{`"say \"hi\""`, false, token.Item{Type: token.STRING, Value: `say "hi"`}},
+		{`"open`, true, token.Item{Type: token.ILLEGAL, Value: `"open`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			l := New(tt.input)
+			l.SetStandardConformingStrings(tt.standardStrings)
+			l.SetDoubleQuoteIsString(true)
+			got := l.Next()
+			if got.Type != tt.expected.Type {
+				t.Errorf("expected type %v, got %v", tt.expected.Type, got.Type)
+			}
+			if got.Value != tt.expected.Value {
+				t.Errorf("expected value %q, got %q", tt.expected.Value, got.Value)
+			}
+		})
+	}
+
+	l := New(`"a"`)
+	l.SetDoubleQuoteIsString(true)
+	l.Reset(`"a"`)
+	if got := l.Next(); got.Type != token.IDENT {
+		t.Errorf("expected Reset to restore quoted identifiers, got %v", got.Type)
+	}
+}
+
 func TestLexerOperators(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -212,6 +333,31 @@ func TestLexerOperators(t *testing.T) {
 				{Type: token.STRING, Value: "key"},
 			},
 		},
+		{
+			input: "doc#>'{a,b}'#>>'{0}'",
+			expected: []token.Item{
+				{Type: token.IDENT, Value: "doc"},
+				{Type: token.HASHGT, Value: "#>"},
+				{Type: token.STRING, Value: "{a,b}"},
+				{Type: token.HASHDGT, Value: "#>>"},
+				{Type: token.STRING, Value: "{0}"},
+			},
+		},
+		{
+			input: "f(a => 1, b=>2)",
+			expected: []token.Item{
+				{Type: token.IDENT, Value: "f"},
+				{Type: token.LPAREN, Value: "("},
+				{Type: token.IDENT, Value: "a"},
+				{Type: token.FATARROW, Value: "=>"},
+				{Type: token.INT, Value: "1"},
+				{Type: token.COMMA, Value: ","},
+				{Type: token.IDENT, Value: "b"},
+				{Type: token.FATARROW, Value: "=>"},
+				{Type: token.INT, Value: "2"},
+				{Type: token.RPAREN, Value: ")"},
+			},
+		},
 		{
 			input: "a::int",
 			expected: []token.Item{
@@ -377,6 +523,27 @@ func TestLexerPeek(t *testing.T) {
 	}
 }
 
+func TestLexerEnd(t *testing.T) {
+	input := `SELECT 'it''s'  -- c`
+	l := New(input)
+	var got []string
+	for {
+		item := l.Next()
+		if item.Type == token.EOF {
+			break
+		}
+		if item.Type == token.STRING {
+			// A peeked token does not move End
+			l.Peek()
+		}
+		got = append(got, l.Source(item.Pos.Offset, l.End()))
+	}
+	want := []string{"SELECT", "'it''s'", "-- c"}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
 func TestLexerDollarQuotedStrings(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -425,6 +592,34 @@ func TestLexerKeywords(t *testing.T) {
 	}
 }
 
+func TestLexerSetKeywords(t *testing.T) {
+	l := New("vector Minus minus_x \"vector\"")
+	l.Next() // scanned before SetKeywords, then rescanned
+	l.SetKeywords(map[string]token.Token{"VECTOR": token.RESERVED, "minus": token.EXCEPT})
+
+	want := []token.Item{
+		{Type: token.RESERVED, Value: "vector"},
+		{Type: token.EXCEPT, Value: "Minus"},
+		{Type: token.IDENT, Value: "minus_x"},
+		{Type: token.IDENT, Value: "vector"},
+	}
+	for _, w := range want {
+		got := l.Next()
+		if got.Type != w.Type || got.Value != w.Value {
+			t.Errorf("Got %v %q, want %v %q", got.Type, got.Value, w.Type, w.Value)
+		}
+	}
+
+	// Reset drops the added keywords; the shared table is untouched.
+	l.Reset("vector")
+	if got := l.Next(); got.Type != token.IDENT {
+		t.Errorf("Expected IDENT after Reset, got %v", got.Type)
+	}
+	if token.IsKeyword("vector") {
+		t.Error("SetKeywords changed the built-in keywords")
+	}
+}
+
 func BenchmarkLexer(b *testing.B) {
 	input := `SELECT u.id, u.name, COUNT(o.id) as order_count
 FROM users u
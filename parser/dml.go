@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"strings"
+
 	"github.com/freeeve/machparse/ast"
 	"github.com/freeeve/machparse/token"
 )
@@ -29,9 +31,11 @@ func (p *Parser) parseInsert() *ast.InsertStmt {
 	// Optional column list
 	if p.curIs(token.LPAREN) && !p.peekIs(token.SELECT) {
 		p.advance()
-		for {
-			if !p.curIs(token.IDENT) {
-				break
+		stmt.EmptyColumns = p.curIs(token.RPAREN)
+		for !stmt.EmptyColumns {
+			if !p.curIsIdent() {
+				p.errorf("expected column name")
+				return nil
 			}
 			col := &ast.ColName{
 				StartPos: p.cur.Pos,
@@ -77,36 +81,17 @@ func (p *Parser) parseInsert() *ast.InsertStmt {
 			p.errorf("expected column name after SET")
 			return nil
 		}
-		stmt.Values = [][]ast.Expr{{}}
-		for {
-			if !p.curIsIdent() {
-				break
-			}
-			// Find column index
-			colName := p.curIdentValue()
-			p.advance()
-			if !p.expect(token.EQ) {
-				return nil
-			}
-			val := p.parseExpr()
-			if val == nil {
+		stmt.SetAssignments = p.parseUpdateExprs()
+		for _, ue := range stmt.SetAssignments {
+			if ue.Expr == nil {
 				return nil
 			}
-
-			// Add column and value
-			stmt.Columns = append(stmt.Columns, &ast.ColName{Parts: []string{colName}})
-			stmt.Values[0] = append(stmt.Values[0], val)
-
-			if !p.curIs(token.COMMA) {
-				break
-			}
-			p.advance()
 		}
 	} else if p.curIs(token.DEFAULT) {
 		p.advance()
 		p.expect(token.VALUES)
 		// INSERT ... DEFAULT VALUES
-		stmt.Values = [][]ast.Expr{{}}
+		stmt.DefaultValues = true
 	}
 
 	// ON DUPLICATE KEY UPDATE (MySQL)
@@ -134,10 +119,30 @@ func (p *Parser) parseInsert() *ast.InsertStmt {
 		stmt.Returning = p.parseSelectExprs()
 	}
 
-	stmt.EndPos = p.cur.Pos
+	stmt.EndPos = p.prevPos
 	return stmt
 }
 
+// parseDeleteTargets parses the target list of a MySQL multi-table DELETE.
+func (p *Parser) parseDeleteTargets() []*ast.TableName {
+	var targets []*ast.TableName
+
+	for {
+		tn := p.parseTableName()
+		if tn == nil {
+			return nil
+		}
+		targets = append(targets, tn)
+
+		if !p.curIs(token.COMMA) {
+			break
+		}
+		p.advance()
+	}
+
+	return targets
+}
+
 func (p *Parser) parseValuesList() [][]ast.Expr {
 	var rows [][]ast.Expr
 
@@ -147,23 +152,14 @@ func (p *Parser) parseValuesList() [][]ast.Expr {
 		}
 		p.advance()
 
+		// An empty row, VALUES (), inserts a row of defaults (MySQL).
 		var row []ast.Expr
-		for {
-			if p.curIs(token.DEFAULT) {
-				row = append(row, &ast.Literal{
-					StartPos: p.cur.Pos,
-					EndPos:   p.cur.Pos,
-					Type:     ast.LiteralNull,
-					Value:    "DEFAULT",
-				})
-				p.advance()
-			} else {
-				expr := p.parseExpr()
-				if expr == nil {
-					break
-				}
-				row = append(row, expr)
+		for !p.curIs(token.RPAREN) || len(row) > 0 {
+			expr := p.parseValueOrDefault()
+			if expr == nil {
+				break
 			}
+			row = append(row, expr)
 
 			if !p.curIs(token.COMMA) {
 				break
@@ -183,21 +179,37 @@ func (p *Parser) parseValuesList() [][]ast.Expr {
 	return rows
 }
 
+// parseValueOrDefault parses a VALUES item or assigned value. DEFAULT
+// stands alone there; parsing it here rather than through parseExpr
+// rejects DEFAULT + 1 and the like.
+func (p *Parser) parseValueOrDefault() ast.Expr {
+	if !p.curIs(token.DEFAULT) {
+		return p.parseExpr()
+	}
+	lit := &ast.Literal{
+		StartPos: p.cur.Pos,
+		EndPos:   p.cur.Pos,
+		Type:     ast.LiteralDefault,
+		Value:    "DEFAULT",
+	}
+	p.advance()
+	return lit
+}
+
 func (p *Parser) parseOnConflict() *ast.OnConflict {
 	p.advance() // consume CONFLICT
 
 	conflict := &ast.OnConflict{}
 
-	// Target columns
+	// Conflict target: columns or index expressions
 	if p.curIs(token.LPAREN) {
 		p.advance()
 		for {
-			if p.curIs(token.IDENT) {
-				conflict.Columns = append(conflict.Columns, p.cur.Value)
-				p.advance()
-			} else {
-				break
+			col := p.parseIndexColumn()
+			if col == nil {
+				return nil
 			}
+			conflict.Columns = append(conflict.Columns, col)
 			if !p.curIs(token.COMMA) {
 				break
 			}
@@ -221,6 +233,12 @@ func (p *Parser) parseOnConflict() *ast.OnConflict {
 		p.advance()
 		p.expect(token.SET)
 		conflict.Updates = p.parseUpdateExprs()
+
+		// WHERE condition on the update action
+		if p.curIs(token.WHERE) {
+			p.advance()
+			conflict.UpdateWhere = p.parseExpr()
+		}
 	}
 
 	return conflict
@@ -269,7 +287,7 @@ func (p *Parser) parseUpdate() *ast.UpdateStmt {
 		stmt.Returning = p.parseSelectExprs()
 	}
 
-	stmt.EndPos = p.cur.Pos
+	stmt.EndPos = p.prevPos
 	return stmt
 }
 
@@ -277,19 +295,19 @@ func (p *Parser) parseUpdateExprs() []*ast.UpdateExpr {
 	var exprs []*ast.UpdateExpr
 
 	for {
-		if !p.curIs(token.IDENT) {
+		if !p.curIsIdent() {
 			break
 		}
 
 		startPos := p.cur.Pos
-		parts := []string{p.cur.Value}
+		parts := []string{p.curIdentValue()}
 		p.advance()
 
 		// Check for qualified column name (table.column or schema.table.column)
 		for p.curIs(token.DOT) {
 			p.advance()
-			if p.curIs(token.IDENT) {
-				parts = append(parts, p.cur.Value)
+			if p.curIsIdent() {
+				parts = append(parts, p.curIdentValue())
 				p.advance()
 			} else {
 				break
@@ -299,13 +317,13 @@ func (p *Parser) parseUpdateExprs() []*ast.UpdateExpr {
 		ue := &ast.UpdateExpr{
 			Column: &ast.ColName{
 				StartPos: startPos,
-				EndPos:   p.cur.Pos,
+				EndPos:   p.prevPos,
 				Parts:    parts,
 			},
 		}
 
 		p.expect(token.EQ)
-		ue.Expr = p.parseExpr()
+		ue.Expr = p.parseValueOrDefault()
 
 		exprs = append(exprs, ue)
 
@@ -327,10 +345,28 @@ func (p *Parser) parseDelete() *ast.DeleteStmt {
 	// Optional FROM
 	if p.curIs(token.FROM) {
 		p.advance()
+		stmt.Table = p.parseTableExpr()
+	} else if p.curIsIdent() && (p.peekIs(token.COMMA) || p.peekIs(token.FROM) || p.peekIs(token.DOT)) {
+		// MySQL multi-table form: DELETE t1, t2 FROM t1 JOIN t2 ...
+		stmt.Targets = p.parseDeleteTargets()
+		if stmt.Targets == nil {
+			return nil
+		}
+		if p.curIs(token.FROM) {
+			p.advance()
+			stmt.Table = p.parseTableExpr()
+		} else if len(stmt.Targets) == 1 {
+			// DELETE schema.t [AS] x WHERE ... (FROM omitted)
+			stmt.Table = p.parseTableAlias(stmt.Targets[0], false)
+			stmt.Targets = nil
+		} else {
+			p.errorf("expected FROM after DELETE target list")
+			return nil
+		}
+	} else {
+		stmt.Table = p.parseTableExpr()
 	}
 
-	stmt.Table = p.parseTableExpr()
-
 	// USING clause (PostgreSQL)
 	if p.curIs(token.USING) {
 		p.advance()
@@ -359,6 +395,128 @@ func (p *Parser) parseDelete() *ast.DeleteStmt {
 		stmt.Returning = p.parseSelectExprs()
 	}
 
-	stmt.EndPos = p.cur.Pos
+	stmt.EndPos = p.prevPos
+	return stmt
+}
+
+// parseCopy parses a PostgreSQL COPY statement.
+func (p *Parser) parseCopy() ast.Statement {
+	pos := p.cur.Pos
+	p.advance() // consume COPY
+
+	stmt := &ast.CopyStmt{StartPos: pos}
+
+	if p.curIs(token.LPAREN) {
+		p.advance()
+		stmt.Query = p.parseStatement()
+		if stmt.Query == nil || !p.expect(token.RPAREN) {
+			return nil
+		}
+	} else {
+		stmt.Table = p.parseTableName()
+		if stmt.Table == nil {
+			return nil
+		}
+		if p.curIs(token.LPAREN) {
+			stmt.Columns = p.parseColumnNameList()
+		}
+	}
+
+	switch {
+	case p.curIs(token.FROM):
+		stmt.From = true
+	case p.curIs(token.TO):
+	default:
+		p.errorf("expected FROM or TO in COPY, got %v", p.cur.Type)
+		return nil
+	}
+	p.advance()
+
+	switch {
+	case p.curIs(token.STRING):
+		stmt.File = p.cur.Value
+		p.advance()
+	case p.curIsIdent() && strings.EqualFold(p.cur.Value, "PROGRAM"):
+		p.advance()
+		if !p.curIs(token.STRING) {
+			p.errorf("expected command string after PROGRAM")
+			return nil
+		}
+		stmt.Program = true
+		stmt.File = p.cur.Value
+		p.advance()
+	case p.curIsIdent() && (strings.EqualFold(p.cur.Value, "STDIN") || strings.EqualFold(p.cur.Value, "STDOUT")):
+		stmt.Stdio = true
+		p.advance()
+	default:
+		p.errorf("expected file name, PROGRAM, STDIN or STDOUT in COPY")
+		return nil
+	}
+
+	if p.curIs(token.WITH) {
+		p.advance()
+	}
+	if p.curIs(token.LPAREN) {
+		stmt.Options = p.parseCopyOptions()
+	} else {
+		// Pre-9.0 syntax: BINARY, CSV HEADER, DELIMITER [AS] ',' ...
+		for p.curIsIdent() {
+			opt := &ast.Option{Name: p.cur.Value}
+			p.advance()
+			if p.curIs(token.AS) {
+				p.advance()
+			}
+			if p.curIs(token.STRING) {
+				opt.Value = p.cur.Value
+				opt.Quoted = true
+				p.advance()
+			}
+			stmt.Options = append(stmt.Options, opt)
+			stmt.LegacyOptions = true
+		}
+	}
+
+	stmt.EndPos = p.prevPos
 	return stmt
 }
+
+// parseCopyOptions parses a parenthesized COPY option list:
+// (FORMAT csv, HEADER true, FORCE_QUOTE (a, b), ...)
+func (p *Parser) parseCopyOptions() []*ast.Option {
+	p.advance() // consume (
+
+	var opts []*ast.Option
+	for {
+		if !p.curIsIdent() {
+			p.errorf("expected COPY option name")
+			return nil
+		}
+		opt := &ast.Option{Name: p.cur.Value}
+		p.advance()
+
+		switch {
+		case p.curIs(token.LPAREN):
+			// Column list, e.g. FORCE_NOT_NULL (a, b)
+			opt.Value = "(" + strings.Join(p.parseColumnNameList(), ", ") + ")"
+		case p.curIs(token.ASTERISK):
+			opt.Value = "*"
+			p.advance()
+		case p.curIs(token.STRING):
+			opt.Value = p.cur.Value
+			opt.Quoted = true
+			p.advance()
+		case p.curIs(token.INT), p.curIsIdent():
+			opt.Value = p.cur.Value
+			p.advance()
+		}
+		opts = append(opts, opt)
+
+		if !p.curIs(token.COMMA) {
+			break
+		}
+		p.advance()
+	}
+	p.expect(token.RPAREN)
+
+	return opts
+}
@@ -18,51 +18,29 @@ func isNilExpr(e ast.Expr) bool {
 	return v.Kind() == reflect.Ptr && v.IsNil()
 }
 
-// Operator precedence levels (higher = tighter binding)
+// Operator precedence levels (higher = tighter binding), see token.Precedence.
 const (
-	precLowest     = 0
-	precOr         = 1  // OR
-	precXor        = 2  // XOR
-	precAnd        = 3  // AND
-	precNot        = 4  // NOT (prefix)
-	precComparison = 5  // =, <>, <, >, <=, >=, IS, LIKE, IN, BETWEEN
-	precBitOr      = 6  // |
-	precBitXor     = 7  // ^
-	precBitAnd     = 8  // &
-	precShift      = 9  // <<, >>
-	precAdditive   = 10 // +, -, ||
-	precMultiply   = 11 // *, /, %
-	precUnary      = 12 // -, ~, !
-	precCollate    = 13 // COLLATE
-	precHighest    = 14
+	precLowest     = token.PrecLowest
+	precOr         = token.PrecOr
+	precXor        = token.PrecXor
+	precAnd        = token.PrecAnd
+	precNot        = token.PrecNot
+	precComparison = token.PrecComparison
+	precJSON       = token.PrecJSON
+	precBitOr      = token.PrecBitOr
+	precBitXor     = token.PrecBitXor
+	precBitAnd     = token.PrecBitAnd
+	precShift      = token.PrecShift
+	precAdditive   = token.PrecAdditive
+	precMultiply   = token.PrecMultiply
+	precUnary      = token.PrecUnary
+	precCollate    = token.PrecCollate
+	precHighest    = token.PrecHighest
 )
 
 // precedence returns the precedence of a binary operator.
 func precedence(t token.Token) int {
-	switch t {
-	case token.OR:
-		return precOr
-	case token.XOR:
-		return precXor
-	case token.AND:
-		return precAnd
-	case token.EQ, token.NEQ, token.LT, token.GT, token.LTE, token.GTE:
-		return precComparison
-	case token.BITOR:
-		return precBitOr
-	case token.BITXOR:
-		return precBitXor
-	case token.BITAND:
-		return precBitAnd
-	case token.LSHIFT, token.RSHIFT:
-		return precShift
-	case token.PLUS, token.MINUS, token.CONCAT:
-		return precAdditive
-	case token.ASTERISK, token.SLASH, token.PERCENT:
-		return precMultiply
-	default:
-		return precLowest
-	}
+	return token.Precedence(t)
 }
 
 // parseExpr parses an expression using precedence climbing.
@@ -72,6 +50,11 @@ func (p *Parser) parseExpr() ast.Expr {
 
 // parseExprPrec implements precedence climbing.
 func (p *Parser) parseExprPrec(minPrec int) ast.Expr {
+	if !p.enter() {
+		return nil
+	}
+	defer p.leave()
+
 	left := p.parsePrimaryExpr()
 	if left == nil {
 		return nil
@@ -79,9 +62,19 @@ func (p *Parser) parseExprPrec(minPrec int) ast.Expr {
 
 	for {
 		op := p.cur.Type
+		if op == token.PARAM && p.cur.Value == "?" {
+			// The lexer can't tell a placeholder from the JSONB/hstore
+			// existence operator, but a placeholder never follows an
+			// operand: in doc ? 'key' it is the operator, in a = ? it was
+			// consumed as the operand of =.
+			op = token.QUESTION
+		}
 
-		// Handle special cases that aren't simple binary ops
-		if p.curIs(token.IS) {
+		// Handle special cases that aren't simple binary ops. The
+		// predicates bind at comparison precedence, so in a + b IS NULL
+		// the operand of + stops at b and the predicate applies to a + b.
+		predicate := minPrec <= precComparison
+		if p.curIs(token.IS) && predicate {
 			if isNilExpr(left) {
 				return nil
 			}
@@ -91,7 +84,7 @@ func (p *Parser) parseExprPrec(minPrec int) ast.Expr {
 			}
 			continue
 		}
-		if p.curIs(token.IN) {
+		if p.curIs(token.IN) && predicate {
 			if isNilExpr(left) {
 				return nil
 			}
@@ -101,7 +94,7 @@ func (p *Parser) parseExprPrec(minPrec int) ast.Expr {
 			}
 			continue
 		}
-		if p.curIs(token.NOT) {
+		if p.curIs(token.NOT) && predicate {
 			next := p.peek()
 			switch next.Type {
 			case token.IN:
@@ -146,7 +139,7 @@ func (p *Parser) parseExprPrec(minPrec int) ast.Expr {
 				continue
 			}
 		}
-		if p.curIs(token.BETWEEN) {
+		if p.curIs(token.BETWEEN) && predicate {
 			if isNilExpr(left) {
 				return nil
 			}
@@ -156,7 +149,7 @@ func (p *Parser) parseExprPrec(minPrec int) ast.Expr {
 			}
 			continue
 		}
-		if p.curIs(token.LIKE) || p.curIs(token.ILIKE) {
+		if (p.curIs(token.LIKE) || p.curIs(token.ILIKE)) && predicate {
 			if isNilExpr(left) {
 				return nil
 			}
@@ -166,7 +159,17 @@ func (p *Parser) parseExprPrec(minPrec int) ast.Expr {
 			}
 			continue
 		}
-		if p.curIs(token.SIMILAR) {
+		if predicate && p.curIsPeriodPredicate() {
+			if isNilExpr(left) {
+				return nil
+			}
+			left = p.parsePeriodExpr(left)
+			if isNilExpr(left) {
+				return nil
+			}
+			continue
+		}
+		if p.curIs(token.SIMILAR) && predicate {
 			if isNilExpr(left) {
 				return nil
 			}
@@ -197,6 +200,15 @@ func (p *Parser) parseExprPrec(minPrec int) ast.Expr {
 			}
 			continue
 		}
+		if p.curIs(token.BANG) && minPrec <= precUnary {
+			// Postfix factorial: expr!
+			if isNilExpr(left) {
+				return nil
+			}
+			left = &ast.PostfixExpr{StartPos: left.Pos(), EndPos: p.cur.Pos, Op: token.BANG, Operand: left}
+			p.advance()
+			continue
+		}
 		if p.curIs(token.LBRACKET) {
 			// Array subscript
 			if isNilExpr(left) {
@@ -209,7 +221,10 @@ func (p *Parser) parseExprPrec(minPrec int) ast.Expr {
 			continue
 		}
 
-		// Standard binary operators
+		// Standard binary operators. The right operand is parsed one level
+		// tighter, so a following operator of the same precedence returns
+		// here and folds into left: a AND b AND c builds a left-deep tree
+		// at constant nesting depth, however long the chain.
 		prec := precedence(op)
 		if prec < minPrec {
 			break
@@ -249,6 +264,8 @@ func (p *Parser) parsePrimaryExpr() ast.Expr {
 		return p.parseLiteral(ast.LiteralFloat)
 	case token.STRING:
 		return p.parseLiteral(ast.LiteralString)
+	case token.BLOB:
+		return p.parseLiteral(ast.LiteralBlob)
 	case token.NULL:
 		pos := p.cur.Pos
 		p.advance()
@@ -275,10 +292,20 @@ func (p *Parser) parsePrimaryExpr() ast.Expr {
 		return p.parseUnaryBitnot()
 	case token.EXISTS:
 		return p.parseExistsExpr()
+	case token.UNIQUE:
+		if p.peekIs(token.LPAREN) {
+			return p.parseUniqueExpr()
+		}
+		return p.parseIdentifierOrFunc()
 	case token.CASE:
 		return p.parseCaseExpr()
 	case token.CAST:
 		return p.parseCastExpr()
+	case token.CONVERT:
+		if p.peekIs(token.LPAREN) {
+			return p.parseConvertExpr()
+		}
+		return p.parseIdentifierOrFunc()
 	case token.INTERVAL:
 		return p.parseIntervalExpr()
 	case token.EXTRACT:
@@ -298,24 +325,78 @@ func (p *Parser) parsePrimaryExpr() ast.Expr {
 	case token.DEFAULT:
 		pos := p.cur.Pos
 		p.advance()
-		return &ast.Literal{StartPos: pos, EndPos: pos, Type: ast.LiteralNull, Value: "DEFAULT"}
+		return &ast.Literal{StartPos: pos, EndPos: pos, Type: ast.LiteralDefault, Value: "DEFAULT"}
+	case token.DATE, token.TIME, token.TIMESTAMP:
+		if p.peekIs(token.STRING) {
+			return p.parseTypedLiteral()
+		}
+		return p.parseIdentifierOrFunc()
 	default:
 		// Check if it's a keyword that could be a function name or column name
 		if p.cur.Type.IsKeyword() {
 			return p.parseIdentifierOrFunc()
 		}
+		p.wantAny(expressionStart...)
 		p.errorf("unexpected token %v in expression", p.cur.Type)
 		return nil
 	}
 }
 
+// expressionStart lists the tokens parsePrimaryExpr accepts, other than
+// keywords used as identifiers.
+var expressionStart = []token.Token{
+	token.INT, token.FLOAT, token.STRING, token.NULL, token.TRUE, token.FALSE,
+	token.IDENT, token.PARAM, token.LPAREN, token.NOT, token.MINUS,
+	token.BITNOT, token.EXISTS, token.UNIQUE, token.CASE, token.CAST, token.CONVERT, token.INTERVAL,
+	token.EXTRACT, token.TRIM, token.SUBSTRING, token.POSITION,
+	token.ASTERISK, token.ARRAY, token.DEFAULT,
+}
+
 func (p *Parser) parseLiteral(litType ast.LiteralType) *ast.Literal {
 	lit := ast.GetLiteral()
 	lit.StartPos = p.cur.Pos
 	lit.EndPos = p.cur.Pos
 	lit.Type = litType
 	lit.Value = p.cur.Value
+	if litType == ast.LiteralString {
+		lit.DollarTag, lit.DollarQuoted = p.lexer.DollarTag(p.cur)
+	}
 	p.advance()
+
+	// Adjacent string literals separated only by whitespace form a single
+	// literal, as in standard SQL: 'abc' 'def' is 'abcdef'.
+	if litType == ast.LiteralString && p.stringContinues() {
+		var b strings.Builder
+		b.WriteString(lit.Value)
+		for p.stringContinues() {
+			b.WriteString(p.cur.Value)
+			lit.EndPos = p.cur.Pos
+			p.advance()
+		}
+		lit.Value = b.String()
+		lit.DollarQuoted, lit.DollarTag = false, ""
+	}
+	return lit
+}
+
+// stringContinues reports whether cur is a string literal continuing the
+// one just consumed. With StringContinuationNewline set, it must start on
+// a later line.
+func (p *Parser) stringContinues() bool {
+	if !p.curIs(token.STRING) {
+		return false
+	}
+	return !p.StringContinuationNewline ||
+		strings.ContainsRune(p.lexer.Source(p.prevEnd, p.cur.Pos.Offset), '\n')
+}
+
+// parseTypedLiteral parses DATE, TIME or TIMESTAMP followed by a string.
+func (p *Parser) parseTypedLiteral() *ast.TypedLiteral {
+	lit := &ast.TypedLiteral{StartPos: p.cur.Pos, Type: strings.ToUpper(p.cur.Value)}
+	p.advance() // consume type keyword
+	lit.Value = p.cur.Value
+	lit.EndPos = p.cur.Pos
+	p.advance() // consume string
 	return lit
 }
 
@@ -340,16 +421,10 @@ func (p *Parser) parseIdentifierOrFunc() ast.Expr {
 		if p.curIs(token.ASTERISK) {
 			endPos = p.cur.Pos
 			p.advance()
-			// Join all parts except the star for the table name
-			tableName := parts[len(parts)-1]
-			if len(parts) > 1 {
-				tableName = parts[len(parts)-1]
-			}
 			return &ast.StarExpr{
-				StartPos:     pos,
-				EndPos:       endPos,
-				TableName:    tableName,
-				HasQualifier: true,
+				StartPos:       pos,
+				EndPos:         endPos,
+				QualifierParts: parts,
 			}
 		}
 
@@ -368,6 +443,11 @@ func (p *Parser) parseIdentifierOrFunc() ast.Expr {
 	col.StartPos = pos
 	col.EndPos = endPos
 	col.Parts = parts
+	if p.curIs(token.OUTERJOIN) {
+		col.OuterJoin = true
+		col.EndPos = p.cur.Pos
+		p.advance()
+	}
 	return col
 }
 
@@ -397,10 +477,23 @@ func (p *Parser) parseFuncCall(pos token.Pos, name string) *ast.FuncExpr {
 			p.advance()
 		} else {
 			for {
+				name := ""
+				if p.curIsIdent() && p.peekIs(token.FATARROW) {
+					// Named argument: name => value
+					name = p.curIdentValue()
+					p.advance()
+					p.advance()
+				}
 				arg := p.parseExpr()
 				if arg == nil {
 					break
 				}
+				if name != "" && fn.ArgNames == nil {
+					fn.ArgNames = make([]string, len(fn.Args), cap(fn.Args))
+				}
+				if fn.ArgNames != nil {
+					fn.ArgNames = append(fn.ArgNames, name)
+				}
 				fn.Args = append(fn.Args, arg)
 				if !p.curIs(token.COMMA) {
 					break
@@ -413,7 +506,34 @@ func (p *Parser) parseFuncCall(pos token.Pos, name string) *ast.FuncExpr {
 	if !p.expect(token.RPAREN) {
 		return nil
 	}
-	fn.EndPos = p.cur.Pos
+	fn.EndPos = p.prevPos
+
+	// Check for WITHIN GROUP (ORDER BY ...); which functions may take it
+	// is left to Validate.
+	if p.curIs(token.WITHIN) {
+		p.advance()
+		if !p.expect(token.GROUP) || !p.expect(token.LPAREN) {
+			return nil
+		}
+		if !p.curIs(token.ORDER) {
+			p.errorf("expected ORDER BY in WITHIN GROUP")
+			return nil
+		}
+		fn.WithinGroup = p.parseOrderBy()
+		if !p.expect(token.RPAREN) {
+			return nil
+		}
+		fn.EndPos = p.prevPos
+	}
+
+	// Null treatment of a window function: {IGNORE | RESPECT} NULLS
+	if (p.curIs(token.IGNORE) || p.curIs(token.RESPECT)) && p.peekIs(token.NULLS) {
+		fn.IgnoreNulls = p.curIs(token.IGNORE)
+		fn.RespectNulls = !fn.IgnoreNulls
+		p.advance()
+		fn.EndPos = p.cur.Pos
+		p.advance()
+	}
 
 	// Check for FILTER clause
 	if p.curIs(token.FILTER) {
@@ -448,7 +568,7 @@ func (p *Parser) parseWindowSpec() *ast.WindowSpec {
 	if p.curIs(token.IDENT) {
 		spec.Name = p.cur.Value
 		p.advance()
-		spec.EndPos = p.cur.Pos
+		spec.EndPos = p.prevPos
 		return spec
 	}
 
@@ -480,7 +600,7 @@ func (p *Parser) parseWindowSpec() *ast.WindowSpec {
 	}
 
 	p.expect(token.RPAREN)
-	spec.EndPos = p.cur.Pos
+	spec.EndPos = p.prevPos
 	return spec
 }
 
@@ -506,6 +626,34 @@ func (p *Parser) parseWindowFrame() *ast.WindowFrame {
 		frame.Start = p.parseFrameBound()
 	}
 
+	// EXCLUDE {CURRENT ROW | GROUP | TIES | NO OTHERS}; neither EXCLUDE nor
+	// OTHERS is a keyword, so both stay usable as names elsewhere.
+	if p.curIsWord("EXCLUDE") {
+		p.advance()
+		switch p.cur.Type {
+		case token.CURRENT:
+			p.advance()
+			p.expect(token.ROW)
+			frame.Exclude = ast.ExcludeCurrentRow
+		case token.GROUP:
+			p.advance()
+			frame.Exclude = ast.ExcludeGroup
+		case token.TIES:
+			p.advance()
+			frame.Exclude = ast.ExcludeTies
+		case token.NO:
+			p.advance()
+			if !p.curIsWord("OTHERS") {
+				p.errorf("expected OTHERS after EXCLUDE NO")
+				return frame
+			}
+			p.advance()
+			frame.Exclude = ast.ExcludeNoOthers
+		default:
+			p.errorf("expected CURRENT ROW, GROUP, TIES, or NO OTHERS after EXCLUDE")
+		}
+	}
+
 	return frame
 }
 
@@ -584,7 +732,7 @@ func (p *Parser) parseParenOrSubquery() ast.Expr {
 		if !p.expect(token.RPAREN) {
 			return nil
 		}
-		endPos := p.cur.Pos
+		endPos := p.prevPos
 		sel, ok := stmt.(*ast.SelectStmt)
 		if !ok {
 			p.errorf("expected SELECT statement in subquery")
@@ -595,10 +743,29 @@ func (p *Parser) parseParenOrSubquery() ast.Expr {
 
 	// Regular parenthesized expression
 	expr := p.parseExpr()
+
+	// Row constructor: (a, b, ...)
+	if p.curIs(token.COMMA) {
+		tuple := &ast.TupleExpr{StartPos: pos, Exprs: []ast.Expr{expr}}
+		for p.curIs(token.COMMA) {
+			p.advance()
+			e := p.parseExpr()
+			if e == nil {
+				return nil
+			}
+			tuple.Exprs = append(tuple.Exprs, e)
+		}
+		if !p.expect(token.RPAREN) {
+			return nil
+		}
+		tuple.EndPos = p.prevPos
+		return tuple
+	}
+
 	if !p.expect(token.RPAREN) {
 		return nil
 	}
-	endPos := p.cur.Pos
+	endPos := p.prevPos
 	return &ast.ParenExpr{StartPos: pos, EndPos: endPos, Expr: expr}
 }
 
@@ -645,6 +812,40 @@ func (p *Parser) parseExistsExpr() *ast.ExistsExpr {
 		p.advance()
 	}
 
+	sub := p.parsePredicateSubquery("EXISTS")
+	if sub == nil {
+		return nil
+	}
+
+	return &ast.ExistsExpr{
+		StartPos: pos,
+		EndPos:   p.prevPos,
+		Not:      not,
+		Subquery: sub,
+	}
+}
+
+// parseUniqueExpr parses the UNIQUE (subquery) predicate.
+func (p *Parser) parseUniqueExpr() *ast.UniqueExpr {
+	pos := p.cur.Pos
+	p.advance() // consume UNIQUE
+
+	sub := p.parsePredicateSubquery("UNIQUE")
+	if sub == nil {
+		return nil
+	}
+
+	return &ast.UniqueExpr{
+		StartPos: pos,
+		EndPos:   p.prevPos,
+		Subquery: sub,
+	}
+}
+
+// parsePredicateSubquery parses the parenthesized query of a subquery
+// predicate such as EXISTS or UNIQUE.
+func (p *Parser) parsePredicateSubquery(kw string) *ast.Subquery {
+	pos := p.cur.Pos
 	if !p.expect(token.LPAREN) {
 		return nil
 	}
@@ -660,20 +861,14 @@ func (p *Parser) parseExistsExpr() *ast.ExistsExpr {
 	}
 
 	if sel == nil {
-		p.errorf("expected SELECT in EXISTS subquery")
+		p.errorf("expected SELECT in %s subquery", kw)
 		return nil
 	}
 
 	if !p.expect(token.RPAREN) {
 		return nil
 	}
-
-	return &ast.ExistsExpr{
-		StartPos: pos,
-		EndPos:   p.cur.Pos,
-		Not:      not,
-		Subquery: &ast.Subquery{Select: sel},
-	}
+	return &ast.Subquery{StartPos: pos, EndPos: p.prevPos, Select: sel}
 }
 
 func (p *Parser) parseCaseExpr() *ast.CaseExpr {
@@ -711,7 +906,7 @@ func (p *Parser) parseCaseExpr() *ast.CaseExpr {
 		return nil
 	}
 
-	caseExpr.EndPos = p.cur.Pos
+	caseExpr.EndPos = p.prevPos
 	return caseExpr
 }
 
@@ -737,21 +932,134 @@ func (p *Parser) parseCastExpr() *ast.CastExpr {
 
 	return &ast.CastExpr{
 		StartPos: pos,
-		EndPos:   p.cur.Pos,
+		EndPos:   p.prevPos,
 		Expr:     expr,
 		Type:     dataType,
 	}
 }
 
+// parseConvertExpr parses CONVERT in the forms ConvertExpr models:
+//
+//	CONVERT(expr USING charset)     MySQL
+//	CONVERT(expr, type)             MySQL
+//	CONVERT(type, expr [, style])   SQL Server
+//
+// Anything else, such as PostgreSQL's convert(bytes, src, dest), is kept
+// as an ordinary function call.
+func (p *Parser) parseConvertExpr() ast.Expr {
+	pos := p.cur.Pos
+	p.advance() // consume CONVERT
+	p.advance() // consume (
+
+	expr := &ast.ConvertExpr{StartPos: pos}
+	if isTypeKeyword(p.cur.Type) {
+		expr.Type = p.parseDataType()
+		expr.TypeFirst = true
+	} else {
+		first := p.parseExpr()
+		switch {
+		case p.curIs(token.USING):
+			p.advance()
+			if !p.curIsIdent() {
+				p.errorf("expected character set after USING")
+				return nil
+			}
+			expr.Expr = first
+			expr.Charset = p.cur.Value
+			p.advance()
+		case p.curIs(token.COMMA) && isTypeKeyword(p.peek().Type):
+			p.advance()
+			expr.Expr = first
+			expr.Type = p.parseDataType()
+		case p.curIs(token.COMMA) && convertDataType(first) != nil:
+			// A type that is not a keyword, such as NVARCHAR(50), parses
+			// as a name or a call.
+			expr.Type = convertDataType(first)
+			expr.TypeFirst = true
+		default:
+			fn := &ast.FuncExpr{StartPos: pos, Name: "CONVERT", Args: []ast.Expr{first}}
+			for p.curIs(token.COMMA) {
+				p.advance()
+				fn.Args = append(fn.Args, p.parseExpr())
+			}
+			if !p.expect(token.RPAREN) {
+				return nil
+			}
+			fn.EndPos = p.prevPos
+			return fn
+		}
+	}
+
+	if expr.TypeFirst {
+		if !p.expect(token.COMMA) {
+			return nil
+		}
+		expr.Expr = p.parseExpr()
+		if p.curIs(token.COMMA) {
+			p.advance()
+			expr.Style = p.parseExpr()
+		}
+	}
+
+	if !p.expect(token.RPAREN) {
+		return nil
+	}
+
+	expr.EndPos = p.prevPos
+	return expr
+}
+
+// isTypeKeyword reports whether t is a keyword that names a data type.
+func isTypeKeyword(t token.Token) bool {
+	return t >= token.INT_TYPE && t <= token.SMALLSERIAL || t == token.SIGNED || t == token.UNSIGNED
+}
+
+// convertDataType reinterprets the first argument of a SQL Server CONVERT
+// as a data type, or returns nil if it cannot be one: a plain or
+// schema-qualified name, or a call with one or two integer arguments for
+// the length or precision and scale.
+func convertDataType(e ast.Expr) *ast.DataType {
+	switch n := e.(type) {
+	case *ast.ColName:
+		switch len(n.Parts) {
+		case 1:
+			return &ast.DataType{Name: n.Parts[0]}
+		case 2:
+			return &ast.DataType{Schema: n.Parts[0], Name: n.Parts[1]}
+		}
+	case *ast.FuncExpr:
+		if n.Distinct || len(n.Args) == 0 || len(n.Args) > 2 || n.ArgNames != nil ||
+			n.OrderBy != nil || n.WithinGroup != nil || n.Filter != nil || n.Over != nil {
+			return nil
+		}
+		var sizes []int
+		for _, arg := range n.Args {
+			lit, ok := arg.(*ast.Literal)
+			if !ok || lit.Type != ast.LiteralInt {
+				return nil
+			}
+			sizes = append(sizes, parseInt(lit.Value))
+		}
+		dt := &ast.DataType{Name: n.Name, Length: &sizes[0]}
+		if len(sizes) == 2 {
+			dt.Precision = dt.Length
+			dt.Scale = &sizes[1]
+		}
+		return dt
+	}
+	return nil
+}
+
 func (p *Parser) parsePostgresCast(left ast.Expr) *ast.CastExpr {
 	p.advance() // consume ::
 	dataType := p.parseDataType()
 
 	return &ast.CastExpr{
 		StartPos: left.Pos(),
-		EndPos:   p.cur.Pos,
+		EndPos:   p.prevPos,
 		Expr:     left,
 		Type:     dataType,
+		Postgres: true,
 	}
 }
 
@@ -762,13 +1070,23 @@ func (p *Parser) parseIntervalExpr() *ast.IntervalExpr {
 	expr := &ast.IntervalExpr{StartPos: pos}
 	expr.Value = p.parseExpr()
 
-	// Parse unit (YEAR, MONTH, DAY, etc.)
-	if p.cur.Type.IsKeyword() {
-		expr.Unit = p.cur.Value
+	// Parse unit (YEAR, DAY_HOUR, DAY TO SECOND, etc.). Other words are
+	// left alone: they may be an alias or the next clause.
+	if p.curIsIdent() && ast.IsIntervalUnit(p.cur.Value) {
+		expr.Unit = strings.ToUpper(p.cur.Value)
 		p.advance()
+		if p.curIs(token.TO) {
+			p.advance()
+			if !p.curIsIdent() || !ast.IsIntervalUnit(expr.Unit+" TO "+p.cur.Value) {
+				p.errorf("invalid interval unit %s TO %s", expr.Unit, p.cur.Value)
+				return nil
+			}
+			expr.Unit += " TO " + strings.ToUpper(p.cur.Value)
+			p.advance()
+		}
 	}
 
-	expr.EndPos = p.cur.Pos
+	expr.EndPos = p.prevPos
 	return expr
 }
 
@@ -798,7 +1116,7 @@ func (p *Parser) parseExtractExpr() *ast.ExtractExpr {
 		return nil
 	}
 
-	expr.EndPos = p.cur.Pos
+	expr.EndPos = p.prevPos
 	return expr
 }
 
@@ -840,7 +1158,7 @@ func (p *Parser) parseTrimExpr() *ast.TrimExpr {
 		return nil
 	}
 
-	expr.EndPos = p.cur.Pos
+	expr.EndPos = p.prevPos
 	return expr
 }
 
@@ -875,7 +1193,7 @@ func (p *Parser) parseSubstringExpr() *ast.SubstringExpr {
 		return nil
 	}
 
-	expr.EndPos = p.cur.Pos
+	expr.EndPos = p.prevPos
 	return expr
 }
 
@@ -900,7 +1218,7 @@ func (p *Parser) parsePositionExpr() *ast.PositionExpr {
 		return nil
 	}
 
-	expr.EndPos = p.cur.Pos
+	expr.EndPos = p.prevPos
 	return expr
 }
 
@@ -932,7 +1250,7 @@ func (p *Parser) parseArrayExpr() *ast.ArrayExpr {
 		return nil
 	}
 
-	expr.EndPos = p.cur.Pos
+	expr.EndPos = p.prevPos
 	return expr
 }
 
@@ -955,7 +1273,7 @@ func (p *Parser) parseSubscript(left ast.Expr) *ast.SubscriptExpr {
 		return nil
 	}
 
-	expr.EndPos = p.cur.Pos
+	expr.EndPos = p.prevPos
 	return expr
 }
 
@@ -989,7 +1307,7 @@ func (p *Parser) parseIsExpr(left ast.Expr) *ast.IsExpr {
 	}
 
 	p.advance()
-	expr.EndPos = p.cur.Pos
+	expr.EndPos = p.prevPos
 	return expr
 }
 
@@ -1025,7 +1343,12 @@ func (p *Parser) parseInExpr(left ast.Expr, not bool) *ast.InExpr {
 		}
 		expr.Select = sel
 	} else {
-		// Value list
+		// Value list; IN () is rejected rather than formatted back as
+		// SQL most engines refuse.
+		if p.curIs(token.RPAREN) {
+			p.errorf("empty IN list")
+			return nil
+		}
 		for {
 			val := p.parseExpr()
 			if val == nil {
@@ -1043,7 +1366,7 @@ func (p *Parser) parseInExpr(left ast.Expr, not bool) *ast.InExpr {
 		return nil
 	}
 
-	expr.EndPos = p.cur.Pos
+	expr.EndPos = p.prevPos
 	return expr
 }
 
@@ -1070,7 +1393,7 @@ func (p *Parser) parseBetweenExpr(left ast.Expr, not bool) *ast.BetweenExpr {
 
 	expr.High = p.parseExprPrec(precComparison + 1)
 
-	expr.EndPos = p.cur.Pos
+	expr.EndPos = p.prevPos
 	return expr
 }
 
@@ -1086,17 +1409,59 @@ func (p *Parser) parseLikeExpr(left ast.Expr, not bool) *ast.LikeExpr {
 		ILike:    ilike,
 	}
 
-	expr.Pattern = p.parseExprPrec(precComparison + 1)
+	if (p.curIs(token.ANY) || p.curIs(token.SOME) || p.curIs(token.ALL)) && p.peekIs(token.LPAREN) {
+		if !p.parseLikeQuantifier(expr) {
+			return nil
+		}
+	} else {
+		expr.Pattern = p.parseExprPrec(precComparison + 1)
+	}
 
 	if p.curIs(token.ESCAPE) {
 		p.advance()
 		expr.Escape = p.parseExprPrec(precComparison + 1)
 	}
 
-	expr.EndPos = p.cur.Pos
+	expr.EndPos = p.prevPos
 	return expr
 }
 
+// parseLikeQuantifier parses the ANY, SOME or ALL operand of a quantified
+// LIKE: a subquery, an array expression, or a list of patterns.
+func (p *Parser) parseLikeQuantifier(expr *ast.LikeExpr) bool {
+	expr.Quantifier = strings.ToUpper(p.cur.Value)
+	p.advance() // consume quantifier
+
+	if p.peekIs(token.SELECT) || p.peekIs(token.WITH) {
+		expr.Pattern = p.parsePredicateSubquery(expr.Quantifier)
+		return expr.Pattern != nil
+	}
+
+	p.advance() // consume (
+	var patterns []ast.Expr
+	for {
+		pattern := p.parseExpr()
+		if pattern == nil {
+			return false
+		}
+		patterns = append(patterns, pattern)
+		if !p.curIs(token.COMMA) {
+			break
+		}
+		p.advance()
+	}
+	if !p.expect(token.RPAREN) {
+		return false
+	}
+
+	if len(patterns) == 1 {
+		expr.Pattern = patterns[0]
+	} else {
+		expr.Patterns = patterns
+	}
+	return true
+}
+
 func (p *Parser) parseSimilarExpr(left ast.Expr, not bool) *ast.LikeExpr {
 	pos := left.Pos()
 	p.advance() // consume SIMILAR
@@ -1115,7 +1480,60 @@ func (p *Parser) parseSimilarExpr(left ast.Expr, not bool) *ast.LikeExpr {
 		expr.Escape = p.parseExprPrec(precComparison + 1)
 	}
 
-	expr.EndPos = p.cur.Pos
+	expr.EndPos = p.prevPos
+	return expr
+}
+
+// curIsPeriodPredicate reports whether the current word introduces a
+// period predicate. The predicate words are not keywords, so they remain
+// usable as names: one followed by a comma, a clause keyword or the end
+// of the input is an alias, as in SELECT a contains FROM t.
+func (p *Parser) curIsPeriodPredicate() bool {
+	if !p.curIs(token.IDENT) {
+		return false
+	}
+	next := p.peek()
+	switch strings.ToUpper(p.cur.Value) {
+	case "OVERLAPS", "CONTAINS", "PRECEDES", "SUCCEEDS":
+		switch next.Type {
+		case token.EOF, token.COMMA, token.RPAREN, token.SEMICOLON:
+			return false
+		}
+		return !isClauseKeyword(next.Type)
+	case "IMMEDIATELY":
+		return next.Type == token.IDENT &&
+			(strings.EqualFold(next.Value, "PRECEDES") || strings.EqualFold(next.Value, "SUCCEEDS"))
+	}
+	return false
+}
+
+// parsePeriodExpr parses a period predicate such as OVERLAPS after its
+// left operand.
+func (p *Parser) parsePeriodExpr(left ast.Expr) *ast.PeriodExpr {
+	expr := &ast.PeriodExpr{StartPos: left.Pos(), Left: left}
+	switch strings.ToUpper(p.cur.Value) {
+	case "OVERLAPS":
+		expr.Op = ast.PeriodOverlaps
+	case "CONTAINS":
+		expr.Op = ast.PeriodContains
+	case "PRECEDES":
+		expr.Op = ast.PeriodPrecedes
+	case "SUCCEEDS":
+		expr.Op = ast.PeriodSucceeds
+	case "IMMEDIATELY":
+		p.advance()
+		expr.Op = ast.PeriodImmediatelyPrecedes
+		if p.curIsWord("SUCCEEDS") {
+			expr.Op = ast.PeriodImmediatelySucceeds
+		}
+	}
+	p.advance() // consume predicate word
+
+	expr.Right = p.parseExprPrec(precComparison + 1)
+	if expr.Right == nil {
+		return nil
+	}
+	expr.EndPos = p.prevPos
 	return expr
 }
 
@@ -1132,7 +1550,7 @@ func (p *Parser) parseCollateExpr(left ast.Expr) *ast.CollateExpr {
 		p.advance()
 	}
 
-	expr.EndPos = p.cur.Pos
+	expr.EndPos = p.prevPos
 	return expr
 }
 
@@ -1157,10 +1575,11 @@ func (p *Parser) parseExprList() []ast.Expr {
 func isBinaryOp(t token.Token) bool {
 	switch t {
 	case token.PLUS, token.MINUS, token.ASTERISK, token.SLASH, token.PERCENT,
-		token.EQ, token.NEQ, token.LT, token.GT, token.LTE, token.GTE,
+		token.EQ, token.NEQ, token.LT, token.GT, token.LTE, token.GTE, token.ATAT,
 		token.AND, token.OR, token.XOR,
 		token.BITAND, token.BITOR, token.BITXOR, token.LSHIFT, token.RSHIFT,
-		token.CONCAT:
+		token.CONCAT, token.ARROW, token.DARROW, token.HASHGT, token.HASHDGT,
+		token.QUESTION, token.QUESTIONOR, token.QUESTIONAND:
 		return true
 	default:
 		return false
@@ -2,8 +2,11 @@
 package parser
 
 import (
+	"context"
 	"fmt"
+	"slices"
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/freeeve/machparse/ast"
@@ -16,22 +19,125 @@ type Parser struct {
 	lexer  *lexer.Lexer
 	errors []ParseError
 	cur    token.Item // current token
+
+	// prevPos and prevEnd are the position of, and the offset just past,
+	// the last token consumed before cur, ignoring comments. Nodes end at
+	// prevPos.
+	prevPos token.Pos
+	prevEnd int
+
+	// expected holds the tokens recorded by want at offset expectedAt.
+	expected   []token.Token
+	expectedAt int
+
+	// MaxDepth limits how deeply statements, expressions and table
+	// expressions may nest, so adversarial input such as thousands of
+	// nested parentheses fails with a DepthLimitExceededError instead of
+	// exhausting the stack. New and Get set it to DefaultMaxDepth; zero
+	// or less disables the limit.
+	MaxDepth int
+	depth    int
+
+	// Strict rejects input the parser otherwise tolerates, for callers
+	// validating user-submitted SQL: ParseAll requires a semicolon between
+	// statements, and EXPLAIN options and values and set operations, which
+	// the AST does not model, are rejected rather than dropped. New and Get
+	// leave it off.
+	Strict bool
+
+	// StringContinuationNewline makes adjacent string literals merge only
+	// when the whitespace between them includes a newline, as PostgreSQL
+	// requires; 'a' 'b' on one line is then an error. By default any
+	// whitespace joins them. New and Get leave it off.
+	StringContinuationNewline bool
+
+	// ctx, when set by ParseContext or ParseAllContext, is polled every
+	// ctxCheckInterval tokens; ctxErr holds its error once cancelled.
+	ctx    context.Context
+	tokens int
+	ctxErr error
 }
 
+// ctxCheckInterval is how many tokens the parser consumes between checks
+// of its context.
+const ctxCheckInterval = 256
+
+// DefaultMaxDepth is the nesting limit new parsers start with.
+const DefaultMaxDepth = 10000
+
 // ParseError represents a parse error with position.
+//
+// Err holds the error category: one of *UnexpectedTokenError,
+// *UnterminatedStringError, *UnsupportedStatementError or
+// *DepthLimitExceededError. Use errors.As to inspect it.
+//
+// Expected lists tokens that would have been valid at Pos, when the error
+// occurred at a statement start, an expression or a clause boundary. It
+// is meant for completion hints and is not exhaustive.
 type ParseError struct {
-	Pos     token.Pos
-	Message string
+	Pos      token.Pos
+	Message  string
+	Err      error
+	Expected []token.Token
 }
 
 func (e ParseError) Error() string {
 	return fmt.Sprintf("line %d, column %d: %s", e.Pos.Line, e.Pos.Column, e.Message)
 }
 
+// Unwrap returns the error category.
+func (e ParseError) Unwrap() error {
+	return e.Err
+}
+
+// UnexpectedTokenError reports a token the grammar does not allow here.
+type UnexpectedTokenError struct {
+	Expected token.Token // the required token, or ILLEGAL if several were allowed
+	Got      token.Token
+	Value    string // text of the unexpected token
+}
+
+func (e *UnexpectedTokenError) Error() string {
+	if e.Expected != token.ILLEGAL {
+		return fmt.Sprintf("expected %v, got %v", e.Expected, e.Got)
+	}
+	return fmt.Sprintf("unexpected token %v", e.Got)
+}
+
+// UnterminatedStringError reports a string literal or quoted identifier
+// that runs to the end of the input.
+type UnterminatedStringError struct {
+	Value string // the unterminated text, including the opening quote
+}
+
+func (e *UnterminatedStringError) Error() string {
+	return "unterminated quoted string"
+}
+
+// UnsupportedStatementError reports a statement the parser does not handle.
+type UnsupportedStatementError struct {
+	Token token.Token // the statement keyword, e.g. CREATE for CREATE VIEW
+	Value string      // text of the token that was not recognized
+}
+
+func (e *UnsupportedStatementError) Error() string {
+	return fmt.Sprintf("unsupported statement at %q", e.Value)
+}
+
+// DepthLimitExceededError reports input nested deeper than the parser allows.
+type DepthLimitExceededError struct {
+	Limit int
+}
+
+func (e *DepthLimitExceededError) Error() string {
+	return fmt.Sprintf("nesting depth exceeds %d", e.Limit)
+}
+
 // New creates a new parser for the given input.
 func New(input string) *Parser {
 	p := &Parser{
-		lexer: lexer.New(input),
+		lexer:    lexer.New(input),
+		MaxDepth: DefaultMaxDepth,
 	}
 	p.advance() // Prime the first token
 	return p
@@ -47,17 +153,65 @@ func Get(input string) *Parser {
 	p := parserPool.Get().(*Parser)
 	p.lexer = lexer.Get(input)
 	p.errors = p.errors[:0]
+	p.expected = p.expected[:0]
+	p.MaxDepth = DefaultMaxDepth
+	p.depth = 0
+	p.Strict = false
+	p.StringContinuationNewline = false
+	p.ctx, p.tokens, p.ctxErr = nil, 0, nil
 	p.cur = token.Item{}
 	p.advance()
 	return p
 }
 
+// SetKeywords makes the parser recognize additional keywords, as described
+// for lexer.Lexer.SetKeywords. Call it before parsing: the input is
+// scanned again from the start.
+func (p *Parser) SetKeywords(keywords map[string]token.Token) {
+	p.lexer.SetKeywords(keywords)
+	p.cur = token.Item{}
+	p.advance()
+}
+
+// SetStandardConformingStrings sets whether backslashes in string
+// literals are literal characters (the default) or start C-style escapes,
+// as described for lexer.Lexer.SetStandardConformingStrings. Turn it off
+// for MySQL input. Call it before parsing: the input is scanned again from
+// the start.
+func (p *Parser) SetStandardConformingStrings(on bool) {
+	p.lexer.SetStandardConformingStrings(on)
+	p.cur = token.Item{}
+	p.advance()
+}
+
+// SetDoubleQuoteIsString sets whether double-quoted text is a string
+// literal, as in MySQL without ANSI_QUOTES, rather than a quoted
+// identifier, the default. Call it before parsing: the input is scanned
+// again from the start.
+func (p *Parser) SetDoubleQuoteIsString(on bool) {
+	p.lexer.SetDoubleQuoteIsString(on)
+	p.cur = token.Item{}
+	p.advance()
+}
+
+// SetReservedWords reserves words for a dialect extension. Each word lexes
+// as token.RESERVED rather than as an identifier, so it is no longer taken
+// as an implicit alias, while still being accepted as a type name.
+func (p *Parser) SetReservedWords(words ...string) {
+	keywords := make(map[string]token.Token, len(words))
+	for _, word := range words {
+		keywords[word] = token.RESERVED
+	}
+	p.SetKeywords(keywords)
+}
+
 // Put returns the parser and its lexer to the pool.
 func Put(p *Parser) {
 	if p.lexer != nil {
 		lexer.Put(p.lexer)
 		p.lexer = nil
 	}
+	p.ctx = nil
 	parserPool.Put(p)
 }
 
@@ -78,23 +232,100 @@ func (p *Parser) Parse() (ast.Statement, error) {
 		p.skipComments()
 	}
 	if !p.curIs(token.EOF) {
-		p.errorf("unexpected token %v after statement", p.cur.Type)
+		p.reportf(nil, "unexpected token %v after statement", p.cur.Type)
 		return nil, p.errors[0]
 	}
 	return stmt, nil
 }
 
+// ParseContext is like Parse but stops early and returns ctx.Err() if ctx
+// is cancelled while parsing. The check is cooperative: the parser polls
+// ctx every few hundred tokens.
+func (p *Parser) ParseContext(ctx context.Context) (ast.Statement, error) {
+	if err := p.startContext(ctx); err != nil {
+		return nil, err
+	}
+	stmt, err := p.Parse()
+	if p.ctxErr != nil {
+		return nil, p.ctxErr
+	}
+	return stmt, err
+}
+
+// ParseAllContext is like ParseAll but stops early and returns ctx.Err()
+// if ctx is cancelled while parsing.
+func (p *Parser) ParseAllContext(ctx context.Context) ([]ast.Statement, error) {
+	if err := p.startContext(ctx); err != nil {
+		return nil, err
+	}
+	stmts, err := p.ParseAll()
+	if p.ctxErr != nil {
+		return nil, p.ctxErr
+	}
+	return stmts, err
+}
+
+// startContext installs ctx for polling, returning its error if it is
+// already done.
+func (p *Parser) startContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	p.ctx = ctx
+	return nil
+}
+
 // ParseAll parses all statements until EOF.
 func (p *Parser) ParseAll() ([]ast.Statement, error) {
 	var stmts []ast.Statement
+	err := p.parseAll(func(stmt ast.Statement, _, _ int) {
+		stmts = append(stmts, stmt)
+	})
+	return stmts, err
+}
+
+// StatementSpan is a statement and the byte range [Start, End) it
+// occupies in the input. The range runs from the statement's first token
+// through its last, or through the semicolon that ends it; comments and
+// whitespace outside that are not part of it.
+type StatementSpan struct {
+	Stmt  ast.Statement
+	Start int
+	End   int
+}
+
+// ParseAllWithSpans is like ParseAll but pairs each statement with the
+// range of the input it was parsed from, for mapping statements back to
+// the source.
+func (p *Parser) ParseAllWithSpans() ([]StatementSpan, error) {
+	var spans []StatementSpan
+	err := p.parseAll(func(stmt ast.Statement, start, end int) {
+		spans = append(spans, StatementSpan{Stmt: stmt, Start: start, End: end})
+	})
+	return spans, err
+}
+
+// parseAll parses statements until EOF, passing each one to add with its
+// span in the input.
+func (p *Parser) parseAll(add func(stmt ast.Statement, start, end int)) error {
 	for !p.curIs(token.EOF) {
 		p.skipComments()
 		if p.curIs(token.EOF) {
 			break
 		}
+		start := p.cur.Pos.Offset
 		stmt := p.parseStatement()
+		end := p.prevEnd
+		p.skipComments()
+		if p.curIs(token.SEMICOLON) {
+			end = p.cur.Pos.Offset + 1
+		}
 		if stmt != nil {
-			stmts = append(stmts, stmt)
+			add(stmt, start, end)
+		}
+		if p.Strict && len(p.errors) == 0 && !p.curIs(token.SEMICOLON) && !p.curIs(token.EOF) {
+			p.reportf(nil, "unexpected token %v after statement", p.cur.Type)
+			break
 		}
 		// Skip optional semicolons between statements
 		for p.curIs(token.SEMICOLON) {
@@ -103,15 +334,29 @@ func (p *Parser) ParseAll() ([]ast.Statement, error) {
 		p.skipComments()
 	}
 	if len(p.errors) > 0 {
-		return stmts, p.errors[0]
+		return p.errors[0]
 	}
-	return stmts, nil
+	return nil
 }
 
 // Token navigation methods
 
 func (p *Parser) advance() {
+	if !p.curIs(token.COMMENT) {
+		p.prevPos, p.prevEnd = p.cur.Pos, p.lexer.End()
+	}
 	p.cur = p.lexer.Next()
+	if p.ctx == nil {
+		return
+	}
+	p.tokens++
+	if p.ctxErr == nil && p.tokens%ctxCheckInterval == 0 {
+		p.ctxErr = p.ctx.Err()
+	}
+	if p.ctxErr != nil {
+		// Pretend the input ended so every production unwinds quickly.
+		p.cur = token.Item{Type: token.EOF, Pos: p.cur.Pos}
+	}
 }
 
 func (p *Parser) curIs(t token.Token) bool {
@@ -153,7 +398,9 @@ func (p *Parser) expect(t token.Token) bool {
 		p.advance()
 		return true
 	}
-	p.errorf("expected %v, got %v", t, p.cur.Type)
+	p.wantAny(t)
+	p.reportf(&UnexpectedTokenError{Expected: t, Got: p.cur.Type, Value: p.cur.Value},
+		"expected %v, got %v", t, p.cur.Type)
 	return false
 }
 
@@ -163,15 +410,106 @@ func (p *Parser) skipComments() {
 	}
 }
 
+// errorf records a syntax error at the current token.
 func (p *Parser) errorf(format string, args ...interface{}) {
+	p.reportf(nil, format, args...)
+}
+
+// reportf records an error of the given category at the current token.
+// A nil err means the current token was unexpected; an unterminated
+// quote from the lexer takes precedence over either.
+func (p *Parser) reportf(err error, format string, args ...interface{}) {
+	if p.cur.Type == token.ILLEGAL && isUnterminatedQuote(p.cur.Value) {
+		err = &UnterminatedStringError{Value: p.cur.Value}
+	} else if err == nil {
+		err = &UnexpectedTokenError{Got: p.cur.Type, Value: p.cur.Value}
+	}
 	p.errors = append(p.errors, ParseError{
-		Pos:     p.cur.Pos,
-		Message: fmt.Sprintf(format, args...),
+		Pos:      p.cur.Pos,
+		Message:  fmt.Sprintf(format, args...),
+		Err:      err,
+		Expected: p.Expected(),
 	})
 }
 
+// enter increments the nesting depth on entry to a recursive production.
+// It reports false, after recording a DepthLimitExceededError, if the
+// depth exceeds MaxDepth; otherwise the caller must call leave on exit.
+func (p *Parser) enter() bool {
+	if p.MaxDepth > 0 && p.depth >= p.MaxDepth {
+		p.reportf(&DepthLimitExceededError{Limit: p.MaxDepth}, "nesting depth exceeds %d", p.MaxDepth)
+		return false
+	}
+	p.depth++
+	return true
+}
+
+// leave undoes a successful enter.
+func (p *Parser) leave() {
+	p.depth--
+}
+
+// want reports whether the current token is t, recording t as a valid
+// continuation at this position for error reporting and Expected.
+func (p *Parser) want(t token.Token) bool {
+	p.wantAny(t)
+	return p.cur.Type == t
+}
+
+// wantAny records ts as valid continuations at the current position.
+func (p *Parser) wantAny(ts ...token.Token) {
+	if p.expectedAt != p.cur.Pos.Offset {
+		p.expected = p.expected[:0]
+		p.expectedAt = p.cur.Pos.Offset
+	}
+	for _, t := range ts {
+		if !slices.Contains(p.expected, t) {
+			p.expected = append(p.expected, t)
+		}
+	}
+}
+
+// Expected returns the tokens recorded as valid at the current position.
+// After a successful Parse of incomplete input such as "SELECT a", these
+// are the keywords and punctuation that could follow it.
+func (p *Parser) Expected() []token.Token {
+	if p.expectedAt != p.cur.Pos.Offset || len(p.expected) == 0 {
+		return nil
+	}
+	return slices.Clone(p.expected)
+}
+
+// statementStart lists the tokens that can begin a statement.
+var statementStart = []token.Token{
+	token.SELECT, token.INSERT, token.REPLACE, token.UPDATE, token.DELETE,
+	token.CREATE, token.ALTER, token.DROP, token.WITH, token.TRUNCATE,
+	token.COPY, token.EXPLAIN, token.VACUUM, token.ANALYZE,
+	token.USE, token.SET, token.FETCH, token.LPAREN, token.VALUES,
+	token.TABLE,
+}
+
+// unsupportedf records an UnsupportedStatementError for a statement
+// introduced by tok that cannot continue at the current token.
+func (p *Parser) unsupportedf(tok token.Token, format string, args ...interface{}) {
+	p.reportf(&UnsupportedStatementError{Token: tok, Value: p.cur.Value}, format, args...)
+}
+
+// isUnterminatedQuote reports whether an ILLEGAL token is a quoted string
+// or identifier the lexer ran off the end of. A lone $ is not.
+func isUnterminatedQuote(v string) bool {
+	if len(v) > 1 && v[0] == '$' {
+		return true
+	}
+	return len(v) > 0 && strings.IndexByte("'\"`[", v[0]) >= 0
+}
+
 // parseStatement dispatches to the appropriate statement parser.
 func (p *Parser) parseStatement() ast.Statement {
+	if !p.enter() {
+		return nil
+	}
+	defer p.leave()
+
 	switch p.cur.Type {
 	case token.SELECT:
 		return p.parseSelect()
@@ -191,12 +529,36 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseWith()
 	case token.TRUNCATE:
 		return p.parseTruncate()
-	case token.EXPLAIN, token.ANALYZE:
+	case token.COPY:
+		return p.parseCopy()
+	case token.EXPLAIN:
 		return p.parseExplain()
+	case token.VACUUM:
+		return p.parseVacuum()
+	case token.ANALYZE:
+		return p.parseAnalyze()
+	case token.USE:
+		return p.parseUse()
+	case token.SET:
+		return p.parseSetSession()
+	case token.FETCH:
+		return p.parseFetch()
 	case token.LPAREN:
 		return p.parseParenthesizedStatement()
+	case token.VALUES:
+		return p.parseValuesClause()
+	case token.TABLE:
+		return p.parseTableStmt()
 	default:
-		p.errorf("unexpected token %v at start of statement", p.cur.Type)
+		// SHOW and DECLARE are not keywords, so they stay usable as names.
+		if p.curIsWord("SHOW") {
+			return p.parseShow()
+		}
+		if p.curIsWord("DECLARE") {
+			return p.parseDeclareCursor()
+		}
+		p.wantAny(statementStart...)
+		p.unsupportedf(p.cur.Type, "unexpected token %v at start of statement", p.cur.Type)
 		p.advance() // Skip to recover
 		return nil
 	}
@@ -264,13 +626,13 @@ func (p *Parser) parseWithClause() *ast.WithClause {
 }
 
 func (p *Parser) parseCTE() *ast.CTE {
-	if !p.curIs(token.IDENT) {
+	if !p.curIsIdent() {
 		p.errorf("expected CTE name")
 		return nil
 	}
 
 	cte := &ast.CTE{
-		Name: p.cur.Value,
+		Name: p.curIdentValue(),
 	}
 	p.advance()
 
@@ -283,6 +645,16 @@ func (p *Parser) parseCTE() *ast.CTE {
 		return nil
 	}
 
+	// PostgreSQL optimizer hint: AS [NOT] MATERIALIZED
+	if p.curIs(token.MATERIALIZED) || p.curIs(token.NOT) && p.peekIs(token.MATERIALIZED) {
+		materialized := p.curIs(token.MATERIALIZED)
+		if !materialized {
+			p.advance() // consume NOT
+		}
+		p.advance() // consume MATERIALIZED
+		cte.Materialized = &materialized
+	}
+
 	if !p.expect(token.LPAREN) {
 		return nil
 	}
@@ -301,10 +673,10 @@ func (p *Parser) parseColumnNameList() []string {
 
 	var names []string
 	for {
-		if !p.curIs(token.IDENT) {
+		if !p.curIsIdent() {
 			break
 		}
-		names = append(names, p.cur.Value)
+		names = append(names, p.curIdentValue())
 		p.advance()
 
 		if !p.curIs(token.COMMA) {
@@ -322,18 +694,39 @@ func (p *Parser) parseCreate() ast.Statement {
 	pos := p.cur.Pos
 	p.advance() // consume CREATE
 
-	// Skip TEMPORARY/TEMP
-	if p.curIs(token.TEMPORARY) || p.curIs(token.TEMP) {
+	if p.curIs(token.OR) && p.peekIs(token.REPLACE) {
+		p.advance()
+		p.advance()
+		if !p.curIsWord("FUNCTION") && !p.curIsWord("PROCEDURE") {
+			p.unsupportedf(token.CREATE, "expected FUNCTION or PROCEDURE after CREATE OR REPLACE")
+			return nil
+		}
+		return p.parseCreateFunction(pos, true)
+	}
+
+	// TEMPORARY/TEMP is kept only for sequences
+	temporary := p.curIs(token.TEMPORARY) || p.curIs(token.TEMP)
+	if temporary {
 		p.advance()
 	}
 
+	if p.curIsWord("FUNCTION") || p.curIsWord("PROCEDURE") {
+		return p.parseCreateFunction(pos, false)
+	}
+
 	switch p.cur.Type {
 	case token.TABLE:
 		return p.parseCreateTable(pos)
 	case token.INDEX, token.UNIQUE:
 		return p.parseCreateIndex(pos)
+	case token.DATABASE:
+		return p.parseCreateDatabase(pos)
+	case token.SCHEMA:
+		return p.parseCreateSchema(pos)
+	case token.SEQUENCE:
+		return p.parseCreateSequence(pos, temporary)
 	default:
-		p.errorf("expected TABLE or INDEX after CREATE")
+		p.unsupportedf(token.CREATE, "expected TABLE, INDEX, DATABASE, SCHEMA, SEQUENCE, FUNCTION or PROCEDURE after CREATE")
 		return nil
 	}
 }
@@ -360,7 +753,7 @@ func (p *Parser) parseCreateTable(pos token.Pos) ast.Statement {
 	if p.curIs(token.AS) {
 		p.advance()
 		stmt.As = p.parseSelect()
-		stmt.EndPos = p.cur.Pos
+		stmt.EndPos = p.prevPos
 		return stmt
 	}
 
@@ -391,10 +784,29 @@ func (p *Parser) parseCreateTable(pos token.Pos) ast.Statement {
 
 	p.expect(token.RPAREN)
 
-	// Parse table options (ENGINE, CHARSET, etc.)
+	// PostgreSQL storage parameters
+	if p.curIs(token.WITH) {
+		stmt.With = p.parseWithOptions()
+	}
+
+	// Parse table options (ENGINE, CHARSET, etc.). MySQL lets TABLESPACE
+	// appear among them.
 	stmt.Options = p.parseTableOptions()
+	if p.curIs(token.TABLESPACE) {
+		stmt.Tablespace = p.parseTablespace()
+		if p.curIs(token.STORAGE) {
+			p.advance()
+			if !p.curIsWord("DISK") && !p.curIsWord("MEMORY") {
+				p.errorf("expected DISK or MEMORY after STORAGE")
+				return nil
+			}
+			stmt.Storage = strings.ToUpper(p.cur.Value)
+			p.advance()
+		}
+		stmt.Options = append(stmt.Options, p.parseTableOptions()...)
+	}
 
-	stmt.EndPos = p.cur.Pos
+	stmt.EndPos = p.prevPos
 	return stmt
 }
 
@@ -410,7 +822,7 @@ func (p *Parser) parseColumnDef() *ast.ColumnDef {
 	p.advance()
 
 	col.Type = p.parseDataType()
-	col.Constraints = p.parseColumnConstraints()
+	p.parseColumnConstraints(col)
 
 	return col
 }
@@ -427,6 +839,18 @@ func (p *Parser) parseDataType() *ast.DataType {
 		return dt
 	}
 
+	// Schema-qualified user-defined type: myschema.mytype
+	if p.curIs(token.DOT) {
+		p.advance()
+		if !p.curIsIdent() {
+			p.errorf("expected type name after '.'")
+			return dt
+		}
+		dt.Schema = dt.Name
+		dt.Name = p.cur.Value
+		p.advance()
+	}
+
 	// Handle multi-word types like DOUBLE PRECISION, CHARACTER VARYING
 	if p.curIs(token.PRECISION) || p.curIs(token.VARYING) {
 		dt.Name += " " + p.cur.Value
@@ -495,9 +919,9 @@ func (p *Parser) parseDataType() *ast.DataType {
 	}
 }
 
-func (p *Parser) parseColumnConstraints() []*ast.ColumnConstraint {
-	var constraints []*ast.ColumnConstraint
-
+// parseColumnConstraints parses the constraints and attributes following
+// a column's type into col.
+func (p *Parser) parseColumnConstraints(col *ast.ColumnDef) {
 	for {
 		var constraint *ast.ColumnConstraint
 
@@ -567,12 +991,21 @@ func (p *Parser) parseColumnConstraints() []*ast.ColumnConstraint {
 		case token.GENERATED:
 			p.advance()
 			constraint = p.parseGeneratedConstraint(name)
+		case token.COMMENT_KW:
+			// MySQL column comment - kept on the column, not a constraint
+			p.advance()
+			if !p.curIs(token.STRING) {
+				p.errorf("expected string after COMMENT")
+				return
+			}
+			col.Comment = p.cur.Value
+			p.advance()
 		default:
-			return constraints
+			return
 		}
 
 		if constraint != nil {
-			constraints = append(constraints, constraint)
+			col.Constraints = append(col.Constraints, constraint)
 		}
 	}
 }
@@ -683,6 +1116,7 @@ func (p *Parser) parseTableConstraint() *ast.TableConstraint {
 		if p.curIs(token.LPAREN) {
 			tc.Columns = p.parseColumnNameList()
 		}
+		tc.IndexTablespace = p.parseIndexTablespace()
 	case token.UNIQUE:
 		p.advance()
 		tc.Type = ast.ConstraintUnique
@@ -692,6 +1126,7 @@ func (p *Parser) parseTableConstraint() *ast.TableConstraint {
 		if p.curIs(token.LPAREN) {
 			tc.Columns = p.parseColumnNameList()
 		}
+		tc.IndexTablespace = p.parseIndexTablespace()
 	case token.FOREIGN:
 		p.advance()
 		p.expect(token.KEY)
@@ -712,141 +1147,628 @@ func (p *Parser) parseTableConstraint() *ast.TableConstraint {
 	return tc
 }
 
-func (p *Parser) parseTableOptions() []*ast.TableOption {
-	var opts []*ast.TableOption
+// parseTablespace parses TABLESPACE name.
+func (p *Parser) parseTablespace() string {
+	p.advance() // consume TABLESPACE
+	if !p.curIsIdent() {
+		p.errorf("expected tablespace name")
+		return ""
+	}
+	name := p.curIdentValue()
+	p.advance()
+	return name
+}
 
-	for {
-		switch p.cur.Type {
-		case token.ENGINE:
-			p.advance()
-			if p.curIs(token.EQ) {
-				p.advance()
-			}
-			if p.curIs(token.IDENT) {
-				opts = append(opts, &ast.TableOption{Name: "ENGINE", Value: p.cur.Value})
-				p.advance()
-			}
-		case token.CHARSET, token.CHARACTER:
-			p.advance()
-			if p.curIs(token.SET) {
-				p.advance()
-			}
-			if p.curIs(token.EQ) {
-				p.advance()
-			}
-			if p.curIs(token.IDENT) {
-				opts = append(opts, &ast.TableOption{Name: "CHARSET", Value: p.cur.Value})
-				p.advance()
-			}
-		case token.COLLATE:
-			p.advance()
-			if p.curIs(token.EQ) {
-				p.advance()
-			}
-			if p.curIs(token.IDENT) {
-				opts = append(opts, &ast.TableOption{Name: "COLLATE", Value: p.cur.Value})
-				p.advance()
-			}
-		case token.COMMENT_KW:
-			p.advance()
-			if p.curIs(token.EQ) {
-				p.advance()
-			}
-			if p.curIs(token.STRING) {
-				opts = append(opts, &ast.TableOption{Name: "COMMENT", Value: p.cur.Value})
-				p.advance()
-			}
-		case token.AUTO_INCREMENT:
-			p.advance()
-			if p.curIs(token.EQ) {
-				p.advance()
-			}
-			if p.curIs(token.INT) {
-				opts = append(opts, &ast.TableOption{Name: "AUTO_INCREMENT", Value: p.cur.Value})
-				p.advance()
-			}
-		default:
-			return opts
-		}
+// parseIndexTablespace parses the optional USING INDEX TABLESPACE name
+// after a PRIMARY KEY or UNIQUE constraint's columns.
+func (p *Parser) parseIndexTablespace() string {
+	if !p.curIs(token.USING) || !p.peekIs(token.INDEX) {
+		return ""
+	}
+	p.advance() // consume USING
+	p.advance() // consume INDEX
+	if !p.curIs(token.TABLESPACE) {
+		p.expect(token.TABLESPACE)
+		return ""
 	}
+	return p.parseTablespace()
 }
 
-func (p *Parser) parseCreateIndex(pos token.Pos) ast.Statement {
-	stmt := &ast.CreateIndexStmt{StartPos: pos}
+func (p *Parser) parseCreateDatabase(pos token.Pos) ast.Statement {
+	p.advance() // consume DATABASE
 
-	if p.curIs(token.UNIQUE) {
-		stmt.Unique = true
+	stmt := &ast.CreateDatabaseStmt{StartPos: pos}
+	stmt.IfNotExists = p.parseIfNotExists()
+	stmt.Name = p.parseObjectName("database")
+	if p.curIs(token.WITH) {
+		// PostgreSQL: CREATE DATABASE name WITH OWNER = role ...
 		p.advance()
 	}
+	stmt.Options = p.parseTableOptions()
 
-	p.expect(token.INDEX)
+	stmt.EndPos = p.prevPos
+	return stmt
+}
 
-	if p.curIs(token.CONCURRENTLY) {
-		stmt.Concurrent = true
+func (p *Parser) parseCreateSchema(pos token.Pos) ast.Statement {
+	p.advance() // consume SCHEMA
+
+	stmt := &ast.CreateSchemaStmt{StartPos: pos}
+	stmt.IfNotExists = p.parseIfNotExists()
+	if !p.curIsWord("AUTHORIZATION") {
+		stmt.Name = p.parseObjectName("schema")
+	}
+	if p.curIsWord("AUTHORIZATION") {
 		p.advance()
+		stmt.Authorization = p.parseObjectName("role")
 	}
+	stmt.Options = p.parseTableOptions()
 
-	if p.curIs(token.IF) {
-		p.advance()
-		if p.curIs(token.NOT) {
+	stmt.EndPos = p.prevPos
+	return stmt
+}
+
+func (p *Parser) parseCreateSequence(pos token.Pos, temporary bool) ast.Statement {
+	p.advance() // consume SEQUENCE
+
+	stmt := &ast.CreateSequenceStmt{StartPos: pos, Temporary: temporary}
+	stmt.IfNotExists = p.parseIfNotExists()
+	stmt.Name = p.parseTableName()
+	for p.curIsIdent() {
+		opt := p.parseSequenceOption()
+		if opt == nil {
+			return nil
+		}
+		stmt.Options = append(stmt.Options, opt)
+		// MariaDB allows sequence options to be separated by commas
+		if p.curIs(token.COMMA) {
 			p.advance()
-			if p.curIs(token.EXISTS) {
-				stmt.IfNotExists = true
-				p.advance()
-			}
 		}
 	}
 
-	if p.curIs(token.IDENT) {
-		stmt.Name = p.cur.Value
-		p.advance()
-	}
+	stmt.EndPos = p.prevPos
+	return stmt
+}
 
-	p.expect(token.ON)
-	stmt.Table = p.parseTableName()
+// sequenceFlags maps the MariaDB one-word spellings of sequence options
+// that take no value to their standard names.
+var sequenceFlags = map[string]string{
+	"NOCYCLE":    "NO CYCLE",
+	"NOMINVALUE": "NO MINVALUE",
+	"NOMAXVALUE": "NO MAXVALUE",
+}
 
-	// USING method
-	if p.curIs(token.USING) {
-		p.advance()
-		if p.curIs(token.IDENT) {
-			stmt.Using = p.cur.Value
-			p.advance()
-		}
+// parseSequenceOption parses one CREATE SEQUENCE option.
+func (p *Parser) parseSequenceOption() *ast.Option {
+	word := strings.ToUpper(p.cur.Value)
+	p.advance()
+	if name, ok := sequenceFlags[word]; ok {
+		return &ast.Option{Name: name}
 	}
 
-	// Column list
-	p.expect(token.LPAREN)
-	for !p.curIs(token.RPAREN) && !p.curIs(token.EOF) {
-		col := &ast.IndexColumn{}
-		if p.curIsIdent() {
-			col.Column = p.curIdentValue()
-			p.advance()
-		} else if p.curIs(token.LPAREN) {
-			// Expression index (must be parenthesized)
-			col.Expr = p.parseExpr()
-		} else {
-			p.errorf("expected column name or expression")
+	switch word {
+	case "CYCLE", "NOCACHE":
+		return &ast.Option{Name: word}
+	case "NO":
+		if !p.curIsWord("CYCLE") && !p.curIsWord("MINVALUE") && !p.curIsWord("MAXVALUE") {
+			p.errorf("expected CYCLE, MINVALUE or MAXVALUE after NO")
 			return nil
 		}
-
-		if p.curIs(token.DESC) {
-			col.Desc = true
+		opt := &ast.Option{Name: "NO " + strings.ToUpper(p.cur.Value)}
+		p.advance()
+		return opt
+	case "AS":
+		if !p.curIsIdent() {
+			p.errorf("expected type after AS")
+			return nil
+		}
+		opt := &ast.Option{Name: word, Value: p.cur.Value}
+		p.advance()
+		return opt
+	case "OWNED":
+		if !p.expect(token.BY) {
+			return nil
+		}
+		opt := &ast.Option{Name: "OWNED BY"}
+		for {
+			if !p.curIsIdent() {
+				p.errorf("expected column after OWNED BY")
+				return nil
+			}
+			opt.Value += p.cur.Value
 			p.advance()
-		} else if p.curIs(token.ASC) {
+			if !p.curIs(token.DOT) {
+				return opt
+			}
+			opt.Value += "."
 			p.advance()
 		}
-
-		if p.curIs(token.NULLS) {
+	case "INCREMENT":
+		if p.curIs(token.BY) {
+			p.advance()
+		}
+		word = "INCREMENT BY"
+	case "START":
+		if p.curIs(token.WITH) {
 			p.advance()
-			if p.curIs(token.FIRST) {
-				col.Nulls = "FIRST"
-				p.advance()
-			} else if p.curIs(token.LAST) {
-				col.Nulls = "LAST"
-				p.advance()
-			}
 		}
+		word = "START WITH"
+	case "MINVALUE", "MAXVALUE", "CACHE":
+	default:
+		p.errorf("unexpected sequence option %s", word)
+		return nil
+	}
 
+	// MariaDB allows INCREMENT = 5, START = 1, ...
+	if p.curIs(token.EQ) {
+		p.advance()
+	}
+	sign := ""
+	if p.curIs(token.MINUS) {
+		sign = "-"
+		p.advance()
+	}
+	if !p.curIs(token.INT) {
+		p.errorf("expected number for sequence option %s", word)
+		return nil
+	}
+	opt := &ast.Option{Name: word, Value: sign + p.cur.Value}
+	p.advance()
+	return opt
+}
+
+// parseCreateFunction parses CREATE FUNCTION or CREATE PROCEDURE after
+// CREATE [OR REPLACE]. The body is taken as a string literal and not
+// parsed; a MySQL BEGIN ... END body is not supported.
+func (p *Parser) parseCreateFunction(pos token.Pos, orReplace bool) ast.Statement {
+	stmt := &ast.CreateFunctionStmt{
+		StartPos:  pos,
+		OrReplace: orReplace,
+		Procedure: p.curIsWord("PROCEDURE"),
+	}
+	p.advance() // consume FUNCTION or PROCEDURE
+
+	stmt.Name = p.parseTableName()
+	if !p.expect(token.LPAREN) {
+		return nil
+	}
+	for !p.curIs(token.RPAREN) {
+		arg := p.parseFuncArg()
+		if arg == nil {
+			return nil
+		}
+		stmt.Args = append(stmt.Args, arg)
+		if !p.curIs(token.COMMA) {
+			break
+		}
+		p.advance()
+	}
+	if !p.expect(token.RPAREN) {
+		return nil
+	}
+
+	if p.curIsWord("RETURNS") {
+		p.advance()
+		switch {
+		case p.curIs(token.TABLE) && p.peekIs(token.LPAREN):
+			stmt.Table = p.parseReturnsTable()
+			if stmt.Table == nil {
+				return nil
+			}
+		case p.curIsWord("SETOF"):
+			stmt.SetOf = true
+			p.advance()
+			fallthrough
+		default:
+			stmt.Returns = p.parseDataType()
+		}
+	}
+
+	for !p.curIs(token.EOF) && !p.curIs(token.SEMICOLON) && len(p.errors) == 0 {
+		switch {
+		case p.curIs(token.AS):
+			p.advance()
+			if !p.curIs(token.STRING) {
+				p.errorf("expected string literal for routine body")
+				return nil
+			}
+			stmt.Body = p.parseLiteral(ast.LiteralString)
+		case p.curIsWord("LANGUAGE"):
+			p.advance()
+			if p.curIs(token.STRING) {
+				stmt.Language = p.cur.Value
+				p.advance()
+			} else {
+				stmt.Language = p.parseObjectName("language")
+			}
+		case p.curIsWord("RETURN"):
+			p.advance()
+			stmt.Return = p.parseExpr()
+		case p.curIs(token.BEGIN):
+			p.unsupportedf(token.CREATE, "BEGIN ... END routine bodies are not supported")
+			return nil
+		case p.curIsIdent():
+			stmt.Options = append(stmt.Options, p.parseRoutineOption())
+		default:
+			p.errorf("unexpected %v in routine definition", p.cur.Type)
+			return nil
+		}
+	}
+
+	stmt.EndPos = p.prevPos
+	return stmt
+}
+
+// parseFuncArg parses a routine parameter:
+// [IN | OUT | INOUT | VARIADIC] [name] type [{DEFAULT | =} expr].
+func (p *Parser) parseFuncArg() *ast.FuncArg {
+	arg := &ast.FuncArg{}
+	for _, mode := range []string{"IN", "OUT", "INOUT", "VARIADIC"} {
+		if p.curIsWord(mode) && startsFuncArgType(p.peek().Type) {
+			arg.Mode = mode
+			p.advance()
+			break
+		}
+	}
+
+	// A word followed by another word is the parameter name, unless it
+	// starts a built-in type such as DOUBLE PRECISION.
+	if p.curIsIdent() && !isTypeKeyword(p.cur.Type) && startsFuncArgType(p.peek().Type) {
+		arg.Name = p.curIdentValue()
+		p.advance()
+	}
+	arg.Type = p.parseDataType()
+
+	if p.curIs(token.DEFAULT) || p.curIs(token.EQ) {
+		p.advance()
+		arg.Default = p.parseExpr()
+	}
+	if len(p.errors) > 0 {
+		return nil
+	}
+	return arg
+}
+
+// startsFuncArgType reports whether t can begin the type of a routine
+// parameter.
+func startsFuncArgType(t token.Token) bool {
+	return (t == token.IDENT || t.IsKeyword()) && t != token.DEFAULT
+}
+
+// parseReturnsTable parses the column list of RETURNS TABLE (name type, ...).
+func (p *Parser) parseReturnsTable() []*ast.FuncArg {
+	p.advance() // consume TABLE
+	p.advance() // consume (
+
+	var cols []*ast.FuncArg
+	for {
+		if !p.curIsIdent() {
+			p.errorf("expected column name in RETURNS TABLE")
+			return nil
+		}
+		col := &ast.FuncArg{Name: p.curIdentValue()}
+		p.advance()
+		col.Type = p.parseDataType()
+		cols = append(cols, col)
+		if !p.curIs(token.COMMA) {
+			break
+		}
+		p.advance()
+	}
+	if !p.expect(token.RPAREN) {
+		return nil
+	}
+	return cols
+}
+
+// routinePhrases maps the first word of a multi-word routine
+// characteristic to the number of words that follow it.
+var routinePhrases = map[string]int{
+	"SECURITY": 1, // SECURITY DEFINER, SECURITY INVOKER
+	"NOT":      1, // NOT DETERMINISTIC, NOT LEAKPROOF
+	"PARALLEL": 1, // PARALLEL SAFE
+	"CONTAINS": 1, // CONTAINS SQL
+	"NO":       1, // NO SQL
+	"SQL":      2, // SQL SECURITY DEFINER
+	"EXTERNAL": 1, // EXTERNAL SECURITY
+	"READS":    2, // READS SQL DATA
+	"MODIFIES": 2, // MODIFIES SQL DATA
+	"CALLED":   3, // CALLED ON NULL INPUT
+	"RETURNS":  4, // RETURNS NULL ON NULL INPUT
+}
+
+// parseRoutineOption parses a routine characteristic such as IMMUTABLE,
+// SECURITY DEFINER or COST 100. A characteristic followed by a number or
+// string takes it as its value.
+func (p *Parser) parseRoutineOption() *ast.Option {
+	words := []string{strings.ToUpper(p.cur.Value)}
+	n := routinePhrases[words[0]]
+	p.advance()
+	for ; n > 0 && p.curIsIdent(); n-- {
+		words = append(words, strings.ToUpper(p.cur.Value))
+		p.advance()
+	}
+
+	name := strings.Join(words, " ")
+	if p.curIs(token.INT) || p.curIs(token.FLOAT) || p.curIs(token.STRING) {
+		return p.parseOptionValue(name)
+	}
+	return &ast.Option{Name: name}
+}
+
+func (p *Parser) parseDropDatabase(pos token.Pos) ast.Statement {
+	p.advance() // consume DATABASE
+
+	stmt := &ast.DropDatabaseStmt{StartPos: pos}
+	stmt.IfExists = p.parseIfExists()
+	stmt.Name = p.parseObjectName("database")
+
+	stmt.EndPos = p.prevPos
+	return stmt
+}
+
+func (p *Parser) parseDropSchema(pos token.Pos) ast.Statement {
+	p.advance() // consume SCHEMA
+
+	stmt := &ast.DropSchemaStmt{StartPos: pos}
+	stmt.IfExists = p.parseIfExists()
+	for {
+		stmt.Names = append(stmt.Names, p.parseObjectName("schema"))
+		if !p.curIs(token.COMMA) {
+			break
+		}
+		p.advance()
+	}
+
+	switch {
+	case p.curIs(token.CASCADE):
+		stmt.Cascade = true
+		p.advance()
+	case p.curIs(token.RESTRICT):
+		stmt.Restrict = true
+		p.advance()
+	}
+
+	stmt.EndPos = p.prevPos
+	return stmt
+}
+
+// parseIfNotExists consumes an optional IF NOT EXISTS.
+func (p *Parser) parseIfNotExists() bool {
+	if !p.curIs(token.IF) {
+		return false
+	}
+	p.advance()
+	p.expect(token.NOT)
+	p.expect(token.EXISTS)
+	return true
+}
+
+// parseIfExists consumes an optional IF EXISTS.
+func (p *Parser) parseIfExists() bool {
+	if !p.curIs(token.IF) {
+		return false
+	}
+	p.advance()
+	p.expect(token.EXISTS)
+	return true
+}
+
+// parseObjectName parses the unqualified name of a database, schema or
+// role; what names the object in the error message.
+func (p *Parser) parseObjectName(what string) string {
+	if !p.curIsIdent() {
+		p.errorf("expected %s name", what)
+		return ""
+	}
+	name := p.cur.Value
+	p.advance()
+	return name
+}
+
+// curIsWord reports whether the current token is the identifier or
+// keyword word, for words the lexer does not treat as keywords.
+func (p *Parser) curIsWord(word string) bool {
+	return p.curIsIdent() && strings.EqualFold(p.cur.Value, word)
+}
+
+// peekIsWord is like curIsWord for the next token.
+func (p *Parser) peekIsWord(word string) bool {
+	next := p.peek()
+	return (next.Type == token.IDENT || next.Type.IsKeyword()) && strings.EqualFold(next.Value, word)
+}
+
+func (p *Parser) parseTableOptions() []*ast.TableOption {
+	var opts []*ast.TableOption
+
+	isDefault := false
+	for {
+		var name string
+		switch {
+		case p.curIs(token.DEFAULT) &&
+			(p.peekIs(token.CHARSET) || p.peekIs(token.CHARACTER) || p.peekIs(token.COLLATE)):
+			// DEFAULT CHARSET is equivalent to CHARSET, but keeps its spelling
+			isDefault = true
+			p.advance()
+			continue
+		case p.curIs(token.CHARSET), p.curIs(token.CHARACTER):
+			p.advance()
+			if p.curIs(token.SET) {
+				p.advance()
+			}
+			name = "CHARSET"
+		case p.curIs(token.ENGINE), p.curIs(token.COLLATE),
+			p.curIs(token.COMMENT_KW), p.curIs(token.AUTO_INCREMENT):
+			name = strings.ToUpper(p.cur.Value)
+			p.advance()
+		case p.curIsIdent() && p.peekIs(token.EQ):
+			// Any other NAME=VALUE option (ROW_FORMAT, KEY_BLOCK_SIZE, ...)
+			name = strings.ToUpper(p.cur.Value)
+			p.advance()
+		default:
+			return opts
+		}
+
+		if p.curIs(token.EQ) {
+			p.advance()
+		}
+		opt := p.parseOptionValue(name)
+		if opt == nil {
+			return opts
+		}
+		opt.Default, isDefault = isDefault, false
+		opts = append(opts, opt)
+
+		// MySQL allows table options to be separated by commas
+		if p.curIs(token.COMMA) {
+			p.advance()
+		}
+	}
+}
+
+// parseOptionValue parses the value of a DDL option.
+func (p *Parser) parseOptionValue(name string) *ast.Option {
+	opt := &ast.Option{Name: name}
+	switch {
+	case p.curIs(token.STRING):
+		opt.Value = p.cur.Value
+		opt.Quoted = true
+	case p.curIs(token.INT), p.curIs(token.FLOAT), p.curIsIdent():
+		opt.Value = p.cur.Value
+	default:
+		p.errorf("expected value for option %s", name)
+		return nil
+	}
+	p.advance()
+	return opt
+}
+
+// parseWithOptions parses a PostgreSQL storage parameter list:
+// WITH (name [= value], ...)
+func (p *Parser) parseWithOptions() []*ast.Option {
+	p.advance() // consume WITH
+	if !p.expect(token.LPAREN) {
+		return nil
+	}
+
+	var opts []*ast.Option
+	for {
+		if !p.curIsIdent() {
+			p.errorf("expected storage parameter name")
+			return nil
+		}
+		name := p.cur.Value
+		p.advance()
+		// Namespaced parameters, e.g. toast.autovacuum_enabled
+		for p.curIs(token.DOT) {
+			p.advance()
+			if !p.curIsIdent() {
+				p.errorf("expected storage parameter name")
+				return nil
+			}
+			name += "." + p.cur.Value
+			p.advance()
+		}
+
+		opt := &ast.Option{Name: name}
+		if p.curIs(token.EQ) {
+			p.advance()
+			opt = p.parseOptionValue(name)
+			if opt == nil {
+				return nil
+			}
+		}
+		opts = append(opts, opt)
+
+		if !p.curIs(token.COMMA) {
+			break
+		}
+		p.advance()
+	}
+	p.expect(token.RPAREN)
+
+	return opts
+}
+
+// parseIndexOptions parses MySQL index options following the column list.
+func (p *Parser) parseIndexOptions() []*ast.Option {
+	var opts []*ast.Option
+
+	for {
+		var name string
+		switch {
+		case p.curIs(token.COMMENT_KW), p.curIs(token.ALGORITHM), p.curIs(token.LOCK_KW),
+			p.curIsWord("KEY_BLOCK_SIZE"):
+			name = strings.ToUpper(p.cur.Value)
+			p.advance()
+		case p.curIsIdent() && p.peekIs(token.EQ):
+			name = strings.ToUpper(p.cur.Value)
+			p.advance()
+		default:
+			return opts
+		}
+
+		if p.curIs(token.EQ) {
+			p.advance()
+		}
+		opt := p.parseOptionValue(name)
+		if opt == nil {
+			return opts
+		}
+		opts = append(opts, opt)
+	}
+}
+
+func (p *Parser) parseCreateIndex(pos token.Pos) ast.Statement {
+	stmt := &ast.CreateIndexStmt{StartPos: pos}
+
+	if p.curIs(token.UNIQUE) {
+		stmt.Unique = true
+		p.advance()
+	}
+
+	p.expect(token.INDEX)
+
+	if p.curIs(token.CONCURRENTLY) {
+		stmt.Concurrent = true
+		p.advance()
+	}
+
+	if p.curIs(token.IF) {
+		p.advance()
+		if p.curIs(token.NOT) {
+			p.advance()
+			if p.curIs(token.EXISTS) {
+				stmt.IfNotExists = true
+				p.advance()
+			}
+		}
+	}
+
+	if p.curIs(token.IDENT) {
+		stmt.Name = p.cur.Value
+		p.advance()
+	}
+
+	p.expect(token.ON)
+	stmt.Table = p.parseTableName()
+
+	// USING method
+	if p.curIs(token.USING) {
+		p.advance()
+		if p.curIs(token.IDENT) {
+			stmt.Using = p.cur.Value
+			p.advance()
+		}
+	}
+
+	// Column list
+	p.expect(token.LPAREN)
+	for !p.curIs(token.RPAREN) && !p.curIs(token.EOF) {
+		col := p.parseIndexColumn()
+		if col == nil {
+			return nil
+		}
 		stmt.Columns = append(stmt.Columns, col)
 
 		if !p.curIs(token.COMMA) {
@@ -856,22 +1778,100 @@ func (p *Parser) parseCreateIndex(pos token.Pos) ast.Statement {
 	}
 	p.expect(token.RPAREN)
 
+	// Covering index columns (PostgreSQL, SQL Server)
+	if p.curIs(token.INCLUDE) {
+		p.advance()
+		if !p.curIs(token.LPAREN) {
+			p.expect(token.LPAREN)
+			return nil
+		}
+		stmt.Include = p.parseColumnNameList()
+		if len(stmt.Include) == 0 {
+			p.errorf("expected column name in INCLUDE")
+			return nil
+		}
+	}
+
+	// PostgreSQL storage parameters
+	if p.curIs(token.WITH) {
+		stmt.With = p.parseWithOptions()
+	}
+
+	if p.curIs(token.TABLESPACE) {
+		stmt.Tablespace = p.parseTablespace()
+	}
+
+	// MySQL index options
+	stmt.Options = p.parseIndexOptions()
+
 	// WHERE clause for partial index
 	if p.curIs(token.WHERE) {
 		p.advance()
 		stmt.Where = p.parseExpr()
 	}
 
-	stmt.EndPos = p.cur.Pos
+	stmt.EndPos = p.prevPos
 	return stmt
 }
 
+// parseIndexColumn parses an index element: a column name, a function call,
+// or a parenthesized expression, followed by optional ordering.
+func (p *Parser) parseIndexColumn() *ast.IndexColumn {
+	col := &ast.IndexColumn{}
+	if p.curIsIdent() && p.peekIs(token.LPAREN) {
+		// Function-call index element, e.g. lower(email)
+		col.Expr = p.parseExpr()
+		if col.Expr == nil {
+			return nil
+		}
+	} else if p.curIsIdent() {
+		col.Column = p.curIdentValue()
+		p.advance()
+	} else if p.curIs(token.LPAREN) {
+		// Expression index (must be parenthesized)
+		col.Expr = p.parseExpr()
+		if col.Expr == nil {
+			return nil
+		}
+	} else {
+		p.errorf("expected column name or expression")
+		return nil
+	}
+
+	// Operator class names are never keywords, so ASC, DESC and NULLS
+	// are not mistaken for one.
+	if p.curIs(token.IDENT) {
+		col.OpClass = p.cur.Value
+		p.advance()
+	}
+
+	if p.curIs(token.DESC) {
+		col.Desc = true
+		p.advance()
+	} else if p.curIs(token.ASC) {
+		p.advance()
+	}
+
+	if p.curIs(token.NULLS) {
+		p.advance()
+		if p.curIs(token.FIRST) {
+			col.Nulls = "FIRST"
+			p.advance()
+		} else if p.curIs(token.LAST) {
+			col.Nulls = "LAST"
+			p.advance()
+		}
+	}
+
+	return col
+}
+
 func (p *Parser) parseAlter() ast.Statement {
 	pos := p.cur.Pos
 	p.advance() // consume ALTER
 
 	if !p.curIs(token.TABLE) {
-		p.errorf("expected TABLE after ALTER")
+		p.unsupportedf(token.ALTER, "expected TABLE after ALTER")
 		return nil
 	}
 	p.advance()
@@ -894,7 +1894,7 @@ func (p *Parser) parseAlter() ast.Statement {
 		p.advance()
 	}
 
-	stmt.EndPos = p.cur.Pos
+	stmt.EndPos = p.prevPos
 	return stmt
 }
 
@@ -1006,7 +2006,7 @@ func (p *Parser) parseAlterTableAction() ast.AlterTableAction {
 			// MySQL MODIFY COLUMN name type - parse type and constraints directly
 			colDef := &ast.ColumnDef{Name: action.Name}
 			colDef.Type = p.parseDataType()
-			colDef.Constraints = p.parseColumnConstraints()
+			p.parseColumnConstraints(colDef)
 			action.NewDef = colDef
 		}
 		return action
@@ -1024,8 +2024,12 @@ func (p *Parser) parseDrop() ast.Statement {
 		return p.parseDropTable(pos)
 	case token.INDEX:
 		return p.parseDropIndex(pos)
+	case token.DATABASE:
+		return p.parseDropDatabase(pos)
+	case token.SCHEMA:
+		return p.parseDropSchema(pos)
 	default:
-		p.errorf("expected TABLE or INDEX after DROP")
+		p.unsupportedf(token.DROP, "expected TABLE, INDEX, DATABASE or SCHEMA after DROP")
 		return nil
 	}
 }
@@ -1055,7 +2059,7 @@ func (p *Parser) parseDropTable(pos token.Pos) ast.Statement {
 		p.advance()
 	}
 
-	stmt.EndPos = p.cur.Pos
+	stmt.EndPos = p.prevPos
 	return stmt
 }
 
@@ -1091,7 +2095,7 @@ func (p *Parser) parseDropIndex(pos token.Pos) ast.Statement {
 		p.advance()
 	}
 
-	stmt.EndPos = p.cur.Pos
+	stmt.EndPos = p.prevPos
 	return stmt
 }
 
@@ -1107,7 +2111,7 @@ func (p *Parser) parseTruncate() ast.Statement {
 
 	// Parse table names
 	for {
-		stmt.Tables = append(stmt.Tables, p.parseTableName())
+		stmt.Tables = append(stmt.Tables, p.parseTableNameOnly())
 		if !p.curIs(token.COMMA) {
 			break
 		}
@@ -1119,10 +2123,383 @@ func (p *Parser) parseTruncate() ast.Statement {
 		p.advance()
 	}
 
-	stmt.EndPos = p.cur.Pos
+	stmt.EndPos = p.prevPos
+	return stmt
+}
+
+// parseVacuum parses VACUUM [FULL] [VERBOSE] [ANALYZE] [table, ...].
+func (p *Parser) parseVacuum() ast.Statement {
+	stmt := &ast.VacuumStmt{StartPos: p.cur.Pos}
+	p.advance() // consume VACUUM
+
+	for {
+		switch p.cur.Type {
+		case token.FULL:
+			stmt.Full = true
+		case token.VERBOSE:
+			stmt.Verbose = true
+		case token.ANALYZE:
+			stmt.Analyze = true
+		default:
+			stmt.Tables = p.parseMaintenanceTables()
+			stmt.EndPos = p.prevPos
+			return stmt
+		}
+		p.advance()
+	}
+}
+
+// parseAnalyze parses the ANALYZE maintenance command.
+func (p *Parser) parseAnalyze() ast.Statement {
+	stmt := &ast.AnalyzeStmt{StartPos: p.cur.Pos}
+	p.advance() // consume ANALYZE
+
+	if p.curIs(token.TABLE) {
+		stmt.Table = true
+		p.advance()
+	} else if p.curIs(token.VERBOSE) {
+		stmt.Verbose = true
+		p.advance()
+	}
+	stmt.Tables = p.parseMaintenanceTables()
+	if stmt.Table && len(stmt.Tables) == 0 {
+		p.errorf("expected table name")
+	}
+
+	stmt.EndPos = p.prevPos
 	return stmt
 }
 
+// parseShow parses MySQL SHOW statements.
+func (p *Parser) parseShow() ast.Statement {
+	stmt := &ast.ShowStmt{StartPos: p.cur.Pos}
+	p.advance() // consume SHOW
+
+	if p.curIs(token.FULL) {
+		stmt.Full = true
+		p.advance()
+	}
+	if p.curIsWord("GLOBAL") || p.curIsWord("SESSION") || p.curIs(token.LOCAL) {
+		stmt.Scope = strings.ToUpper(p.cur.Value)
+		p.advance()
+	}
+
+	if p.curIs(token.CREATE) {
+		// SHOW CREATE TABLE t, SHOW CREATE VIEW v, ...
+		p.advance()
+		if !p.curIsIdent() {
+			p.errorf("expected object type after SHOW CREATE")
+			return nil
+		}
+		stmt.Kind = "CREATE " + strings.ToUpper(p.cur.Value)
+		p.advance()
+		stmt.Table = p.parseTableName()
+		stmt.EndPos = p.prevPos
+		return stmt
+	}
+
+	var kind []string
+	for p.curIsIdent() && !p.curIs(token.FROM) && !p.curIs(token.IN) &&
+		!p.curIs(token.LIKE) && !p.curIs(token.WHERE) {
+		kind = append(kind, strings.ToUpper(p.cur.Value))
+		p.advance()
+	}
+	if len(kind) == 0 {
+		p.errorf("expected what to SHOW")
+		return nil
+	}
+	stmt.Kind = strings.Join(kind, " ")
+
+	if p.curIs(token.FROM) || p.curIs(token.IN) {
+		p.advance()
+		switch stmt.Kind {
+		case "COLUMNS", "FIELDS", "INDEX", "INDEXES", "KEYS":
+			stmt.Table = p.parseTableName()
+			if p.curIs(token.FROM) || p.curIs(token.IN) {
+				p.advance()
+				stmt.Database = p.parseObjectName("database")
+			}
+		default:
+			stmt.Database = p.parseObjectName("database")
+		}
+	}
+
+	if p.curIs(token.LIKE) {
+		p.advance()
+		stmt.Like = p.parseExpr()
+	} else if p.curIs(token.WHERE) {
+		p.advance()
+		stmt.Where = p.parseExpr()
+	}
+
+	stmt.EndPos = p.prevPos
+	return stmt
+}
+
+// parseUse parses MySQL USE db.
+func (p *Parser) parseUse() ast.Statement {
+	stmt := &ast.UseStmt{StartPos: p.cur.Pos}
+	p.advance() // consume USE
+
+	stmt.Database = p.parseObjectName("database")
+	stmt.EndPos = p.prevPos
+	return stmt
+}
+
+// parseSetSession parses a SET statement at the start of a statement.
+func (p *Parser) parseSetSession() ast.Statement {
+	stmt := &ast.SetSessionStmt{StartPos: p.cur.Pos}
+	p.advance() // consume SET
+
+	if (p.curIsWord("SESSION") || p.curIsWord("GLOBAL") || p.curIs(token.LOCAL)) &&
+		!p.peekIs(token.EQ) && !p.peekIs(token.TO) {
+		stmt.Scope = strings.ToUpper(p.cur.Value)
+		p.advance()
+	}
+
+	v := p.parseSetVar()
+	if v == nil {
+		return nil
+	}
+	stmt.Vars = append(stmt.Vars, v)
+	for p.curIs(token.COMMA) {
+		p.advance()
+		if p.curIs(token.ATAT) || p.curIs(token.PARAM) {
+			// MySQL: SET @a = 1, @@b = 2
+			if v = p.parseSetVar(); v == nil {
+				return nil
+			}
+			stmt.Vars = append(stmt.Vars, v)
+			continue
+		}
+		// Either another PostgreSQL list value or another MySQL
+		// assignment; a list value is never a bare name = value.
+		expr := p.parseExpr()
+		if expr == nil {
+			return nil
+		}
+		if bin, ok := expr.(*ast.BinaryExpr); ok && bin.Op == token.EQ {
+			if col, ok := bin.Left.(*ast.ColName); ok {
+				v = &ast.SetVar{Name: strings.Join(col.Parts, "."), Values: []ast.Expr{bin.Right}}
+				stmt.Vars = append(stmt.Vars, v)
+				continue
+			}
+		}
+		v.Values = append(v.Values, expr)
+	}
+
+	stmt.EndPos = p.prevPos
+	return stmt
+}
+
+// parseSetVar parses a variable name, its assignment operator and its
+// first value.
+func (p *Parser) parseSetVar() *ast.SetVar {
+	v := &ast.SetVar{}
+	switch {
+	case p.curIs(token.ATAT):
+		// MySQL system variable: @@name or @@scope.name
+		p.advance()
+		v.Name = "@@" + p.parseDottedName()
+	case p.curIs(token.PARAM) && strings.HasPrefix(p.cur.Value, "@"):
+		v.Name = p.cur.Value
+		p.advance()
+	case p.curIsWord("NAMES"):
+		v.Name = "NAMES"
+		p.advance()
+	case p.curIsWord("TIME") && p.peekIs(token.ZONE):
+		v.Name = "TIME ZONE"
+		p.advance()
+		p.advance()
+	case p.curIsIdent():
+		v.Name = p.parseDottedName()
+	default:
+		p.errorf("expected variable name after SET")
+		return nil
+	}
+	if v.Name == "@@" || v.Name == "" {
+		return nil
+	}
+
+	if v.Name != "NAMES" && v.Name != "TIME ZONE" {
+		switch {
+		case p.curIs(token.EQ):
+			p.advance()
+		case p.curIs(token.TO):
+			v.To = true
+			p.advance()
+		default:
+			p.errorf("expected = or TO after %s", v.Name)
+			return nil
+		}
+	}
+
+	value := p.parseExpr()
+	if value == nil {
+		return nil
+	}
+	v.Values = append(v.Values, value)
+	return v
+}
+
+// parseDeclareCursor parses DECLARE name ... CURSOR ... FOR query.
+func (p *Parser) parseDeclareCursor() ast.Statement {
+	stmt := &ast.DeclareCursorStmt{StartPos: p.cur.Pos}
+	p.advance() // consume DECLARE
+
+	stmt.Name = p.parseObjectName("cursor")
+	if stmt.Name == "" {
+		return nil
+	}
+
+	for !p.curIsWord("CURSOR") {
+		switch {
+		case p.curIs(token.BINARY):
+			stmt.Binary = true
+			p.advance()
+		case p.curIsWord("INSENSITIVE") || p.curIsWord("ASENSITIVE"):
+			stmt.Insensitive = p.curIsWord("INSENSITIVE")
+			p.advance()
+		case p.curIsWord("SCROLL"):
+			stmt.Scroll = "SCROLL"
+			p.advance()
+		case p.curIs(token.NO) && strings.EqualFold(p.peek().Value, "SCROLL"):
+			stmt.Scroll = "NO SCROLL"
+			p.advance()
+			p.advance()
+		default:
+			p.errorf("expected CURSOR after DECLARE %s", stmt.Name)
+			return nil
+		}
+	}
+	p.advance() // consume CURSOR
+
+	if (p.curIs(token.WITH) || p.curIs(token.WITHOUT)) && strings.EqualFold(p.peek().Value, "HOLD") {
+		stmt.Hold = strings.ToUpper(p.cur.Value) + " HOLD"
+		p.advance()
+		p.advance()
+	}
+
+	if !p.expect(token.FOR) {
+		return nil
+	}
+	stmt.Query = p.parseStatement()
+	if stmt.Query == nil {
+		return nil
+	}
+	stmt.EndPos = p.prevPos
+	return stmt
+}
+
+// parseFetch parses a cursor FETCH statement.
+func (p *Parser) parseFetch() ast.Statement {
+	stmt := &ast.FetchStmt{StartPos: p.cur.Pos}
+	p.advance() // consume FETCH
+
+	switch {
+	case p.curIs(token.NEXT) || p.curIs(token.PRIOR) || p.curIs(token.FIRST) || p.curIs(token.LAST):
+		stmt.Direction = strings.ToUpper(p.cur.Value)
+		p.advance()
+	case p.curIs(token.ALL):
+		stmt.Direction = "ALL"
+		p.advance()
+	case p.curIsWord("ABSOLUTE") || p.curIsWord("RELATIVE"):
+		stmt.Direction = strings.ToUpper(p.cur.Value)
+		p.advance()
+		if stmt.Count = p.parseFetchCount(); stmt.Count == nil {
+			p.errorf("expected count after %s", stmt.Direction)
+			return nil
+		}
+	case p.curIsWord("FORWARD") || p.curIsWord("BACKWARD"):
+		stmt.Direction = strings.ToUpper(p.cur.Value)
+		p.advance()
+		if p.curIs(token.ALL) {
+			stmt.Direction += " ALL"
+			p.advance()
+		} else {
+			stmt.Count = p.parseFetchCount()
+		}
+	default:
+		stmt.Count = p.parseFetchCount()
+	}
+
+	if p.curIs(token.FROM) || p.curIs(token.IN) {
+		stmt.FromWord = strings.ToUpper(p.cur.Value)
+		p.advance()
+	}
+	stmt.Cursor = p.parseObjectName("cursor")
+	if stmt.Cursor == "" {
+		return nil
+	}
+
+	if p.curIs(token.INTO) {
+		p.advance()
+		for {
+			name := p.parseObjectName("variable")
+			if name == "" {
+				return nil
+			}
+			stmt.Into = append(stmt.Into, name)
+			if !p.curIs(token.COMMA) {
+				break
+			}
+			p.advance()
+		}
+	}
+
+	stmt.EndPos = p.prevPos
+	return stmt
+}
+
+// parseFetchCount parses the optional signed row count of a FETCH
+// direction, returning nil if there is none.
+func (p *Parser) parseFetchCount() ast.Expr {
+	switch {
+	case p.curIs(token.INT):
+		return p.parsePrimaryExpr()
+	case p.curIs(token.MINUS):
+		return p.parseUnaryMinus()
+	default:
+		return nil
+	}
+}
+
+// parseDottedName parses name[.name ...] and returns it joined with dots.
+func (p *Parser) parseDottedName() string {
+	if !p.curIsIdent() {
+		p.errorf("expected identifier")
+		return ""
+	}
+	name := p.cur.Value
+	p.advance()
+	for p.curIs(token.DOT) {
+		p.advance()
+		if !p.curIsIdent() {
+			p.errorf("expected identifier after '.'")
+			return ""
+		}
+		name += "." + p.cur.Value
+		p.advance()
+	}
+	return name
+}
+
+// parseMaintenanceTables parses the optional table list of VACUUM and
+// ANALYZE, which ends at the end of the statement.
+func (p *Parser) parseMaintenanceTables() []*ast.TableName {
+	if p.curIs(token.EOF) || p.curIs(token.SEMICOLON) {
+		return nil
+	}
+	var tables []*ast.TableName
+	for {
+		tables = append(tables, p.parseTableName())
+		if !p.curIs(token.COMMA) {
+			return tables
+		}
+		p.advance()
+	}
+}
+
 // parseParenthesizedStatement handles statements that start with parentheses,
 // like (SELECT ...) UNION (SELECT ...).
 func (p *Parser) parseParenthesizedStatement() ast.Statement {
@@ -1158,7 +2535,7 @@ func (p *Parser) parseParenthesizedStatement() ast.Statement {
 		sel.Limit = p.parseLimit()
 	}
 	sel.StartPos = pos
-	sel.EndPos = p.cur.Pos
+	sel.EndPos = p.prevPos
 
 	return sel
 }
@@ -1182,29 +2559,45 @@ func (p *Parser) parseExplain() ast.Statement {
 			stmt.Verbose = true
 			p.advance()
 		case token.FORMAT:
+			// FORMAT JSON, or MySQL's FORMAT=JSON
 			p.advance()
-			if p.curIs(token.IDENT) {
-				stmt.Format = p.cur.Value
+			if p.curIs(token.EQ) {
+				p.advance()
+			}
+			if p.curIsIdent() {
+				stmt.Format = p.curIdentValue()
 				p.advance()
 			}
 		case token.LPAREN:
-			// PostgreSQL style: EXPLAIN (ANALYZE, VERBOSE, ...)
+			// PostgreSQL style: EXPLAIN (ANALYZE, VERBOSE false, ...)
+			stmt.Parens = true
 			p.advance()
 			for !p.curIs(token.RPAREN) && !p.curIs(token.EOF) {
 				switch p.cur.Type {
 				case token.ANALYZE:
-					stmt.Analyze = true
+					p.advance()
+					stmt.Analyze = p.parseExplainBool()
 				case token.VERBOSE:
-					stmt.Verbose = true
+					p.advance()
+					stmt.Verbose = p.parseExplainBool()
 				case token.FORMAT:
 					p.advance()
-					if p.curIs(token.IDENT) {
-						stmt.Format = p.cur.Value
+					if p.curIsIdent() {
+						stmt.Format = p.curIdentValue()
+					}
+					p.advance()
+				default:
+					if p.Strict {
+						p.errorf("unsupported EXPLAIN option %s", p.cur.Value)
+						return nil
 					}
+					p.advance()
 				}
-				p.advance()
 				if p.curIs(token.COMMA) {
 					p.advance()
+				} else if p.Strict && !p.curIs(token.RPAREN) {
+					p.errorf("expected , or ) after EXPLAIN option")
+					return nil
 				}
 			}
 			p.expect(token.RPAREN)
@@ -1215,10 +2608,24 @@ func (p *Parser) parseExplain() ast.Statement {
 
 parseStmt:
 	stmt.Stmt = p.parseStatement()
-	stmt.EndPos = p.cur.Pos
+	stmt.EndPos = p.prevPos
 	return stmt
 }
 
+// parseExplainBool parses the optional boolean after a parenthesized
+// EXPLAIN option, as in ANALYZE false; an omitted value means true.
+func (p *Parser) parseExplainBool() bool {
+	switch {
+	case p.curIs(token.TRUE), p.curIs(token.ON), p.curIs(token.INT) && p.cur.Value == "1":
+		p.advance()
+		return true
+	case p.curIs(token.FALSE), p.curIsWord("OFF"), p.curIs(token.INT) && p.cur.Value == "0":
+		p.advance()
+		return false
+	}
+	return true
+}
+
 func (p *Parser) parseTableName() *ast.TableName {
 	if !p.curIsIdent() {
 		p.errorf("expected table name")
@@ -1242,11 +2649,39 @@ func (p *Parser) parseTableName() *ast.TableName {
 
 	tn := ast.GetTableName()
 	tn.StartPos = pos
-	tn.EndPos = p.cur.Pos
+	tn.EndPos = p.prevPos
 	tn.Parts = parts
 	return tn
 }
 
+// parseTableNameOnly parses a table name optionally preceded by ONLY,
+// which PostgreSQL uses to exclude tables inheriting from it, as in
+// ONLY t or ONLY (t). ONLY followed by a clause keyword or the end of
+// input is a table name.
+func (p *Parser) parseTableNameOnly() *ast.TableName {
+	pos := p.cur.Pos
+	next := p.peek().Type
+	only := p.curIs(token.ONLY) &&
+		(next == token.IDENT || next == token.LPAREN || next.IsKeyword() && !isClauseKeyword(next))
+	if !only {
+		return p.parseTableName()
+	}
+
+	p.advance() // consume ONLY
+	paren := p.curIs(token.LPAREN)
+	if paren {
+		p.advance()
+	}
+	tn := p.parseTableName()
+	if tn == nil || paren && !p.expect(token.RPAREN) {
+		return nil
+	}
+	tn.StartPos = pos
+	tn.EndPos = p.prevPos
+	tn.Only = true
+	return tn
+}
+
 func parseInt(s string) int {
 	// Use strconv to properly handle overflow
 	n, err := strconv.ParseInt(s, 10, 64)
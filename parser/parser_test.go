@@ -1,9 +1,16 @@
 package parser
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/freeeve/machparse/ast"
+	"github.com/freeeve/machparse/token"
 )
 
 func TestParseSelect(t *testing.T) {
@@ -65,40 +72,77 @@ func TestParseInsert(t *testing.T) {
 	}
 }
 
-func TestParseUpdate(t *testing.T) {
+func TestParseValuesDefault(t *testing.T) {
 	tests := []struct {
-		input    string
-		wantSets int
+		input string
+		rows  func(ast.Statement) [][]ast.Expr
 	}{
-		{"UPDATE users SET name = 'test' WHERE id = 1", 1},
-		{"UPDATE users SET name = 'test', email = 'a@b.com'", 2},
+		{"INSERT INTO t VALUES (DEFAULT, 1), (2, DEFAULT)", func(s ast.Statement) [][]ast.Expr {
+			return s.(*ast.InsertStmt).Values
+		}},
+		{"VALUES (DEFAULT, 1), (2, DEFAULT)", func(s ast.Statement) [][]ast.Expr {
+			return s.(*ast.ValuesStmt).Rows
+		}},
+		{"SELECT * FROM (VALUES (DEFAULT, 1), (2, DEFAULT)) AS v", func(s ast.Statement) [][]ast.Expr {
+			from := s.(*ast.SelectStmt).From.(*ast.AliasedTableExpr).Expr
+			return from.(*ast.ParenTableExpr).Expr.(*ast.ValuesStmt).Rows
+		}},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.input, func(t *testing.T) {
-			p := New(tt.input)
-			stmt, err := p.Parse()
+			stmt, err := New(tt.input).Parse()
 			if err != nil {
 				t.Fatalf("Parse error: %v", err)
 			}
-			upd, ok := stmt.(*ast.UpdateStmt)
-			if !ok {
-				t.Fatalf("Expected UpdateStmt, got %T", stmt)
+			rows := tt.rows(stmt)
+			if len(rows) != 2 || len(rows[0]) != 2 || len(rows[1]) != 2 {
+				t.Fatalf("Expected 2 rows of 2 values, got %v", rows)
 			}
-			if len(upd.Set) != tt.wantSets {
-				t.Errorf("Expected %d SET expressions, got %d", tt.wantSets, len(upd.Set))
+			for _, v := range []ast.Expr{rows[0][0], rows[1][1]} {
+				if lit, ok := v.(*ast.Literal); !ok || lit.Type != ast.LiteralDefault {
+					t.Errorf("Expected DEFAULT literal, got %#v", v)
+				}
 			}
 		})
 	}
+
+	if _, err := New("INSERT INTO t VALUES (DEFAULT + 1)").Parse(); err == nil {
+		t.Error("Expected error for DEFAULT used as an operand")
+	}
 }
 
-func TestParseDelete(t *testing.T) {
+func TestParseUpdateDefault(t *testing.T) {
+	stmt, err := New("UPDATE t SET a = DEFAULT, b = 1").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	exprs := stmt.(*ast.UpdateStmt).Set
+	if lit, ok := exprs[0].Expr.(*ast.Literal); !ok || lit.Type != ast.LiteralDefault {
+		t.Errorf("Expected DEFAULT literal, got %#v", exprs[0].Expr)
+	}
+
+	stmt, err = New("INSERT INTO t (a) VALUES (1) ON CONFLICT (a) DO UPDATE SET b = DEFAULT").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	set := stmt.(*ast.InsertStmt).OnConflict.Updates
+	if lit, ok := set[0].Expr.(*ast.Literal); !ok || lit.Type != ast.LiteralDefault {
+		t.Errorf("Expected DEFAULT literal, got %#v", set[0].Expr)
+	}
+
+	if _, err := New("UPDATE t SET a = DEFAULT + 1").Parse(); err == nil {
+		t.Error("Expected error for DEFAULT used as an operand")
+	}
+}
+
+func TestParseUpdate(t *testing.T) {
 	tests := []struct {
 		input    string
-		hasWhere bool
+		wantSets int
 	}{
-		{"DELETE FROM users WHERE id = 1", true},
-		{"DELETE FROM users", false},
+		{"UPDATE users SET name = 'test' WHERE id = 1", 1},
+		{"UPDATE users SET name = 'test', email = 'a@b.com'", 2},
 	}
 
 	for _, tt := range tests {
@@ -108,194 +152,2389 @@ func TestParseDelete(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Parse error: %v", err)
 			}
-			del, ok := stmt.(*ast.DeleteStmt)
+			upd, ok := stmt.(*ast.UpdateStmt)
 			if !ok {
-				t.Fatalf("Expected DeleteStmt, got %T", stmt)
+				t.Fatalf("Expected UpdateStmt, got %T", stmt)
 			}
-			if (del.Where != nil) != tt.hasWhere {
-				t.Errorf("Expected hasWhere=%v, got %v", tt.hasWhere, del.Where != nil)
+			if len(upd.Set) != tt.wantSets {
+				t.Errorf("Expected %d SET expressions, got %d", tt.wantSets, len(upd.Set))
 			}
 		})
 	}
 }
 
-func TestParseCreateTable(t *testing.T) {
-	input := `CREATE TABLE users (
-		id INT PRIMARY KEY,
-		name VARCHAR(255) NOT NULL,
-		email VARCHAR(255) UNIQUE,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-	)`
+func TestParseInsertSet(t *testing.T) {
+	stmt, err := New("INSERT INTO t SET a = 1, b = 2").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	ins := stmt.(*ast.InsertStmt)
+	if len(ins.SetAssignments) != 2 {
+		t.Fatalf("Expected 2 SET assignments, got %d", len(ins.SetAssignments))
+	}
+	if ins.SetAssignments[1].Column.Name() != "b" {
+		t.Errorf("Expected second column b, got %s", ins.SetAssignments[1].Column.Name())
+	}
+	if len(ins.Columns) != 0 || len(ins.Values) != 0 {
+		t.Errorf("Expected no column list or VALUES, got %d columns and %d rows", len(ins.Columns), len(ins.Values))
+	}
+}
 
-	p := New(input)
-	stmt, err := p.Parse()
+func TestParseInsertEmpty(t *testing.T) {
+	stmt, err := New("INSERT INTO t () VALUES (), ()").Parse()
 	if err != nil {
 		t.Fatalf("Parse error: %v", err)
 	}
+	ins := stmt.(*ast.InsertStmt)
+	if !ins.EmptyColumns || len(ins.Columns) != 0 {
+		t.Errorf("Expected an empty column list, got %d columns", len(ins.Columns))
+	}
+	if len(ins.Values) != 2 || len(ins.Values[0]) != 0 || len(ins.Values[1]) != 0 {
+		t.Errorf("Expected 2 empty rows, got %v", ins.Values)
+	}
 
-	create, ok := stmt.(*ast.CreateTableStmt)
-	if !ok {
-		t.Fatalf("Expected CreateTableStmt, got %T", stmt)
+	stmt, err = New("INSERT INTO t DEFAULT VALUES").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if ins := stmt.(*ast.InsertStmt); !ins.DefaultValues || ins.Values != nil {
+		t.Errorf("Expected DEFAULT VALUES without rows, got %v", ins.Values)
 	}
 
-	if create.Table.Name() != "users" {
-		t.Errorf("Expected table name 'users', got %s", create.Table.Name())
+	for _, input := range []string{
+		"INSERT INTO t VALUES (1, )",
+		"INSERT INTO t VALUES (, 1)",
+		"INSERT INTO t (a, ) VALUES (1)",
+	} {
+		if _, err := New(input).Parse(); err == nil {
+			t.Errorf("%s: expected error", input)
+		}
 	}
+}
 
-	if len(create.Columns) != 4 {
-		t.Errorf("Expected 4 columns, got %d", len(create.Columns))
+func TestParseInsertKeywordColumns(t *testing.T) {
+	stmt, err := New("INSERT INTO t (order, key, value) VALUES (1, 2, 3)").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	ins := stmt.(*ast.InsertStmt)
+	want := []string{"order", "key", "value"}
+	if len(ins.Columns) != len(want) {
+		t.Fatalf("Expected %d columns, got %d", len(want), len(ins.Columns))
+	}
+	for i, name := range want {
+		if ins.Columns[i].Name() != name {
+			t.Errorf("Column %d: expected %s, got %s", i, name, ins.Columns[i].Name())
+		}
 	}
 }
 
-func TestParseExpressions(t *testing.T) {
-	tests := []struct {
-		input string
-	}{
-		{"SELECT 1 + 2"},
-		{"SELECT a AND b OR c"},
-		{"SELECT a = 1 AND b = 2"},
-		{"SELECT a BETWEEN 1 AND 10"},
-		{"SELECT a IN (1, 2, 3)"},
-		{"SELECT a LIKE '%test%'"},
-		{"SELECT a IS NULL"},
-		{"SELECT a IS NOT NULL"},
-		{"SELECT CASE WHEN a = 1 THEN 'one' ELSE 'other' END"},
-		{"SELECT CAST(a AS INT)"},
-		{"SELECT COUNT(*)"},
-		{"SELECT SUM(amount)"},
-		{"SELECT a::int"},
-		{"SELECT a || b"},
-		{"SELECT COALESCE(a, b, c)"},
-		{"SELECT NULLIF(a, b)"},
-		{"SELECT EXISTS (SELECT 1 FROM t)"},
-		{"SELECT * FROM t WHERE a IN (SELECT id FROM t2)"},
+func TestParseQualifiedStar(t *testing.T) {
+	stmt, err := New("SELECT db.users.*, * FROM db.users").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	cols := stmt.(*ast.SelectStmt).Columns
+	star := cols[0].(*ast.StarExpr)
+	if strings.Join(star.QualifierParts, ".") != "db.users" || star.TableName() != "users" {
+		t.Errorf("Expected qualifier db.users, got %v", star.QualifierParts)
 	}
+	if cols[1].(*ast.StarExpr).HasQualifier() {
+		t.Errorf("Expected bare star to have no qualifier")
+	}
+}
 
-	for _, tt := range tests {
-		t.Run(tt.input, func(t *testing.T) {
-			p := New(tt.input)
-			stmt, err := p.Parse()
+func TestParseAdjacentStrings(t *testing.T) {
+	tests := map[string]string{
+		"SELECT 'a' 'b' 'c'":                "abc",
+		"SELECT 'foo'\n  'bar'":             "foobar",
+		"SELECT 'it''s' ' fine'":            "it's fine",
+		"SELECT 'a' AS 'b'":                 "a",
+		"SELECT x FROM t WHERE y = 'a' 'b'": "ab",
+	}
+
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			stmt, err := New(input).Parse()
 			if err != nil {
 				t.Fatalf("Parse error: %v", err)
 			}
-			if stmt == nil {
-				t.Fatal("Expected statement, got nil")
+			sel := stmt.(*ast.SelectStmt)
+			var expr ast.Expr = sel.Columns[0].(*ast.AliasedExpr).Expr
+			if sel.Where != nil {
+				expr = sel.Where.(*ast.BinaryExpr).Right
+			}
+			lit, ok := expr.(*ast.Literal)
+			if !ok || lit.Type != ast.LiteralString {
+				t.Fatalf("Expected a string literal, got %T", expr)
+			}
+			if lit.Value != want {
+				t.Errorf("Got %q, want %q", lit.Value, want)
 			}
 		})
 	}
+
+	// With StringContinuationNewline, strings merge only across a newline.
+	p := New("SELECT 'a'\n'b'\r\n  'c'")
+	p.StringContinuationNewline = true
+	stmt, err := p.Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if lit := stmt.(*ast.SelectStmt).Columns[0].(*ast.AliasedExpr).Expr.(*ast.Literal); lit.Value != "abc" {
+		t.Errorf("Got %q, want %q", lit.Value, "abc")
+	}
+	for _, input := range []string{"SELECT x FROM t WHERE y = 'a' 'b'", "SELECT 'a' /* c */ 'b' FROM t"} {
+		p := New(input)
+		p.StringContinuationNewline = true
+		if _, err := p.Parse(); err == nil {
+			t.Errorf("%q: expected an error", input)
+		}
+	}
 }
 
-func TestParseJoins(t *testing.T) {
-	tests := []string{
-		"SELECT * FROM a JOIN b ON a.id = b.a_id",
-		"SELECT * FROM a INNER JOIN b ON a.id = b.a_id",
-		"SELECT * FROM a LEFT JOIN b ON a.id = b.a_id",
-		"SELECT * FROM a LEFT OUTER JOIN b ON a.id = b.a_id",
-		"SELECT * FROM a RIGHT JOIN b ON a.id = b.a_id",
-		"SELECT * FROM a FULL OUTER JOIN b ON a.id = b.a_id",
-		"SELECT * FROM a CROSS JOIN b",
-		"SELECT * FROM a NATURAL JOIN b",
-		"SELECT * FROM a JOIN b USING (id)",
-		"SELECT * FROM a, b WHERE a.id = b.a_id",
+func TestParseLikeQuantified(t *testing.T) {
+	tests := []struct {
+		input      string
+		quantifier string
+		pattern    string // type of Pattern, empty when Patterns is set
+		patterns   int
+		not, ilike bool
+	}{
+		{"SELECT * FROM t WHERE a LIKE ANY (ARRAY['x%', 'y%'])", "ANY", "*ast.ArrayExpr", 0, false, false},
+		{"SELECT * FROM t WHERE a ILIKE ALL (SELECT p FROM pats)", "ALL", "*ast.Subquery", 0, false, true},
+		{"SELECT * FROM t WHERE a NOT LIKE SOME (patterns)", "SOME", "*ast.ColName", 0, true, false},
+		{"SELECT * FROM t WHERE a LIKE ANY ('x%', 'y%', 'z%')", "ANY", "", 3, false, false},
 	}
 
-	for _, input := range tests {
-		t.Run(input, func(t *testing.T) {
-			p := New(input)
-			stmt, err := p.Parse()
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			stmt, err := New(tt.input).Parse()
 			if err != nil {
 				t.Fatalf("Parse error: %v", err)
 			}
-			if stmt == nil {
-				t.Fatal("Expected statement, got nil")
+			like, ok := stmt.(*ast.SelectStmt).Where.(*ast.LikeExpr)
+			if !ok {
+				t.Fatalf("Expected LikeExpr, got %T", stmt.(*ast.SelectStmt).Where)
+			}
+			if like.Quantifier != tt.quantifier {
+				t.Errorf("Quantifier = %q, want %q", like.Quantifier, tt.quantifier)
+			}
+			if like.Not != tt.not || like.ILike != tt.ilike {
+				t.Errorf("Not = %v, ILike = %v", like.Not, like.ILike)
+			}
+			if got := fmt.Sprintf("%T", like.Pattern); tt.pattern != "" && got != tt.pattern {
+				t.Errorf("Pattern is %s, want %s", got, tt.pattern)
+			}
+			if len(like.Patterns) != tt.patterns {
+				t.Errorf("Got %d patterns, want %d", len(like.Patterns), tt.patterns)
 			}
 		})
 	}
-}
 
-func TestParseWithCTE(t *testing.T) {
-	input := `WITH active_users AS (
-		SELECT id, name FROM users WHERE status = 'active'
-	)
-	SELECT * FROM active_users WHERE name LIKE 'A%'`
+	if _, err := New("SELECT * FROM t WHERE a LIKE ANY ('x%',)").Parse(); err == nil {
+		t.Error("Expected an error for a trailing comma in the pattern list")
+	}
+}
 
-	p := New(input)
-	stmt, err := p.Parse()
+func TestParseStarModifiers(t *testing.T) {
+	stmt, err := New("SELECT * EXCEPT (a, b) REPLACE (x + 1 AS x, 'n' AS y), t.* EXCLUDE (c) FROM t").Parse()
 	if err != nil {
 		t.Fatalf("Parse error: %v", err)
 	}
+	cols := stmt.(*ast.SelectStmt).Columns
+	star := cols[0].(*ast.StarExpr)
+	if !slices.Equal(star.Except, []string{"a", "b"}) || star.Exclude {
+		t.Errorf("Expected EXCEPT (a, b), got %v", star.Except)
+	}
+	if len(star.Replace) != 2 || star.Replace[0].Column != "x" || star.Replace[1].Column != "y" {
+		t.Fatalf("Expected REPLACE of x and y, got %+v", star.Replace)
+	}
+	if _, ok := star.Replace[0].Expr.(*ast.BinaryExpr); !ok {
+		t.Errorf("Expected x + 1, got %T", star.Replace[0].Expr)
+	}
+	if qualified := cols[1].(*ast.StarExpr); !qualified.Exclude || qualified.TableName() != "t" {
+		t.Errorf("Expected t.* EXCLUDE (c), got %+v", qualified)
+	}
 
-	sel, ok := stmt.(*ast.SelectStmt)
-	if !ok {
-		t.Fatalf("Expected SelectStmt, got %T", stmt)
+	// EXCEPT without a list after the FROM clause stays a set operation.
+	if _, err := New("SELECT * FROM a EXCEPT SELECT * FROM b").Parse(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
 	}
 
-	if sel.With == nil {
-		t.Fatal("Expected WITH clause")
+	for _, input := range []string{
+		"SELECT * EXCEPT () FROM t",
+		"SELECT * REPLACE (x + 1) FROM t",
+	} {
+		if _, err := New(input).Parse(); err == nil {
+			t.Errorf("%s: expected error", input)
+		}
 	}
+}
 
-	if len(sel.With.CTEs) != 1 {
-		t.Errorf("Expected 1 CTE, got %d", len(sel.With.CTEs))
+func TestParseOracleOuterJoin(t *testing.T) {
+	stmt, err := New("SELECT * FROM a, b WHERE a.id = b.id(+) AND x(+) > 1").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	and := stmt.(*ast.SelectStmt).Where.(*ast.BinaryExpr)
+	eq := and.Left.(*ast.BinaryExpr)
+	if eq.Left.(*ast.ColName).OuterJoin {
+		t.Errorf("Expected a.id without outer join marker")
+	}
+	if col := eq.Right.(*ast.ColName); !col.OuterJoin || col.Name() != "id" {
+		t.Errorf("Expected b.id(+), got %+v", col)
+	}
+	if col := and.Right.(*ast.BinaryExpr).Left.(*ast.ColName); !col.OuterJoin {
+		t.Errorf("Expected x(+), got %+v", col)
 	}
 }
 
-func TestParseWindowFunctions(t *testing.T) {
-	tests := []string{
-		"SELECT ROW_NUMBER() OVER () FROM t",
-		"SELECT ROW_NUMBER() OVER (ORDER BY id) FROM t",
-		"SELECT ROW_NUMBER() OVER (PARTITION BY type ORDER BY id) FROM t",
-		"SELECT SUM(amount) OVER (PARTITION BY user_id) FROM orders",
-		"SELECT AVG(price) OVER (ORDER BY date ROWS BETWEEN 1 PRECEDING AND 1 FOLLOWING) FROM prices",
+func TestParseCompositeCasts(t *testing.T) {
+	tests := []struct {
+		input     string
+		exprType  string
+		schema    string
+		typeName  string
+		wantArray bool
+	}{
+		{"(1, 2)::point", "*ast.TupleExpr", "", "point", false},
+		{"ROW(1, 2)::my_composite", "*ast.FuncExpr", "", "my_composite", false},
+		{"x::record", "*ast.ColName", "", "record", false},
+		{"data::jsonb[]", "*ast.ColName", "", "jsonb", true},
+		{"y::myschema.addr[]", "*ast.ColName", "myschema", "addr", true},
+		{"CAST(y AS myschema.addr)", "*ast.ColName", "myschema", "addr", false},
 	}
 
-	for _, input := range tests {
-		t.Run(input, func(t *testing.T) {
-			p := New(input)
-			stmt, err := p.Parse()
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			stmt, err := New("SELECT " + tt.input).Parse()
 			if err != nil {
 				t.Fatalf("Parse error: %v", err)
 			}
-			if stmt == nil {
-				t.Fatal("Expected statement, got nil")
+			cast, ok := stmt.(*ast.SelectStmt).Columns[0].(*ast.AliasedExpr).Expr.(*ast.CastExpr)
+			if !ok {
+				t.Fatalf("Expected CastExpr")
+			}
+			if got := fmt.Sprintf("%T", cast.Expr); got != tt.exprType {
+				t.Errorf("Expected operand %s, got %s", tt.exprType, got)
+			}
+			if cast.Type.Schema != tt.schema || cast.Type.Name != tt.typeName || cast.Type.Array != tt.wantArray {
+				t.Errorf("Expected type %s.%s array=%v, got %+v", tt.schema, tt.typeName, tt.wantArray, cast.Type)
+			}
+			if want := !strings.HasPrefix(tt.input, "CAST"); cast.Postgres != want {
+				t.Errorf("Expected Postgres = %v", want)
 			}
 		})
 	}
 }
 
-func BenchmarkParse(b *testing.B) {
-	input := `SELECT u.id, u.name, COUNT(o.id) as order_count
-FROM users u
-LEFT JOIN orders o ON u.id = o.user_id
-WHERE u.status = 'active'
-  AND u.created_at BETWEEN '2024-01-01' AND '2024-12-31'
-GROUP BY u.id, u.name
-HAVING COUNT(o.id) > 5
-ORDER BY order_count DESC
-LIMIT 100`
+func TestParseCopy(t *testing.T) {
+	stmt, err := New("COPY t (a, b) FROM STDIN WITH (FORMAT csv, FORCE_NOT_NULL (a, b))").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	cp := stmt.(*ast.CopyStmt)
+	if cp.Table.Name() != "t" || len(cp.Columns) != 2 || !cp.From || !cp.Stdio {
+		t.Errorf("Unexpected COPY: %+v", cp)
+	}
+	if len(cp.Options) != 2 || cp.Options[0].Name != "FORMAT" || cp.Options[0].Value != "csv" ||
+		cp.Options[1].Value != "(a, b)" {
+		t.Errorf("Unexpected COPY options: %+v", cp.Options)
+	}
 
-	b.ReportAllocs()
-	b.ResetTimer()
+	stmt, err = New("COPY (SELECT a FROM t) TO '/tmp/out'").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	cp = stmt.(*ast.CopyStmt)
+	if _, ok := cp.Query.(*ast.SelectStmt); !ok || cp.From || cp.File != "/tmp/out" {
+		t.Errorf("Unexpected COPY: %+v", cp)
+	}
 
-	for i := 0; i < b.N; i++ {
-		p := New(input)
-		_, err := p.Parse()
-		if err != nil {
-			b.Fatal(err)
+	for _, input := range []string{"COPY t", "COPY t FROM", "COPY t TO PROGRAM"} {
+		if _, err := New(input).Parse(); err == nil {
+			t.Errorf("Expected error for %q", input)
 		}
 	}
 }
 
-func BenchmarkParseSimple(b *testing.B) {
-	input := "SELECT * FROM users WHERE id = 1"
+func TestParseMaintenance(t *testing.T) {
+	stmt, err := New("ANALYZE users").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	an, ok := stmt.(*ast.AnalyzeStmt)
+	if !ok || len(an.Tables) != 1 || an.Tables[0].Name() != "users" {
+		t.Errorf("Unexpected ANALYZE: %#v", stmt)
+	}
 
-	b.ReportAllocs()
-	b.ResetTimer()
+	stmt, err = New("EXPLAIN ANALYZE SELECT 1").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if ex, ok := stmt.(*ast.ExplainStmt); !ok || !ex.Analyze {
+		t.Errorf("Unexpected EXPLAIN: %#v", stmt)
+	}
 
-	for i := 0; i < b.N; i++ {
-		p := New(input)
-		_, err := p.Parse()
-		if err != nil {
-			b.Fatal(err)
-		}
+	stmt, err = New("VACUUM FULL a, b").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	vac, ok := stmt.(*ast.VacuumStmt)
+	if !ok || !vac.Full || len(vac.Tables) != 2 {
+		t.Errorf("Unexpected VACUUM: %#v", stmt)
+	}
+
+	if _, err := New("ANALYZE TABLE").Parse(); err == nil {
+		t.Error("Expected error for ANALYZE TABLE without a table")
+	}
+}
+
+func TestParseDatabaseAndSchema(t *testing.T) {
+	stmt, err := New("CREATE DATABASE IF NOT EXISTS shop CHARACTER SET utf8mb4 COLLATE utf8mb4_bin").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	db := stmt.(*ast.CreateDatabaseStmt)
+	if !db.IfNotExists || db.Name != "shop" || len(db.Options) != 2 ||
+		db.Options[0].Name != "CHARSET" || db.Options[1].Name != "COLLATE" {
+		t.Errorf("Unexpected CREATE DATABASE: %+v", db)
+	}
+
+	stmt, err = New("CREATE SCHEMA AUTHORIZATION joe").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if cs := stmt.(*ast.CreateSchemaStmt); cs.Name != "" || cs.Authorization != "joe" {
+		t.Errorf("Unexpected CREATE SCHEMA: %+v", cs)
+	}
+
+	stmt, err = New("DROP SCHEMA IF EXISTS a, b CASCADE").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if ds := stmt.(*ast.DropSchemaStmt); !ds.IfExists || len(ds.Names) != 2 || !ds.Cascade {
+		t.Errorf("Unexpected DROP SCHEMA: %+v", ds)
+	}
+
+	for _, input := range []string{"CREATE DATABASE", "DROP SCHEMA IF a", "CREATE SCHEMA s AUTHORIZATION"} {
+		if _, err := New(input).Parse(); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
+
+func TestParseShow(t *testing.T) {
+	tests := []struct {
+		input    string
+		kind     string
+		table    string
+		database string
+	}{
+		{"SHOW TABLES", "TABLES", "", ""},
+		{"SHOW FULL TABLES FROM shop LIKE 'u%'", "TABLES", "", "shop"},
+		{"SHOW COLUMNS FROM users", "COLUMNS", "users", ""},
+		{"SHOW INDEX FROM t IN shop", "INDEX", "t", "shop"},
+		{"SHOW CREATE TABLE users", "CREATE TABLE", "users", ""},
+		{"SHOW ENGINE INNODB STATUS", "ENGINE INNODB STATUS", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			stmt, err := New(tt.input).Parse()
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			show := stmt.(*ast.ShowStmt)
+			table := ""
+			if show.Table != nil {
+				table = show.Table.Name()
+			}
+			if show.Kind != tt.kind || table != tt.table || show.Database != tt.database {
+				t.Errorf("got kind=%q table=%q database=%q", show.Kind, table, show.Database)
+			}
+		})
+	}
+
+	if _, err := New("SHOW").Parse(); err == nil {
+		t.Error("Expected error for bare SHOW")
+	}
+}
+
+func TestParseSessionStatements(t *testing.T) {
+	stmts, err := New("USE shop; SET NAMES utf8mb4; SET search_path = app, public; SET @a = 1, @@session.sql_mode = 'ANSI'").ParseAll()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if len(stmts) != 4 {
+		t.Fatalf("Expected 4 statements, got %d", len(stmts))
+	}
+	if use, ok := stmts[0].(*ast.UseStmt); !ok || use.Database != "shop" {
+		t.Errorf("Unexpected USE: %#v", stmts[0])
+	}
+	if set := stmts[1].(*ast.SetSessionStmt); len(set.Vars) != 1 || set.Vars[0].Name != "NAMES" {
+		t.Errorf("Unexpected SET NAMES: %+v", set.Vars)
+	}
+	if set := stmts[2].(*ast.SetSessionStmt); len(set.Vars) != 1 || len(set.Vars[0].Values) != 2 {
+		t.Errorf("Expected one search_path var with two values: %+v", set.Vars)
+	}
+	set := stmts[3].(*ast.SetSessionStmt)
+	if len(set.Vars) != 2 || set.Vars[0].Name != "@a" || set.Vars[1].Name != "@@session.sql_mode" {
+		t.Errorf("Unexpected SET assignments: %+v", set.Vars)
+	}
+
+	stmt, err := New("SET LOCAL lock_timeout TO 5").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if set := stmt.(*ast.SetSessionStmt); set.Scope != "LOCAL" || !set.Vars[0].To {
+		t.Errorf("Unexpected SET LOCAL: %+v", set)
+	}
+
+	for _, input := range []string{"SET", "SET a", "USE"} {
+		if _, err := New(input).Parse(); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
+
+func TestParseCursorStatements(t *testing.T) {
+	stmts, err := New("DECLARE c BINARY SCROLL CURSOR FOR SELECT * FROM t FETCH FIRST 5 ROWS ONLY; FETCH ABSOLUTE -1 FROM c; FETCH c INTO a").ParseAll()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if len(stmts) != 3 {
+		t.Fatalf("Expected 3 statements, got %d", len(stmts))
+	}
+	decl, ok := stmts[0].(*ast.DeclareCursorStmt)
+	if !ok || decl.Name != "c" || !decl.Binary || decl.Scroll != "SCROLL" {
+		t.Fatalf("Unexpected DECLARE: %#v", stmts[0])
+	}
+	if sel, ok := decl.Query.(*ast.SelectStmt); !ok || sel.Limit == nil || sel.Limit.Count == nil {
+		t.Errorf("Expected the SELECT to keep its FETCH FIRST clause: %#v", decl.Query)
+	}
+	fetch := stmts[1].(*ast.FetchStmt)
+	if fetch.Direction != "ABSOLUTE" || fetch.Count == nil || fetch.FromWord != "FROM" || fetch.Cursor != "c" {
+		t.Errorf("Unexpected FETCH: %+v", fetch)
+	}
+	if fetch := stmts[2].(*ast.FetchStmt); fetch.Direction != "" || fetch.Cursor != "c" || len(fetch.Into) != 1 {
+		t.Errorf("Unexpected MySQL FETCH: %+v", fetch)
+	}
+
+	for _, input := range []string{"DECLARE c FOR SELECT 1", "DECLARE c CURSOR SELECT 1", "FETCH ABSOLUTE FROM c", "FETCH NEXT FROM"} {
+		if _, err := New(input).Parse(); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
+
+func TestParseOrderByDirection(t *testing.T) {
+	stmt, err := New("SELECT a FROM t ORDER BY a ASC, b, c DESC").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	items := stmt.(*ast.SelectStmt).OrderBy
+	want := []ast.SortDirection{ast.SortAsc, ast.SortDefault, ast.SortDesc}
+	for i, item := range items {
+		if item.Direction != want[i] {
+			t.Errorf("Item %d: expected direction %v, got %v", i, want[i], item.Direction)
+		}
+		if item.Desc != (want[i] == ast.SortDesc) {
+			t.Errorf("Item %d: Desc = %v", i, item.Desc)
+		}
+	}
+}
+
+func TestParseDefaultLiteral(t *testing.T) {
+	stmt, err := New("SELECT CASE WHEN x THEN DEFAULT ELSE NULL END FROM t").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	c := stmt.(*ast.SelectStmt).Columns[0].(*ast.AliasedExpr).Expr.(*ast.CaseExpr)
+	if lit := c.Whens[0].Result.(*ast.Literal); lit.Type != ast.LiteralDefault {
+		t.Errorf("Expected LiteralDefault for THEN DEFAULT, got %v", lit.Type)
+	}
+	if lit := c.Else.(*ast.Literal); lit.Type != ast.LiteralNull {
+		t.Errorf("Expected LiteralNull for ELSE NULL, got %v", lit.Type)
+	}
+}
+
+func TestParseOnConflictTarget(t *testing.T) {
+	input := "INSERT INTO t (a) VALUES (1) ON CONFLICT ((lower(email)), id) DO NOTHING"
+
+	stmt, err := New(input).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	oc := stmt.(*ast.InsertStmt).OnConflict
+	if oc == nil || len(oc.Columns) != 2 {
+		t.Fatalf("Expected 2 conflict targets, got %+v", oc)
+	}
+	if _, ok := oc.Columns[0].Expr.(*ast.ParenExpr); !ok {
+		t.Errorf("Expected expression target, got %T", oc.Columns[0].Expr)
+	}
+	if oc.Columns[1].Column != "id" {
+		t.Errorf("Expected column target id, got %q", oc.Columns[1].Column)
+	}
+}
+
+func TestParseOnConflictWhereClauses(t *testing.T) {
+	input := "INSERT INTO t (id) VALUES (1) ON CONFLICT (id) WHERE active DO UPDATE SET n = n + 1 WHERE n < 10"
+
+	stmt, err := New(input).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	oc := stmt.(*ast.InsertStmt).OnConflict
+	if oc.Where == nil {
+		t.Error("Expected conflict target WHERE")
+	}
+	if oc.UpdateWhere == nil {
+		t.Error("Expected DO UPDATE WHERE")
+	}
+	if oc.Where == oc.UpdateWhere {
+		t.Error("Expected distinct WHERE clauses")
+	}
+}
+
+func TestParseOnConflictExcluded(t *testing.T) {
+	input := "INSERT INTO t (id, name) VALUES (1, 'a') ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, n = t.n"
+
+	stmt, err := New(input).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	updates := stmt.(*ast.InsertStmt).OnConflict.Updates
+	if len(updates) != 2 {
+		t.Fatalf("Expected 2 updates, got %d", len(updates))
+	}
+	if col, ok := updates[0].Expr.(*ast.ColName); !ok || !col.IsExcluded() || col.Name() != "name" {
+		t.Errorf("Expected EXCLUDED.name reference, got %#v", updates[0].Expr)
+	}
+	if col, ok := updates[1].Expr.(*ast.ColName); !ok || col.IsExcluded() {
+		t.Errorf("Expected non-EXCLUDED reference, got %#v", updates[1].Expr)
+	}
+}
+
+func TestParseInsertFlagsWithReturning(t *testing.T) {
+	tests := []struct {
+		input       string
+		wantReplace bool
+		wantIgnore  bool
+	}{
+		{"REPLACE INTO t (a) VALUES (1) RETURNING id", true, false},
+		{"INSERT IGNORE INTO t (a) VALUES (1) RETURNING id", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			stmt, err := New(tt.input).Parse()
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			ins := stmt.(*ast.InsertStmt)
+			if ins.Replace != tt.wantReplace || ins.Ignore != tt.wantIgnore {
+				t.Errorf("Expected Replace=%v Ignore=%v, got Replace=%v Ignore=%v",
+					tt.wantReplace, tt.wantIgnore, ins.Replace, ins.Ignore)
+			}
+			if len(ins.Returning) != 1 {
+				t.Errorf("Expected 1 RETURNING expression, got %d", len(ins.Returning))
+			}
+		})
+	}
+}
+
+func TestParseMultiTableUpdate(t *testing.T) {
+	input := "UPDATE t1 JOIN t2 ON t1.id = t2.id SET t1.x = t2.y"
+
+	stmt, err := New(input).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	upd := stmt.(*ast.UpdateStmt)
+	join, ok := upd.Table.(*ast.JoinExpr)
+	if !ok {
+		t.Fatalf("Expected JoinExpr table, got %T", upd.Table)
+	}
+	if join.On == nil {
+		t.Error("Expected ON condition")
+	}
+	if len(upd.Set) != 1 || upd.Set[0].Column.Table() != "t1" {
+		t.Errorf("Expected SET t1.x, got %+v", upd.Set)
+	}
+}
+
+func TestParseDelete(t *testing.T) {
+	tests := []struct {
+		input    string
+		hasWhere bool
+	}{
+		{"DELETE FROM users WHERE id = 1", true},
+		{"DELETE FROM users", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			p := New(tt.input)
+			stmt, err := p.Parse()
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			del, ok := stmt.(*ast.DeleteStmt)
+			if !ok {
+				t.Fatalf("Expected DeleteStmt, got %T", stmt)
+			}
+			if (del.Where != nil) != tt.hasWhere {
+				t.Errorf("Expected hasWhere=%v, got %v", tt.hasWhere, del.Where != nil)
+			}
+		})
+	}
+}
+
+func TestParseMultiTableDelete(t *testing.T) {
+	tests := []struct {
+		input       string
+		wantTargets []string
+	}{
+		{"DELETE t1 FROM t1 JOIN t2 ON t1.id = t2.id WHERE t2.x = 1", []string{"t1"}},
+		{"DELETE t1, t2 FROM t1 JOIN t2 ON t1.id = t2.id", []string{"t1", "t2"}},
+		{"DELETE FROM t1 WHERE id = 1", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			stmt, err := New(tt.input).Parse()
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			del := stmt.(*ast.DeleteStmt)
+			if len(del.Targets) != len(tt.wantTargets) {
+				t.Fatalf("Expected %d targets, got %d", len(tt.wantTargets), len(del.Targets))
+			}
+			for i, target := range del.Targets {
+				if target.Name() != tt.wantTargets[i] {
+					t.Errorf("Target %d: expected %s, got %s", i, tt.wantTargets[i], target.Name())
+				}
+			}
+			if tt.wantTargets != nil {
+				if _, ok := del.Table.(*ast.JoinExpr); !ok {
+					t.Errorf("Expected JoinExpr FROM table, got %T", del.Table)
+				}
+			}
+		})
+	}
+
+	// A single qualified table without FROM keeps its alias.
+	for _, input := range []string{"DELETE s.t x WHERE x.a = 1", "DELETE s.t AS x WHERE x.a = 1"} {
+		stmt, err := New(input).Parse()
+		if err != nil {
+			t.Fatalf("%s: Parse error: %v", input, err)
+		}
+		del := stmt.(*ast.DeleteStmt)
+		aliased, ok := del.Table.(*ast.AliasedTableExpr)
+		if !ok || aliased.Alias != "x" || del.Targets != nil || del.Where == nil {
+			t.Fatalf("%s: expected s.t AS x, got %#v", input, del.Table)
+		}
+		if tn := aliased.Expr.(*ast.TableName); tn.Schema() != "s" || tn.Name() != "t" {
+			t.Errorf("%s: expected s.t, got %v", input, tn.Parts)
+		}
+	}
+}
+
+func TestParseCreateTable(t *testing.T) {
+	input := `CREATE TABLE users (
+		id INT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		email VARCHAR(255) UNIQUE,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`
+
+	p := New(input)
+	stmt, err := p.Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	create, ok := stmt.(*ast.CreateTableStmt)
+	if !ok {
+		t.Fatalf("Expected CreateTableStmt, got %T", stmt)
+	}
+
+	if create.Table.Name() != "users" {
+		t.Errorf("Expected table name 'users', got %s", create.Table.Name())
+	}
+
+	if len(create.Columns) != 4 {
+		t.Errorf("Expected 4 columns, got %d", len(create.Columns))
+	}
+}
+
+func TestParseTableOptions(t *testing.T) {
+	input := "CREATE TABLE t (id INT) COMMENT = 'users' ENGINE = InnoDB AUTO_INCREMENT = 5 DEFAULT CHARSET utf8mb4"
+
+	stmt, err := New(input).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	create := stmt.(*ast.CreateTableStmt)
+
+	want := []ast.Option{
+		{Name: "COMMENT", Value: "users", Quoted: true},
+		{Name: "ENGINE", Value: "InnoDB"},
+		{Name: "AUTO_INCREMENT", Value: "5"},
+		{Name: "CHARSET", Value: "utf8mb4", Default: true},
+	}
+	if len(create.Options) != len(want) {
+		t.Fatalf("Expected %d options, got %d", len(want), len(create.Options))
+	}
+	for i, opt := range create.Options {
+		if *opt != want[i] {
+			t.Errorf("Option %d: expected %+v, got %+v", i, want[i], *opt)
+		}
+	}
+}
+
+func TestParseTablespace(t *testing.T) {
+	stmt, err := New("CREATE TABLE t (id INT, CONSTRAINT pk PRIMARY KEY (id) USING INDEX TABLESPACE fast) WITH (fillfactor = 70) TABLESPACE pg_default").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	create := stmt.(*ast.CreateTableStmt)
+	if create.Tablespace != "pg_default" {
+		t.Errorf("Expected tablespace pg_default, got %q", create.Tablespace)
+	}
+	if ts := create.Constraints[0].IndexTablespace; ts != "fast" {
+		t.Errorf("Expected index tablespace fast, got %q", ts)
+	}
+
+	// MySQL: TABLESPACE among the table options, with optional STORAGE.
+	stmt, err = New("CREATE TABLE t (id INT) ENGINE = NDB TABLESPACE ts1 STORAGE DISK COMMENT = 'x'").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	create = stmt.(*ast.CreateTableStmt)
+	if create.Tablespace != "ts1" || create.Storage != "DISK" || len(create.Options) != 2 {
+		t.Errorf("Expected TABLESPACE ts1 STORAGE DISK between 2 options, got %q %q %d", create.Tablespace, create.Storage, len(create.Options))
+	}
+
+	stmt, err = New("CREATE INDEX i ON t (a) TABLESPACE fast WHERE a > 0").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if idx := stmt.(*ast.CreateIndexStmt); idx.Tablespace != "fast" || idx.Where == nil {
+		t.Errorf("Expected TABLESPACE fast before WHERE, got %q", idx.Tablespace)
+	}
+
+	for _, input := range []string{
+		"CREATE TABLE t (id INT) TABLESPACE",
+		"CREATE TABLE t (id INT, PRIMARY KEY (id) USING INDEX fast)",
+		"CREATE TABLE t (id INT) TABLESPACE ts STORAGE FLASH",
+	} {
+		if _, err := New(input).Parse(); err == nil {
+			t.Errorf("%s: expected error", input)
+		}
+	}
+}
+
+func TestParseIndexInclude(t *testing.T) {
+	stmt, err := New("CREATE UNIQUE INDEX idx ON t (a, lower(b)) INCLUDE (c, value) WHERE a > 0").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	idx := stmt.(*ast.CreateIndexStmt)
+	if len(idx.Columns) != 2 {
+		t.Errorf("Expected 2 key columns, got %d", len(idx.Columns))
+	}
+	if len(idx.Include) != 2 || idx.Include[0] != "c" || idx.Include[1] != "value" {
+		t.Errorf("Expected INCLUDE (c, value), got %v", idx.Include)
+	}
+	if idx.Where == nil {
+		t.Error("Expected WHERE after INCLUDE")
+	}
+
+	for _, input := range []string{
+		"CREATE INDEX idx ON t (a) INCLUDE ()",
+		"CREATE INDEX idx ON t (a) INCLUDE b",
+	} {
+		if _, err := New(input).Parse(); err == nil {
+			t.Errorf("%s: expected error", input)
+		}
+	}
+}
+
+func TestParseIndexColumnOptions(t *testing.T) {
+	stmt, err := New("CREATE INDEX idx ON t (name text_pattern_ops, created_at DESC NULLS LAST, lower(email) varchar_pattern_ops NULLS FIRST)").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	cols := stmt.(*ast.CreateIndexStmt).Columns
+	want := []ast.IndexColumn{
+		{Column: "name", OpClass: "text_pattern_ops"},
+		{Column: "created_at", Desc: true, Nulls: "LAST"},
+		{OpClass: "varchar_pattern_ops", Nulls: "FIRST"},
+	}
+	if len(cols) != len(want) {
+		t.Fatalf("Expected %d columns, got %d", len(want), len(cols))
+	}
+	for i, col := range cols {
+		got := *col
+		got.Expr = nil
+		if got != want[i] {
+			t.Errorf("Column %d: expected %+v, got %+v", i, want[i], got)
+		}
+	}
+	if cols[2].Expr == nil {
+		t.Error("Expected lower(email) expression")
+	}
+}
+
+func TestParseExpressions(t *testing.T) {
+	tests := []struct {
+		input string
+	}{
+		{"SELECT 1 + 2"},
+		{"SELECT a AND b OR c"},
+		{"SELECT a = 1 AND b = 2"},
+		{"SELECT a BETWEEN 1 AND 10"},
+		{"SELECT a IN (1, 2, 3)"},
+		{"SELECT a LIKE '%test%'"},
+		{"SELECT a IS NULL"},
+		{"SELECT a IS NOT NULL"},
+		{"SELECT CASE WHEN a = 1 THEN 'one' ELSE 'other' END"},
+		{"SELECT CAST(a AS INT)"},
+		{"SELECT COUNT(*)"},
+		{"SELECT SUM(amount)"},
+		{"SELECT a::int"},
+		{"SELECT a || b"},
+		{"SELECT COALESCE(a, b, c)"},
+		{"SELECT NULLIF(a, b)"},
+		{"SELECT EXISTS (SELECT 1 FROM t)"},
+		{"SELECT * FROM t WHERE a IN (SELECT id FROM t2)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			p := New(tt.input)
+			stmt, err := p.Parse()
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			if stmt == nil {
+				t.Fatal("Expected statement, got nil")
+			}
+		})
+	}
+}
+
+func TestParseJoins(t *testing.T) {
+	tests := []string{
+		"SELECT * FROM a JOIN b ON a.id = b.a_id",
+		"SELECT * FROM a INNER JOIN b ON a.id = b.a_id",
+		"SELECT * FROM a LEFT JOIN b ON a.id = b.a_id",
+		"SELECT * FROM a LEFT OUTER JOIN b ON a.id = b.a_id",
+		"SELECT * FROM a RIGHT JOIN b ON a.id = b.a_id",
+		"SELECT * FROM a FULL OUTER JOIN b ON a.id = b.a_id",
+		"SELECT * FROM a CROSS JOIN b",
+		"SELECT * FROM a NATURAL JOIN b",
+		"SELECT * FROM a JOIN b USING (id)",
+		"SELECT * FROM a, b WHERE a.id = b.a_id",
+	}
+
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			p := New(input)
+			stmt, err := p.Parse()
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			if stmt == nil {
+				t.Fatal("Expected statement, got nil")
+			}
+		})
+	}
+}
+
+func TestParseSemiAntiJoins(t *testing.T) {
+	tests := map[string]ast.JoinType{
+		"SELECT * FROM a LEFT SEMI JOIN b ON a.id = b.a_id":  ast.JoinLeftSemi,
+		"SELECT * FROM a SEMI JOIN b ON a.id = b.a_id":       ast.JoinLeftSemi,
+		"SELECT * FROM a LEFT ANTI JOIN b USING (id)":        ast.JoinLeftAnti,
+		"SELECT * FROM a ANTI JOIN b ON a.id = b.a_id":       ast.JoinLeftAnti,
+		"SELECT * FROM a RIGHT SEMI JOIN b ON a.id = b.a_id": ast.JoinRightSemi,
+		"SELECT * FROM a RIGHT ANTI JOIN b ON a.id = b.a_id": ast.JoinRightAnti,
+		"SELECT * FROM a LEFT JOIN b ON a.id = b.a_id":       ast.JoinLeft,
+	}
+
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			stmt, err := New(input).Parse()
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			join, ok := stmt.(*ast.SelectStmt).From.(*ast.JoinExpr)
+			if !ok {
+				t.Fatalf("Expected JoinExpr, got %T", stmt.(*ast.SelectStmt).From)
+			}
+			if join.Type != want {
+				t.Errorf("Got join type %v, want %v", join.Type, want)
+			}
+			if join.On == nil && join.Using == nil {
+				t.Error("Expected a join condition")
+			}
+		})
+	}
+}
+
+func TestParseWithCTE(t *testing.T) {
+	input := `WITH active_users AS (
+		SELECT id, name FROM users WHERE status = 'active'
+	)
+	SELECT * FROM active_users WHERE name LIKE 'A%'`
+
+	p := New(input)
+	stmt, err := p.Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	sel, ok := stmt.(*ast.SelectStmt)
+	if !ok {
+		t.Fatalf("Expected SelectStmt, got %T", stmt)
+	}
+
+	if sel.With == nil {
+		t.Fatal("Expected WITH clause")
+	}
+
+	if len(sel.With.CTEs) != 1 {
+		t.Errorf("Expected 1 CTE, got %d", len(sel.With.CTEs))
+	}
+}
+
+func TestParseWithKeywordNames(t *testing.T) {
+	stmt, err := New("WITH data (value, key) AS (SELECT 1, 2) SELECT value FROM data JOIN other USING (key)").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	sel := stmt.(*ast.SelectStmt)
+	cte := sel.With.CTEs[0]
+	if cte.Name != "data" {
+		t.Errorf("Expected CTE data, got %s", cte.Name)
+	}
+	if len(cte.Columns) != 2 || cte.Columns[0] != "value" || cte.Columns[1] != "key" {
+		t.Errorf("Expected columns [value key], got %v", cte.Columns)
+	}
+	if join := sel.From.(*ast.JoinExpr); len(join.Using) != 1 || join.Using[0] != "key" {
+		t.Errorf("Expected USING (key), got %v", join.Using)
+	}
+}
+
+func TestParseWithMaterialized(t *testing.T) {
+	stmt, err := New("WITH a AS MATERIALIZED (SELECT 1), b AS NOT MATERIALIZED (SELECT 2), c AS (SELECT 3) SELECT * FROM a, b, c").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	ctes := stmt.(*ast.SelectStmt).With.CTEs
+	if m := ctes[0].Materialized; m == nil || !*m {
+		t.Errorf("Expected a MATERIALIZED, got %v", m)
+	}
+	if m := ctes[1].Materialized; m == nil || *m {
+		t.Errorf("Expected b NOT MATERIALIZED, got %v", m)
+	}
+	if m := ctes[2].Materialized; m != nil {
+		t.Errorf("Expected c unspecified, got %v", *m)
+	}
+}
+
+func TestParseSelectWithoutFrom(t *testing.T) {
+	tests := []struct {
+		input   string
+		aliases []string
+	}{
+		{"SELECT 1 AS x, 2 AS y", []string{"x", "y"}},
+		{"SELECT 1 x, 2 y", []string{"x", "y"}},
+		{"SELECT 1 x, 2 y;", []string{"x", "y"}},
+		{"SELECT 1, 2 AS y", []string{"", "y"}},
+		{"SELECT 1 + 2 total, 'a' AS \"b c\"", []string{"total", "b c"}},
+		{"SELECT 1 AS value, 2 AS key", []string{"value", "key"}},
+		{"SELECT 1 x -- trailing", []string{"x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			stmt, err := New(tt.input).Parse()
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			sel := stmt.(*ast.SelectStmt)
+			if sel.From != nil {
+				t.Errorf("Expected no FROM, got %T", sel.From)
+			}
+			if len(sel.Columns) != len(tt.aliases) {
+				t.Fatalf("Expected %d columns, got %d", len(tt.aliases), len(sel.Columns))
+			}
+			for i, want := range tt.aliases {
+				if got := sel.Columns[i].(*ast.AliasedExpr).Alias; got != want {
+					t.Errorf("Column %d: expected alias %q, got %q", i, want, got)
+				}
+			}
+		})
+	}
+
+	for _, input := range []string{"SELECT 1 AS", "SELECT 1 AS;", "SELECT 1 AS FROM t"} {
+		if _, err := New(input).Parse(); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
+
+func TestParseBlobLiterals(t *testing.T) {
+	stmt, err := New("SELECT b'101', X'1F', b FROM t").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	cols := stmt.(*ast.SelectStmt).Columns
+	for i, want := range []string{"b'101'", "X'1F'"} {
+		lit, ok := cols[i].(*ast.AliasedExpr).Expr.(*ast.Literal)
+		if !ok || lit.Type != ast.LiteralBlob || lit.Value != want {
+			t.Errorf("Column %d: expected blob literal %s, got %#v", i, want, cols[i].(*ast.AliasedExpr).Expr)
+		}
+	}
+	if _, ok := cols[2].(*ast.AliasedExpr).Expr.(*ast.ColName); !ok {
+		t.Errorf("Expected column b, got %T", cols[2].(*ast.AliasedExpr).Expr)
+	}
+
+	if _, err := New("SELECT b'12'").Parse(); err == nil {
+		t.Error("Expected error for invalid bit string")
+	}
+}
+
+func TestParseTableStmt(t *testing.T) {
+	stmt, err := New("TABLE ONLY s.t ORDER BY a DESC LIMIT 5 OFFSET 2").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	ts, ok := stmt.(*ast.TableStmt)
+	if !ok {
+		t.Fatalf("Expected TableStmt, got %T", stmt)
+	}
+	if ts.Table.Name() != "t" || ts.Table.Schema() != "s" || !ts.Table.Only {
+		t.Errorf("Expected ONLY s.t, got %+v", ts.Table)
+	}
+	if len(ts.OrderBy) != 1 || !ts.OrderBy[0].Desc {
+		t.Errorf("Expected ORDER BY a DESC, got %v", ts.OrderBy)
+	}
+	if ts.Limit == nil || ts.Limit.Count == nil || ts.Limit.Offset == nil {
+		t.Errorf("Expected LIMIT and OFFSET, got %+v", ts.Limit)
+	}
+
+	for _, input := range []string{"TABLE", "TABLE t WHERE a = 1"} {
+		if _, err := New(input).Parse(); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
+
+func TestParseDollarQuoted(t *testing.T) {
+	stmt, err := New("SELECT $$a$$, $body$b$body$, 'c'").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	tests := []struct {
+		value  string
+		dollar bool
+		tag    string
+	}{
+		{"a", true, ""},
+		{"b", true, "body"},
+		{"c", false, ""},
+	}
+	for i, tt := range tests {
+		lit := stmt.(*ast.SelectStmt).Columns[i].(*ast.AliasedExpr).Expr.(*ast.Literal)
+		if lit.Value != tt.value || lit.DollarQuoted != tt.dollar || lit.DollarTag != tt.tag {
+			t.Errorf("Column %d: got %q dollar=%v tag=%q, want %q dollar=%v tag=%q",
+				i, lit.Value, lit.DollarQuoted, lit.DollarTag, tt.value, tt.dollar, tt.tag)
+		}
+	}
+}
+
+func TestParseCreateFunction(t *testing.T) {
+	stmt, err := New("CREATE OR REPLACE FUNCTION s.add(a INT, OUT b INT, INOUT INT DEFAULT 1) " +
+		"RETURNS SETOF INT AS $fn$ SELECT a; $fn$ LANGUAGE sql IMMUTABLE SECURITY DEFINER COST 10").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	cf, ok := stmt.(*ast.CreateFunctionStmt)
+	if !ok {
+		t.Fatalf("Expected CreateFunctionStmt, got %T", stmt)
+	}
+	if !cf.OrReplace || cf.Procedure || cf.Name.Name() != "add" || cf.Name.Schema() != "s" {
+		t.Errorf("Expected OR REPLACE FUNCTION s.add, got %+v", cf)
+	}
+	if len(cf.Args) != 3 {
+		t.Fatalf("Expected 3 args, got %d", len(cf.Args))
+	}
+	if a := cf.Args[1]; a.Mode != "OUT" || a.Name != "b" || a.Type.Name != "INT" {
+		t.Errorf("Expected OUT b INT, got %+v", a)
+	}
+	if a := cf.Args[2]; a.Mode != "INOUT" || a.Name != "" || a.Default == nil {
+		t.Errorf("Expected unnamed INOUT INT DEFAULT 1, got %+v", a)
+	}
+	if cf.Returns == nil || !cf.SetOf || cf.Language != "sql" {
+		t.Errorf("Expected RETURNS SETOF INT LANGUAGE sql, got %+v", cf)
+	}
+	if cf.Body == nil || cf.Body.Value != " SELECT a; " || cf.Body.DollarTag != "fn" {
+		t.Errorf("Expected dollar-quoted body, got %+v", cf.Body)
+	}
+	var opts []string
+	for _, opt := range cf.Options {
+		opts = append(opts, opt.Name+"="+opt.Value)
+	}
+	if got := strings.Join(opts, ","); got != "IMMUTABLE=,SECURITY DEFINER=,COST=10" {
+		t.Errorf("Expected options IMMUTABLE, SECURITY DEFINER, COST 10, got %s", got)
+	}
+
+	stmt, err = New("CREATE PROCEDURE p(x INT) DETERMINISTIC RETURN x + 1").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if cf := stmt.(*ast.CreateFunctionStmt); !cf.Procedure || cf.Returns != nil || cf.Return == nil {
+		t.Errorf("Expected PROCEDURE with RETURN expression, got %+v", cf)
+	}
+
+	stmt, err = New("CREATE FUNCTION f(n INT) RETURNS TABLE (a INT, b TEXT) AS 'SELECT n, ''x''' LANGUAGE sql").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	cf = stmt.(*ast.CreateFunctionStmt)
+	if cf.Returns != nil || len(cf.Table) != 2 || cf.Table[1].Name != "b" || cf.Table[1].Type.Name != "TEXT" {
+		t.Errorf("Expected RETURNS TABLE (a INT, b TEXT), got %+v", cf.Table)
+	}
+
+	for _, input := range []string{
+		"CREATE FUNCTION f",
+		"CREATE FUNCTION f() RETURNS INT AS SELECT 1",
+		"CREATE FUNCTION f() RETURNS TABLE () AS 'x'",
+		"CREATE FUNCTION f() RETURNS TABLE (a INT AS 'x'",
+		"CREATE OR REPLACE TABLE t (a INT)",
+		"CREATE FUNCTION f() RETURNS INT BEGIN RETURN 1; END",
+	} {
+		if _, err := New(input).Parse(); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
+
+func TestParseCreateSequence(t *testing.T) {
+	stmt, err := New("CREATE SEQUENCE IF NOT EXISTS s.seq AS bigint INCREMENT 5 " +
+		"MINVALUE -10 NO MAXVALUE START WITH 1 CACHE 20 NOCYCLE OWNED BY t.id").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	cs, ok := stmt.(*ast.CreateSequenceStmt)
+	if !ok {
+		t.Fatalf("Expected CreateSequenceStmt, got %T", stmt)
+	}
+	if !cs.IfNotExists || cs.Temporary || cs.Name.Name() != "seq" || cs.Name.Schema() != "s" {
+		t.Errorf("Expected IF NOT EXISTS s.seq, got %+v", cs)
+	}
+	var opts []string
+	for _, opt := range cs.Options {
+		opts = append(opts, opt.Name+"="+opt.Value)
+	}
+	want := "AS=bigint,INCREMENT BY=5,MINVALUE=-10,NO MAXVALUE=,START WITH=1,CACHE=20,NO CYCLE=,OWNED BY=t.id"
+	if got := strings.Join(opts, ","); got != want {
+		t.Errorf("Expected options %s, got %s", want, got)
+	}
+
+	stmt, err = New("CREATE TEMP SEQUENCE s").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if cs := stmt.(*ast.CreateSequenceStmt); !cs.Temporary {
+		t.Errorf("Expected TEMPORARY sequence, got %+v", cs)
+	}
+
+	for _, input := range []string{
+		"CREATE SEQUENCE",
+		"CREATE SEQUENCE s START WITH x",
+		"CREATE SEQUENCE s NO START",
+		"CREATE SEQUENCE s RESTART 1",
+	} {
+		if _, err := New(input).Parse(); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
+
+func TestParseDoubleQuoteIsString(t *testing.T) {
+	p := New(`SELECT "hello" FROM t WHERE name = "it's"`)
+	p.SetDoubleQuoteIsString(true)
+	stmt, err := p.Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	sel := stmt.(*ast.SelectStmt)
+	if lit, ok := sel.Columns[0].(*ast.AliasedExpr).Expr.(*ast.Literal); !ok || lit.Type != ast.LiteralString || lit.Value != "hello" {
+		t.Errorf("Expected string literal hello, got %#v", sel.Columns[0].(*ast.AliasedExpr).Expr)
+	}
+	if lit, ok := sel.Where.(*ast.BinaryExpr).Right.(*ast.Literal); !ok || lit.Value != "it's" {
+		t.Errorf("Expected string literal it's, got %#v", sel.Where.(*ast.BinaryExpr).Right)
+	}
+
+	stmt, err = New(`SELECT "hello" FROM t`).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if _, ok := stmt.(*ast.SelectStmt).Columns[0].(*ast.AliasedExpr).Expr.(*ast.ColName); !ok {
+		t.Errorf("Expected a quoted identifier by default")
+	}
+}
+
+func TestParseEndPos(t *testing.T) {
+	// rest is the input from the node's last token on.
+	tests := []struct {
+		input string
+		rest  string
+		node  func(ast.Statement) ast.Node
+	}{
+		{"SELECT a FROM t WHERE b = 1 ;", "1 ;", nil},
+		{"SELECT a FROM t ORDER BY a DESC LIMIT 5 -- c", "5 -- c", nil},
+		{"INSERT INTO t (a) VALUES (1, 2) ;", ") ;", nil},
+		{"UPDATE t SET a = 1 WHERE b IN (1, 2);", ");", nil},
+		{"DELETE FROM t WHERE a = 'x'\n", "'x'\n", nil},
+		{"CREATE TABLE t (a INT) ;", ") ;", nil},
+		{"DROP TABLE IF EXISTS t, u ;", "u ;", nil},
+		{"TABLE t ;", "t ;", nil},
+		{"SELECT CAST(a AS INT) FROM t", ") FROM t", selectColumn},
+		{"SELECT b::INT FROM t", "INT FROM t", selectColumn},
+		{"SELECT CASE WHEN a THEN 1 END FROM t", "END FROM t", selectColumn},
+		{"SELECT COUNT(*) FROM t", ") FROM t", selectColumn},
+		{"SELECT EXISTS (SELECT 1) FROM t", ") FROM t", selectColumn},
+		{"SELECT a BETWEEN 1 AND 2 FROM t", "2 FROM t", selectColumn},
+		{"SELECT a AS b FROM t", "b FROM t", func(s ast.Statement) ast.Node {
+			return s.(*ast.SelectStmt).Columns[0]
+		}},
+		{"SELECT a FROM s.t WHERE true", "t WHERE true", func(s ast.Statement) ast.Node {
+			return s.(*ast.SelectStmt).From
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			stmt, err := New(tt.input).Parse()
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			var node ast.Node = stmt
+			if tt.node != nil {
+				node = tt.node(stmt)
+			}
+			if got := tt.input[node.End().Offset:]; got != tt.rest {
+				t.Errorf("%T ends at %q, want %q", node, got, tt.rest)
+			}
+		})
+	}
+}
+
+func selectColumn(s ast.Statement) ast.Node {
+	return s.(*ast.SelectStmt).Columns[0].(*ast.AliasedExpr).Expr
+}
+
+func TestParseColumnComment(t *testing.T) {
+	stmt, err := New("CREATE TABLE t (id INT NOT NULL COMMENT 'the id' PRIMARY KEY, name TEXT) COMMENT='table'").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	ct := stmt.(*ast.CreateTableStmt)
+	if id := ct.Columns[0]; id.Comment != "the id" || len(id.Constraints) != 2 {
+		t.Errorf("Expected comment and 2 constraints on id, got %q and %d", id.Comment, len(id.Constraints))
+	}
+	if name := ct.Columns[1]; name.Comment != "" {
+		t.Errorf("Expected no comment on name, got %q", name.Comment)
+	}
+	if len(ct.Options) != 1 || ct.Options[0].Value != "table" {
+		t.Errorf("Expected table COMMENT option, got %+v", ct.Options)
+	}
+
+	if _, err := New("CREATE TABLE t (a INT COMMENT)").Parse(); err == nil {
+		t.Error("Expected error for COMMENT without a string")
+	}
+}
+
+func TestParseWindowFunctions(t *testing.T) {
+	tests := []string{
+		"SELECT ROW_NUMBER() OVER () FROM t",
+		"SELECT ROW_NUMBER() OVER (ORDER BY id) FROM t",
+		"SELECT ROW_NUMBER() OVER (PARTITION BY type ORDER BY id) FROM t",
+		"SELECT SUM(amount) OVER (PARTITION BY user_id) FROM orders",
+		"SELECT AVG(price) OVER (ORDER BY date ROWS BETWEEN 1 PRECEDING AND 1 FOLLOWING) FROM prices",
+	}
+
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			p := New(input)
+			stmt, err := p.Parse()
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			if stmt == nil {
+				t.Fatal("Expected statement, got nil")
+			}
+		})
+	}
+}
+
+func TestParseNullTreatment(t *testing.T) {
+	stmt, err := New("SELECT LAST_VALUE(x) IGNORE NULLS OVER (ORDER BY t), LAG(x, 1) RESPECT NULLS OVER (ORDER BY t), LAG(x) OVER (ORDER BY t) FROM tbl").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	cols := stmt.(*ast.SelectStmt).Columns
+	fn := cols[0].(*ast.AliasedExpr).Expr.(*ast.FuncExpr)
+	if !fn.IgnoreNulls || fn.RespectNulls || fn.Over == nil {
+		t.Errorf("Expected IGNORE NULLS OVER, got %+v", fn)
+	}
+	fn = cols[1].(*ast.AliasedExpr).Expr.(*ast.FuncExpr)
+	if fn.IgnoreNulls || !fn.RespectNulls || fn.Over == nil {
+		t.Errorf("Expected RESPECT NULLS OVER, got %+v", fn)
+	}
+	fn = cols[2].(*ast.AliasedExpr).Expr.(*ast.FuncExpr)
+	if fn.IgnoreNulls || fn.RespectNulls {
+		t.Errorf("Expected no null treatment, got %+v", fn)
+	}
+}
+
+func TestParseGroupingSets(t *testing.T) {
+	input := "SELECT a, GROUPING(a) FROM t GROUP BY GROUPING SETS ((a, b), a, ()) HAVING GROUPING(a) = 0"
+
+	stmt, err := New(input).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	sel := stmt.(*ast.SelectStmt)
+
+	gs, ok := sel.GroupBy[0].(*ast.GroupingSetExpr)
+	if !ok {
+		t.Fatalf("Expected GroupingSetExpr, got %T", sel.GroupBy[0])
+	}
+	if gs.Type != ast.GroupingSets {
+		t.Errorf("Expected GROUPING SETS, got %v", gs.Type)
+	}
+	wantLens := []int{2, 1, 0}
+	if len(gs.Sets) != len(wantLens) {
+		t.Fatalf("Expected %d sets, got %d", len(wantLens), len(gs.Sets))
+	}
+	for i, n := range wantLens {
+		if len(gs.Sets[i]) != n {
+			t.Errorf("Set %d: expected %d exprs, got %d", i, n, len(gs.Sets[i]))
+		}
+	}
+
+	fn := sel.Columns[1].(*ast.AliasedExpr).Expr.(*ast.FuncExpr)
+	if fn.Name != "GROUPING" || !fn.IsAggregate() {
+		t.Errorf("Expected GROUPING classified as aggregate, got %s (aggregate=%v)", fn.Name, fn.IsAggregate())
+	}
+	having := sel.Having.(*ast.BinaryExpr).Left.(*ast.FuncExpr)
+	if !having.IsAggregate() {
+		t.Error("Expected GROUPING in HAVING classified as aggregate")
+	}
+}
+
+func TestParseWindowFrameExclude(t *testing.T) {
+	tests := []struct {
+		exclude string
+		want    ast.FrameExclude
+	}{
+		{"", ast.ExcludeNone},
+		{" EXCLUDE CURRENT ROW", ast.ExcludeCurrentRow},
+		{" EXCLUDE GROUP", ast.ExcludeGroup},
+		{" EXCLUDE TIES", ast.ExcludeTies},
+		{" EXCLUDE NO OTHERS", ast.ExcludeNoOthers},
+	}
+
+	for _, tt := range tests {
+		input := "SELECT SUM(a) OVER (ORDER BY b ROWS BETWEEN 1 PRECEDING AND CURRENT ROW" + tt.exclude + ") FROM t"
+		t.Run(input, func(t *testing.T) {
+			stmt, err := New(input).Parse()
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			fn := stmt.(*ast.SelectStmt).Columns[0].(*ast.AliasedExpr).Expr.(*ast.FuncExpr)
+			if fn.Over == nil || fn.Over.Frame == nil {
+				t.Fatal("Expected window frame")
+			}
+			if fn.Over.Frame.Exclude != tt.want {
+				t.Errorf("Expected exclude %v, got %v", tt.want, fn.Over.Frame.Exclude)
+			}
+		})
+	}
+}
+
+// shape renders an expression tree as a parenthesized prefix form, e.g. (XOR a b).
+func shape(e ast.Expr) string {
+	switch n := e.(type) {
+	case *ast.BinaryExpr:
+		return "(" + n.Op.String() + " " + shape(n.Left) + " " + shape(n.Right) + ")"
+	case *ast.UnaryExpr:
+		return "(" + n.Op.String() + " " + shape(n.Operand) + ")"
+	case *ast.PostfixExpr:
+		return "(" + shape(n.Operand) + " " + n.Op.String() + ")"
+	case *ast.ParenExpr:
+		return shape(n.Expr)
+	case *ast.IsExpr:
+		return "(IS " + shape(n.Expr) + ")"
+	case *ast.BetweenExpr:
+		return "(BETWEEN " + shape(n.Expr) + " " + shape(n.Low) + " " + shape(n.High) + ")"
+	case *ast.InExpr:
+		return "(IN " + shape(n.Expr) + ")"
+	case *ast.LikeExpr:
+		return "(LIKE " + shape(n.Expr) + " " + shape(n.Pattern) + ")"
+	case *ast.PeriodExpr:
+		return "(" + n.Op.String() + " " + shape(n.Left) + " " + shape(n.Right) + ")"
+	case *ast.ColName:
+		return n.Name()
+	case *ast.Literal:
+		return n.Value
+	default:
+		return fmt.Sprintf("%T", e)
+	}
+}
+
+// parseExprShape parses "SELECT <expr>" and returns the shape of the expression.
+func parseExprShape(t *testing.T, expr string) string {
+	t.Helper()
+	stmt, err := New("SELECT " + expr).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	return shape(stmt.(*ast.SelectStmt).Columns[0].(*ast.AliasedExpr).Expr)
+}
+
+func TestParseXorPrecedence(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"a XOR b XOR c", "(XOR (XOR a b) c)"},
+		{"a ^ b", "(^ a b)"},
+		{"a XOR b AND c", "(XOR a (AND b c))"},
+		{"a OR b XOR c", "(OR a (XOR b c))"},
+		{"a ^ b XOR c ^ d", "(XOR (^ a b) (^ c d))"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := parseExprShape(t, tt.input); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseBitwisePrecedence(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"a | b & c << 2", "(| a (& b (<< c 2)))"},
+		{"a << 2 & b | c", "(| (& (<< a 2) b) c)"},
+		{"a | b | c", "(| (| a b) c)"},
+		{"a & b + c", "(& a (+ b c))"},
+		{"a << b + 1", "(<< a (+ b 1))"},
+		{"a ^ b & c", "(& (^ a b) c)"},
+		{"a * b ^ c", "(* a (^ b c))"},
+		{"a ^ b * c", "(* (^ a b) c)"},
+		{"a | b = c", "(= (| a b) c)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := parseExprShape(t, tt.input); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParsePredicatePrecedence(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"a + b BETWEEN 1 AND c * 2", "(BETWEEN (+ a b) 1 (* c 2))"},
+		{"a * b IN (1, 2)", "(IN (* a b))"},
+		{"a || b LIKE c", "(LIKE (|| a b) c)"},
+		{"a - b IS NULL", "(IS (- a b))"},
+		{"a = b IS TRUE", "(IS (= a b))"},
+		{"NOT a IS NULL", "(NOT (IS a))"},
+		{"a IS NULL AND b LIKE c", "(AND (IS a) (LIKE b c))"},
+		{"p + 1 IMMEDIATELY PRECEDES q AND r", "(AND (IMMEDIATELY PRECEDES (+ p 1) q) r)"},
+		{"(a, b) OVERLAPS (c, d)", "(OVERLAPS *ast.TupleExpr *ast.TupleExpr)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := parseExprShape(t, tt.input); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParsePostfixFactorial(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"5!", "(5 !)"},
+		{"a!!", "((a !) !)"},
+		{"-5!", "(- (5 !))"},
+		{"3! * 2", "(* (3 !) 2)"},
+		{"2 ^ 3!", "(^ 2 (3 !))"},
+		{"5!=3", "(!= 5 3)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := parseExprShape(t, tt.input); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := New("SELECT ! a").Parse(); err == nil {
+		t.Error("Expected error for prefix !")
+	}
+}
+
+func TestParseUniquePredicate(t *testing.T) {
+	stmt, err := New("SELECT * FROM t WHERE UNIQUE (SELECT a FROM u)").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	u, ok := stmt.(*ast.SelectStmt).Where.(*ast.UniqueExpr)
+	if !ok || u.Subquery == nil || u.Subquery.Select == nil {
+		t.Fatalf("Expected UniqueExpr, got %#v", stmt.(*ast.SelectStmt).Where)
+	}
+
+	if _, err := New("SELECT * FROM t WHERE UNIQUE (1)").Parse(); err == nil {
+		t.Error("Expected error for UNIQUE without a subquery")
+	}
+}
+
+func TestParseDistinctAggregateArgs(t *testing.T) {
+	stmt, err := New("SELECT COUNT(DISTINCT a, b + 1) FROM t").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	fn := stmt.(*ast.SelectStmt).Columns[0].(*ast.AliasedExpr).Expr.(*ast.FuncExpr)
+	if !fn.Distinct || len(fn.Args) != 2 {
+		t.Fatalf("Expected DISTINCT with 2 args, got distinct=%v args=%d", fn.Distinct, len(fn.Args))
+	}
+	if _, ok := fn.Args[1].(*ast.BinaryExpr); !ok {
+		t.Errorf("Expected second arg to be b + 1, got %#v", fn.Args[1])
+	}
+}
+
+func TestParseTypedLiteral(t *testing.T) {
+	stmt, err := New("SELECT a FROM t WHERE d > TIMESTAMP '2024-01-01 00:00:00'").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	cmp := stmt.(*ast.SelectStmt).Where.(*ast.BinaryExpr)
+	lit, ok := cmp.Right.(*ast.TypedLiteral)
+	if !ok || lit.Type != "TIMESTAMP" || lit.Value != "2024-01-01 00:00:00" {
+		t.Errorf("Expected TypedLiteral, got %#v", cmp.Right)
+	}
+
+	stmt, err = New("SELECT date FROM t").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if _, ok := stmt.(*ast.SelectStmt).Columns[0].(*ast.AliasedExpr).Expr.(*ast.ColName); !ok {
+		t.Error("Expected date without a string to stay a column")
+	}
+}
+
+func TestParseIntervalUnits(t *testing.T) {
+	tests := []struct {
+		input string
+		unit  string
+	}{
+		{"SELECT INTERVAL 1 day", "DAY"},
+		{"SELECT INTERVAL '1:30' hour_minute", "HOUR_MINUTE"},
+		{"SELECT INTERVAL '1 2:03' DAY TO minute", "DAY TO MINUTE"},
+		{"SELECT INTERVAL '1 day' FROM t", ""},
+		{"SELECT INTERVAL '1 day' AS d", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			stmt, err := New(tt.input).Parse()
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			expr := stmt.(*ast.SelectStmt).Columns[0].(*ast.AliasedExpr).Expr
+			if got := expr.(*ast.IntervalExpr).Unit; got != tt.unit {
+				t.Errorf("Expected unit %q, got %q", tt.unit, got)
+			}
+		})
+	}
+
+	for _, input := range []string{"SELECT INTERVAL 1 MONTH TO DAY", "SELECT INTERVAL 1 DAY TO"} {
+		if _, err := New(input).Parse(); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	input := `SELECT u.id, u.name, COUNT(o.id) as order_count
+FROM users u
+LEFT JOIN orders o ON u.id = o.user_id
+WHERE u.status = 'active'
+  AND u.created_at BETWEEN '2024-01-01' AND '2024-12-31'
+GROUP BY u.id, u.name
+HAVING COUNT(o.id) > 5
+ORDER BY order_count DESC
+LIMIT 100`
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		p := New(input)
+		_, err := p.Parse()
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseSimple(b *testing.B) {
+	input := "SELECT * FROM users WHERE id = 1"
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		p := New(input)
+		_, err := p.Parse()
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestParseErrorCategories(t *testing.T) {
+	tests := []struct {
+		input string
+		check func(err error) bool
+	}{
+		{"SELECT * FROM t WHERE (a = 1", func(err error) bool {
+			var e *UnexpectedTokenError
+			return errors.As(err, &e) && e.Expected == token.RPAREN && e.Got == token.EOF
+		}},
+		{"SELECT * FROM t t2 t3", func(err error) bool {
+			var e *UnexpectedTokenError
+			return errors.As(err, &e) && e.Expected == token.ILLEGAL && e.Got == token.IDENT && e.Value == "t3"
+		}},
+		{"SELECT 'abc", func(err error) bool {
+			var e *UnterminatedStringError
+			return errors.As(err, &e) && e.Value == "'abc"
+		}},
+		{`SELECT "abc FROM t`, func(err error) bool {
+			var e *UnterminatedStringError
+			return errors.As(err, &e)
+		}},
+		{"SELECT $$abc", func(err error) bool {
+			var e *UnterminatedStringError
+			return errors.As(err, &e) && e.Value == "$$abc"
+		}},
+		{"GRANT ALL ON t TO u", func(err error) bool {
+			var e *UnsupportedStatementError
+			return errors.As(err, &e) && e.Value == "GRANT"
+		}},
+		{"CREATE VIEW v AS SELECT 1", func(err error) bool {
+			var e *UnsupportedStatementError
+			return errors.As(err, &e) && e.Token == token.CREATE && e.Value == "VIEW"
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := New(tt.input).Parse()
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if _, ok := err.(ParseError); !ok {
+				t.Fatalf("error type = %T, want ParseError", err)
+			}
+			if !tt.check(err) {
+				t.Errorf("unexpected category %T: %v", errors.Unwrap(err), err)
+			}
+		})
+	}
+}
+
+func TestParseMaxDepth(t *testing.T) {
+	const n = 200000
+	tests := map[string]string{
+		"parentheses": "SELECT " + strings.Repeat("(", n) + "1" + strings.Repeat(")", n),
+		"unary":       "SELECT " + strings.Repeat("- ", n) + "1",
+		"not":         "SELECT * FROM t WHERE " + strings.Repeat("NOT ", n) + "a",
+		"subqueries":  strings.Repeat("SELECT * FROM (", n) + "SELECT 1" + strings.Repeat(") AS s", n),
+		"statements":  strings.Repeat("(", n) + "SELECT 1" + strings.Repeat(")", n),
+	}
+
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := New(input).Parse()
+			var e *DepthLimitExceededError
+			if !errors.As(err, &e) || e.Limit != DefaultMaxDepth {
+				t.Errorf("Expected DepthLimitExceededError, got %v", err)
+			}
+		})
+	}
+
+	nested := "SELECT " + strings.Repeat("(", 50) + "1" + strings.Repeat(")", 50)
+	if _, err := New(nested).Parse(); err != nil {
+		t.Errorf("Unexpected error under the default limit: %v", err)
+	}
+	p := New(nested)
+	p.MaxDepth = 20
+	if _, err := p.Parse(); err == nil {
+		t.Error("Expected error with MaxDepth 20")
+	}
+	p = New(nested)
+	p.MaxDepth = 0
+	if _, err := p.Parse(); err != nil {
+		t.Errorf("Unexpected error with the limit disabled: %v", err)
+	}
+}
+
+func TestParseLongBooleanChain(t *testing.T) {
+	const n = 50000
+	var b strings.Builder
+	b.WriteString("SELECT * FROM t WHERE ")
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b.WriteString(" AND ")
+		}
+		fmt.Fprintf(&b, "a%d = %d", i, i)
+	}
+
+	// Left-associative chains fold iteratively, so even a small depth
+	// limit is never reached.
+	p := New(b.String())
+	p.MaxDepth = 16
+	stmt, err := p.Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	terms := 1
+	expr := stmt.(*ast.SelectStmt).Where
+	for {
+		bin, ok := expr.(*ast.BinaryExpr)
+		if !ok || bin.Op != token.AND {
+			break
+		}
+		terms++
+		expr = bin.Left
+	}
+	if terms != n {
+		t.Errorf("Expected a left-deep chain of %d terms, got %d", n, terms)
+	}
+}
+
+func TestParseReservedWords(t *testing.T) {
+	p := New("CREATE TABLE docs (embedding VECTOR(3))")
+	p.SetReservedWords("vector")
+	stmt, err := p.Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	col := stmt.(*ast.CreateTableStmt).Columns[0]
+	if col.Type.Name != "VECTOR" || col.Type.Length == nil || *col.Type.Length != 3 {
+		t.Errorf("Got type %+v, want VECTOR(3)", col.Type)
+	}
+
+	// A reserved word is no longer an implicit alias.
+	p = New("SELECT a vector FROM t")
+	p.SetReservedWords("vector")
+	if _, err := p.Parse(); err == nil {
+		t.Error("Expected error for reserved word used as an alias")
+	}
+	if _, err := New("SELECT a vector FROM t").Parse(); err != nil {
+		t.Errorf("Unexpected error without reserved words: %v", err)
+	}
+
+	// Parsers with different keywords run concurrently.
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(reserve bool) {
+			defer wg.Done()
+			p := Get("SELECT a vector FROM t")
+			defer Put(p)
+			if reserve {
+				p.SetReservedWords("vector")
+			}
+			if _, err := p.Parse(); (err != nil) != reserve {
+				t.Errorf("reserve=%v: got error %v", reserve, err)
+			}
+		}(i%2 == 0)
+	}
+	wg.Wait()
+}
+
+// countdownContext reports cancellation after Err has been called n times.
+type countdownContext struct {
+	context.Context
+	n int
+}
+
+func (c *countdownContext) Err() error {
+	if c.n--; c.n < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestParseContext(t *testing.T) {
+	input := "SELECT " + strings.Repeat("a + ", 100000) + "a FROM t; SELECT 1"
+
+	ctx := &countdownContext{Context: context.Background(), n: 3}
+	stmt, err := New(input).ParseContext(ctx)
+	if !errors.Is(err, context.Canceled) || stmt != nil {
+		t.Errorf("Expected context.Canceled, got %v, %v", stmt, err)
+	}
+	ctx = &countdownContext{Context: context.Background(), n: 3}
+	if stmts, err := New(input).ParseAllContext(ctx); !errors.Is(err, context.Canceled) || stmts != nil {
+		t.Errorf("Expected context.Canceled from ParseAllContext, got %d statements, %v", len(stmts), err)
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := New("SELECT 1").ParseContext(cancelled); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled for a done context, got %v", err)
+	}
+
+	stmts, err := New(input).ParseAllContext(context.Background())
+	if err != nil || len(stmts) != 2 {
+		t.Errorf("Expected 2 statements, got %d, %v", len(stmts), err)
+	}
+	if _, err := New("SELECT * FROM").ParseContext(context.Background()); err == nil || errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a syntax error, got %v", err)
+	}
+}
+
+func TestParseExpectedTokens(t *testing.T) {
+	p := New("SELECT a ")
+	if _, err := p.Parse(); err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	got := p.Expected()
+	for _, want := range []token.Token{token.COMMA, token.AS, token.FROM, token.WHERE, token.GROUP, token.ORDER, token.LIMIT, token.UNION} {
+		if !slices.Contains(got, want) {
+			t.Errorf("Expected() = %v, missing %v", got, want)
+		}
+	}
+
+	tests := []struct {
+		input string
+		want  []token.Token
+	}{
+		{"SELECT a )", []token.Token{token.COMMA, token.FROM, token.WHERE}},
+		{"SELECT a FROM t WHERE", []token.Token{token.IDENT, token.INT, token.LPAREN, token.NOT}},
+		{"SELECT a FROM t GROUP x", []token.Token{token.BY}},
+		{"FROB t", []token.Token{token.SELECT, token.INSERT, token.WITH}},
+	}
+	for _, tt := range tests {
+		_, err := New(tt.input).Parse()
+		var pe ParseError
+		if !errors.As(err, &pe) {
+			t.Fatalf("%q: expected ParseError, got %v", tt.input, err)
+		}
+		for _, want := range tt.want {
+			if !slices.Contains(pe.Expected, want) {
+				t.Errorf("%q: Expected = %v, missing %v", tt.input, pe.Expected, want)
+			}
+		}
+	}
+}
+
+func TestParseLikeEmptyEscape(t *testing.T) {
+	stmt, err := New("SELECT * FROM t WHERE a LIKE 'x' ESCAPE ''").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	like := stmt.(*ast.SelectStmt).Where.(*ast.LikeExpr)
+	lit, ok := like.Escape.(*ast.Literal)
+	if !ok || lit.Type != ast.LiteralString || lit.Value != "" {
+		t.Errorf("Expected empty string escape, got %#v", like.Escape)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		input  string
+		format string // EXPLAIN format, empty for a plain SELECT
+	}{
+		{"SELECT FORMAT(x, 2) FROM t", ""},
+		{"SELECT format(x, 2, 'de_DE') FROM t", ""},
+		{"EXPLAIN FORMAT JSON SELECT FORMAT(x, 2) FROM t", "JSON"},
+		{"EXPLAIN FORMAT=TREE SELECT FORMAT(x, 2) FROM t", "TREE"},
+		{"EXPLAIN (ANALYZE, FORMAT JSON) SELECT FORMAT(x, 2) FROM t", "JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			stmt, err := New(tt.input).Parse()
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			if explain, ok := stmt.(*ast.ExplainStmt); ok {
+				if explain.Format != tt.format {
+					t.Errorf("Format = %q, want %q", explain.Format, tt.format)
+				}
+				stmt = explain.Stmt
+			} else if tt.format != "" {
+				t.Fatalf("Expected ExplainStmt, got %T", stmt)
+			}
+			fn, ok := stmt.(*ast.SelectStmt).Columns[0].(*ast.AliasedExpr).Expr.(*ast.FuncExpr)
+			if !ok {
+				t.Fatalf("Expected FuncExpr, got %T", stmt.(*ast.SelectStmt).Columns[0])
+			}
+			if !strings.EqualFold(fn.Name, "format") || len(fn.Args) < 2 {
+				t.Errorf("Expected FORMAT call with arguments, got %s with %d args", fn.Name, len(fn.Args))
+			}
+		})
+	}
+}
+
+func TestParseNamedArguments(t *testing.T) {
+	stmt, err := New("SELECT make_interval(1, days => 10, secs => 1.5), now() FROM t").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	cols := stmt.(*ast.SelectStmt).Columns
+	fn := cols[0].(*ast.AliasedExpr).Expr.(*ast.FuncExpr)
+	if len(fn.Args) != 3 || !slices.Equal(fn.ArgNames, []string{"", "days", "secs"}) {
+		t.Errorf("Expected 3 args named [\"\" days secs], got %d args named %q", len(fn.Args), fn.ArgNames)
+	}
+	if now := cols[1].(*ast.AliasedExpr).Expr.(*ast.FuncExpr); now.ArgNames != nil {
+		t.Errorf("Expected no argument names, got %q", now.ArgNames)
+	}
+
+	if _, err := New("SELECT f(a => ) FROM t").Parse(); err == nil {
+		t.Error("Expected an error for a named argument without a value")
+	}
+}
+
+func TestParseWithinGroup(t *testing.T) {
+	stmt, err := New("SELECT PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY total DESC) FILTER (WHERE ok), mode() WITHIN GROUP (ORDER BY x) FROM t").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	cols := stmt.(*ast.SelectStmt).Columns
+	fn := cols[0].(*ast.AliasedExpr).Expr.(*ast.FuncExpr)
+	if len(fn.WithinGroup) != 1 || !fn.WithinGroup[0].Desc || fn.Filter == nil {
+		t.Errorf("Expected WITHIN GROUP (ORDER BY total DESC) and FILTER, got %+v", fn)
+	}
+	if mode := cols[1].(*ast.AliasedExpr).Expr.(*ast.FuncExpr); len(mode.WithinGroup) != 1 {
+		t.Errorf("Expected WITHIN GROUP on MODE, got %+v", mode)
+	}
+
+	// Parsing is permissive; Validate rejects WITHIN GROUP on other functions.
+	if _, err := New("SELECT SUM(x) WITHIN GROUP (ORDER BY y) FROM t").Parse(); err != nil {
+		t.Errorf("Parse error: %v", err)
+	}
+	if _, err := New("SELECT PERCENTILE_CONT(0.5) WITHIN GROUP (x) FROM t").Parse(); err == nil {
+		t.Error("Expected an error for WITHIN GROUP without ORDER BY")
+	}
+}
+
+func TestParseTableSample(t *testing.T) {
+	stmt, err := New("SELECT * FROM t AS x TABLESAMPLE BERNOULLI (10.5) REPEATABLE (42) JOIN u SAMPLE (100 ROWS) ON u.id = x.id").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	join := stmt.(*ast.SelectStmt).From.(*ast.JoinExpr)
+	left := join.Left.(*ast.AliasedTableExpr)
+	if left.Alias != "x" || left.Sample == nil {
+		t.Fatalf("Expected alias x with a sample, got %+v", left)
+	}
+	if s := left.Sample; s.Keyword != "TABLESAMPLE" || s.Method != "BERNOULLI" || s.Repeatable == nil || s.Rows {
+		t.Errorf("Unexpected sample %+v", s)
+	}
+	right := join.Right.(*ast.AliasedTableExpr)
+	if s := right.Sample; s == nil || s.Keyword != "SAMPLE" || s.Method != "" || !s.Rows {
+		t.Errorf("Expected SAMPLE (100 ROWS), got %+v", s)
+	}
+
+	if _, err := New("SELECT * FROM t TABLESAMPLE SYSTEM").Parse(); err == nil {
+		t.Error("Expected an error for TABLESAMPLE without a size")
+	}
+}
+
+func TestParseKeywordFunctions(t *testing.T) {
+	stmt, err := New("SELECT LEFT(name, 3), RIGHT(name, 2), RANK() OVER (ORDER BY x), VALUES(b) FROM t LEFT JOIN u ON LEFT(t.a, 1) = u.b").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	sel := stmt.(*ast.SelectStmt)
+	want := []struct {
+		name string
+		args int
+	}{{"LEFT", 2}, {"RIGHT", 2}, {"RANK", 0}, {"VALUES", 1}}
+	for i, w := range want {
+		fn, ok := sel.Columns[i].(*ast.AliasedExpr).Expr.(*ast.FuncExpr)
+		if !ok {
+			t.Fatalf("Column %d: expected FuncExpr, got %T", i, sel.Columns[i].(*ast.AliasedExpr).Expr)
+		}
+		if fn.Name != w.name || len(fn.Args) != w.args {
+			t.Errorf("Column %d: got %s with %d args, want %s with %d", i, fn.Name, len(fn.Args), w.name, w.args)
+		}
+	}
+
+	join := sel.From.(*ast.JoinExpr)
+	if join.Type != ast.JoinLeft {
+		t.Errorf("Expected LEFT JOIN, got %v", join.Type)
+	}
+	if fn, ok := join.On.(*ast.BinaryExpr).Left.(*ast.FuncExpr); !ok || fn.Name != "LEFT" {
+		t.Errorf("Expected LEFT() in ON condition, got %T", join.On.(*ast.BinaryExpr).Left)
+	}
+}
+
+func TestParseStrict(t *testing.T) {
+	// Trailing input after complete DDL is an error in either mode.
+	trailing := map[string]int{
+		"CREATE TABLE t AS SELECT 1 FROM u junk(": 40,
+		"CREATE TABLE t (a INT) garbage":          24,
+		"CREATE INDEX i ON t (a) junk":            25,
+		"DROP TABLE t junk":                       14,
+		"ALTER TABLE t ADD COLUMN a INT junk":     32,
+		"TRUNCATE TABLE t junk":                   18,
+	}
+	for input, column := range trailing {
+		for _, strict := range []bool{false, true} {
+			p := New(input)
+			p.Strict = strict
+			_, err := p.Parse()
+			var pe ParseError
+			if !errors.As(err, &pe) {
+				t.Errorf("%q (strict %v): expected ParseError, got %v", input, strict, err)
+				continue
+			}
+			if pe.Pos.Column != column {
+				t.Errorf("%q (strict %v): error at column %d, want %d", input, strict, pe.Pos.Column, column)
+			}
+		}
+	}
+
+	// ParseAll requires separators only in strict mode.
+	p := New("SELECT 1 SELECT 2")
+	if stmts, err := p.ParseAll(); err != nil || len(stmts) != 2 {
+		t.Errorf("Expected two statements, got %d, %v", len(stmts), err)
+	}
+	p = New("SELECT 1 SELECT 2")
+	p.Strict = true
+	var pe ParseError
+	if _, err := p.ParseAll(); !errors.As(err, &pe) || pe.Pos.Column != 10 {
+		t.Errorf("Expected error at column 10, got %v", err)
+	}
+	p = New("SELECT 1; -- done\nSELECT 2;")
+	p.Strict = true
+	if stmts, err := p.ParseAll(); err != nil || len(stmts) != 2 {
+		t.Errorf("Expected two statements, got %d, %v", len(stmts), err)
+	}
+
+	// EXPLAIN options the AST does not model are rejected in strict mode.
+	p = New("EXPLAIN (ANALYZE false, VERBOSE, FORMAT JSON) SELECT 1")
+	p.Strict = true
+	stmt, err := p.Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if e := stmt.(*ast.ExplainStmt); e.Analyze || !e.Verbose || e.Format != "JSON" {
+		t.Errorf("Unexpected EXPLAIN options %+v", e)
+	}
+	if _, err := New("EXPLAIN (ANALYZE, COSTS OFF) SELECT 1").Parse(); err != nil {
+		t.Errorf("Parse error: %v", err)
+	}
+	for _, input := range []string{
+		"EXPLAIN (ANALYZE, COSTS OFF) SELECT 1",
+		"EXPLAIN (ANALYZE VERBOSE) SELECT 1",
+	} {
+		p = New(input)
+		p.Strict = true
+		if _, err := p.Parse(); err == nil {
+			t.Errorf("%q: expected an error in strict mode", input)
+		}
+	}
+
+	// Set operations are dropped leniently but rejected in strict mode.
+	if _, err := New("SELECT 1 UNION ALL SELECT 2").Parse(); err != nil {
+		t.Errorf("Parse error: %v", err)
+	}
+	p = New("SELECT 1 UNION ALL SELECT 2")
+	p.Strict = true
+	var unsupported *UnsupportedStatementError
+	if _, err := p.Parse(); !errors.As(err, &unsupported) || unsupported.Token != token.UNION {
+		t.Errorf("Expected UnsupportedStatementError at UNION, got %v", err)
+	}
+}
+
+func TestParseJSONOperators(t *testing.T) {
+	stmt, err := New("SELECT doc #> '{a,b}', doc #>> '{0,name}' FROM t WHERE doc ->> 'k' = 'x'").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	sel := stmt.(*ast.SelectStmt)
+	for i, op := range []token.Token{token.HASHGT, token.HASHDGT} {
+		bin, ok := sel.Columns[i].(*ast.AliasedExpr).Expr.(*ast.BinaryExpr)
+		if !ok || bin.Op != op {
+			t.Fatalf("Column %d: expected %v, got %#v", i, op, sel.Columns[i].(*ast.AliasedExpr).Expr)
+		}
+		if lit, ok := bin.Right.(*ast.Literal); !ok || lit.Type != ast.LiteralString {
+			t.Errorf("Column %d: expected a string path, got %T", i, bin.Right)
+		}
+	}
+
+	// The path operators bind tighter than comparison.
+	where := sel.Where.(*ast.BinaryExpr)
+	if left, ok := where.Left.(*ast.BinaryExpr); where.Op != token.EQ || !ok || left.Op != token.DARROW {
+		t.Errorf("Expected (doc ->> 'k') = 'x', got %#v", where)
+	}
+
+	// ... and looser than arithmetic.
+	stmt, err = New("SELECT doc -> 'a' + 1 FROM t").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	arrow := stmt.(*ast.SelectStmt).Columns[0].(*ast.AliasedExpr).Expr.(*ast.BinaryExpr)
+	if right, ok := arrow.Right.(*ast.BinaryExpr); arrow.Op != token.ARROW || !ok || right.Op != token.PLUS {
+		t.Errorf("Expected doc -> ('a' + 1), got %#v", arrow)
+	}
+}
+
+func TestParseExistenceOperators(t *testing.T) {
+	// PostgreSQL: ? after an operand is the JSONB/hstore existence operator.
+	stmt, err := New("SELECT doc ?| ARRAY['a', 'b'], doc ?& ARRAY['c'] FROM t WHERE doc ? 'key' AND n = ?").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	sel := stmt.(*ast.SelectStmt)
+	for i, op := range []token.Token{token.QUESTIONOR, token.QUESTIONAND} {
+		if bin, ok := sel.Columns[i].(*ast.AliasedExpr).Expr.(*ast.BinaryExpr); !ok || bin.Op != op {
+			t.Errorf("Column %d: expected %v, got %#v", i, op, sel.Columns[i].(*ast.AliasedExpr).Expr)
+		}
+	}
+	and := sel.Where.(*ast.BinaryExpr)
+	if exists, ok := and.Left.(*ast.BinaryExpr); !ok || exists.Op != token.QUESTION {
+		t.Errorf("Expected doc ? 'key', got %#v", and.Left)
+	}
+	// In a value position the same token is still a placeholder.
+	if _, ok := and.Right.(*ast.BinaryExpr).Right.(*ast.Param); !ok {
+		t.Errorf("Expected n = ? placeholder, got %#v", and.Right)
+	}
+
+	// MySQL-style placeholders are unaffected.
+	stmt, err = New("SELECT ? FROM t WHERE a IN (?, ?) LIMIT ?").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	sel = stmt.(*ast.SelectStmt)
+	in := sel.Where.(*ast.InExpr)
+	params := []ast.Expr{sel.Columns[0].(*ast.AliasedExpr).Expr, in.Values[0], in.Values[1], sel.Limit.Count}
+	for i, e := range params {
+		if _, ok := e.(*ast.Param); !ok {
+			t.Errorf("Placeholder %d: expected Param, got %T", i, e)
+		}
+	}
+}
+
+func TestParseTextSearchMatch(t *testing.T) {
+	// @@ binds like a comparison: looser than ||, tighter than AND.
+	stmt, err := New("SELECT * FROM t WHERE title || body @@ to_tsquery('x') AND id = 1").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	and, ok := stmt.(*ast.SelectStmt).Where.(*ast.BinaryExpr)
+	if !ok || and.Op != token.AND {
+		t.Fatalf("Expected AND at the top, got %#v", stmt.(*ast.SelectStmt).Where)
+	}
+	match, ok := and.Left.(*ast.BinaryExpr)
+	if !ok || match.Op != token.ATAT {
+		t.Fatalf("Expected @@, got %#v", and.Left)
+	}
+	if concat, ok := match.Left.(*ast.BinaryExpr); !ok || concat.Op != token.CONCAT {
+		t.Errorf("Expected title || body on the left of @@, got %#v", match.Left)
+	}
+	if _, ok := match.Right.(*ast.FuncExpr); !ok {
+		t.Errorf("Expected to_tsquery call on the right of @@, got %#v", match.Right)
+	}
+}
+
+func TestParseConvert(t *testing.T) {
+	stmt, err := New("SELECT CONVERT(name USING utf8mb4), CONVERT(x, DECIMAL(10, 2)), CONVERT(nvarchar(50), d, 120), convert('x', 'UTF8', 'LATIN1') FROM t").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	cols := stmt.(*ast.SelectStmt).Columns
+	expr := func(i int) ast.Expr { return cols[i].(*ast.AliasedExpr).Expr }
+
+	using, ok := expr(0).(*ast.ConvertExpr)
+	if !ok || using.Charset != "utf8mb4" || using.Type != nil {
+		t.Errorf("Expected CONVERT ... USING utf8mb4, got %#v", expr(0))
+	}
+
+	mysql, ok := expr(1).(*ast.ConvertExpr)
+	if !ok || mysql.TypeFirst || mysql.Type.Name != "DECIMAL" || *mysql.Type.Scale != 2 {
+		t.Errorf("Expected CONVERT(x, DECIMAL(10, 2)), got %#v", expr(1))
+	}
+
+	mssql, ok := expr(2).(*ast.ConvertExpr)
+	if !ok || !mssql.TypeFirst || mssql.Type.Name != "NVARCHAR" || *mssql.Type.Length != 50 {
+		t.Fatalf("Expected CONVERT(nvarchar(50), ...), got %#v", expr(2))
+	}
+	if col, ok := mssql.Expr.(*ast.ColName); !ok || col.Name() != "d" {
+		t.Errorf("Expected d as the converted expression, got %#v", mssql.Expr)
+	}
+	if lit, ok := mssql.Style.(*ast.Literal); !ok || lit.Value != "120" {
+		t.Errorf("Expected style 120, got %#v", mssql.Style)
+	}
+
+	// Other arities stay ordinary function calls.
+	if fn, ok := expr(3).(*ast.FuncExpr); !ok || fn.Name != "CONVERT" || len(fn.Args) != 3 {
+		t.Errorf("Expected convert() function call, got %#v", expr(3))
+	}
+}
+
+func TestParseOnly(t *testing.T) {
+	stmt, err := New("SELECT * FROM ONLY parent p JOIN child c ON p.id = c.id").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	join := stmt.(*ast.SelectStmt).From.(*ast.JoinExpr)
+	parent := join.Left.(*ast.AliasedTableExpr)
+	if tn := parent.Expr.(*ast.TableName); !tn.Only || tn.Name() != "parent" || parent.Alias != "p" {
+		t.Errorf("Expected ONLY parent p, got %#v", parent)
+	}
+	if tn := join.Right.(*ast.AliasedTableExpr).Expr.(*ast.TableName); tn.Only {
+		t.Errorf("Expected child without ONLY")
+	}
+
+	stmt, err = New("DELETE FROM ONLY t WHERE a = 1").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if tn := stmt.(*ast.DeleteStmt).Table.(*ast.TableName); !tn.Only || tn.Name() != "t" {
+		t.Errorf("Expected DELETE FROM ONLY t, got %#v", tn)
+	}
+
+	input := "SELECT * FROM ONLY (parent) WHERE a = 1"
+	stmt, err = New(input).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if tn := stmt.(*ast.SelectStmt).From.(*ast.TableName); !tn.Only || tn.Name() != "parent" || tn.End().Offset != strings.Index(input, ")") {
+		t.Errorf("Expected ONLY (parent), got %#v", tn)
+	}
+	if _, err := New("SELECT * FROM ONLY (parent").Parse(); err == nil {
+		t.Error("Expected error for unclosed ONLY (")
+	}
+
+	// ONLY before a clause keyword or the end is a table name.
+	for _, input := range []string{"SELECT * FROM only", "SELECT * FROM only WHERE a = 1"} {
+		stmt, err = New(input).Parse()
+		if err != nil {
+			t.Fatalf("%s: Parse error: %v", input, err)
+		}
+		if tn := stmt.(*ast.SelectStmt).From.(*ast.TableName); tn.Only || tn.Name() != "only" {
+			t.Errorf("%s: expected table only, got %#v", input, tn)
+		}
+	}
+}
+
+func TestStatementType(t *testing.T) {
+	tests := []struct {
+		input string
+		want  StmtType
+	}{
+		{"SELECT * FROM t", StmtSelect},
+		{"  -- leading comment\n/* block */ select 1", StmtSelect},
+		{"((SELECT 1) UNION (SELECT 2))", StmtSelect},
+		{"VALUES (1), (2)", StmtSelect},
+		{"TABLE t", StmtSelect},
+		{"INSERT INTO t VALUES (1)", StmtInsert},
+		{"REPLACE INTO t VALUES (1)", StmtInsert},
+		{"UPDATE t SET a = 1", StmtUpdate},
+		{"DELETE FROM t", StmtDelete},
+		{"CREATE TABLE t (id INT)", StmtDDL},
+		{"TRUNCATE t", StmtDDL},
+		{"EXPLAIN SELECT 1", StmtExplain},
+		{"SHOW TABLES", StmtShow},
+		{"SET NAMES utf8mb4", StmtSet},
+		{"USE db", StmtSet},
+		{"VACUUM t", StmtOther},
+		{"WITH a AS (SELECT 1), b (x) AS (SELECT 2) SELECT * FROM a, b", StmtSelect},
+		{"WITH RECURSIVE r AS (SELECT 1 UNION ALL SELECT n + 1 FROM r) INSERT INTO t SELECT * FROM r", StmtInsert},
+		{"WITH a AS (SELECT REPLACE(x, 'a', 'b') FROM t WHERE y IN (SELECT 1)) UPDATE t SET a = 1", StmtUpdate},
+		// A data-modifying CTE writes even though the statement is a SELECT.
+		{"WITH d AS (DELETE FROM t RETURNING *) SELECT * FROM d", StmtDelete},
+		{"WITH a AS (SELECT 1), u AS ( /* c */ UPDATE t SET a = 1 RETURNING a) SELECT * FROM u", StmtUpdate},
+		// Only the leading keywords are examined, so locking reads and
+		// EXPLAIN ANALYZE of a write are not classified as writes.
+		{"SELECT * FROM t WHERE id = 1 FOR UPDATE", StmtSelect},
+		{"EXPLAIN ANALYZE DELETE FROM t", StmtExplain},
+		{"SELECT FROM WHERE", StmtSelect},
+		{"-- nothing", StmtUnknown},
+	}
+	for _, tt := range tests {
+		got, err := New(tt.input).StatementType()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.input, tt.want, got)
+		}
+	}
+
+	for _, input := range []string{"GRANT ALL ON t TO u", "WITH a AS (SELECT 1)", "1 + 1"} {
+		if _, err := New(input).StatementType(); err == nil {
+			t.Errorf("%s: expected error", input)
+		}
+	}
+}
+
+func TestParseEmptyInList(t *testing.T) {
+	for _, input := range []string{
+		"SELECT * FROM t WHERE a IN ()",
+		"SELECT * FROM t WHERE a NOT IN ( )",
+	} {
+		_, err := New(input).Parse()
+		var pe ParseError
+		if !errors.As(err, &pe) {
+			t.Fatalf("%q: expected ParseError, got %v", input, err)
+		}
+		if pe.Message != "empty IN list" {
+			t.Errorf("%q: Message = %q, want %q", input, pe.Message, "empty IN list")
+		}
+		if want := strings.LastIndex(input, ")"); pe.Pos.Offset != want {
+			t.Errorf("%q: error at offset %d, want %d", input, pe.Pos.Offset, want)
+		}
+	}
+}
+
+func TestParseErrorMessage(t *testing.T) {
+	_, err := New("SELECT * FROM t WHERE (a = 1").Parse()
+	want := "line 1, column 29: expected ), got EOF"
+	if err == nil || err.Error() != want {
+		t.Errorf("got %v, want %q", err, want)
 	}
 }
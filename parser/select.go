@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"strings"
+
 	"github.com/freeeve/machparse/ast"
 	"github.com/freeeve/machparse/token"
 )
@@ -33,62 +35,62 @@ func (p *Parser) parseSelect() *ast.SelectStmt {
 	stmt.Columns = p.parseSelectExprs()
 
 	// Optional INTO clause (MySQL)
-	if p.curIs(token.INTO) {
+	if p.want(token.INTO) {
 		stmt.Into = p.parseSelectInto()
 	}
 
 	// FROM clause (optional for things like SELECT 1+1)
-	if p.curIs(token.FROM) {
+	if p.want(token.FROM) {
 		p.advance()
 		stmt.From = p.parseTableExpr()
 	}
 
 	// WHERE clause
-	if p.curIs(token.WHERE) {
+	if p.want(token.WHERE) {
 		p.advance()
 		stmt.Where = p.parseExpr()
 	}
 
 	// GROUP BY clause
-	if p.curIs(token.GROUP) {
+	if p.want(token.GROUP) {
 		p.advance()
 		if !p.expect(token.BY) {
 			return nil
 		}
-		stmt.GroupBy = p.parseExprList()
+		stmt.GroupBy = p.parseGroupByList()
 	}
 
 	// HAVING clause
-	if p.curIs(token.HAVING) {
+	if p.want(token.HAVING) {
 		p.advance()
 		stmt.Having = p.parseExpr()
 	}
 
 	// WINDOW clause
-	if p.curIs(token.WINDOW) {
+	if p.want(token.WINDOW) {
 		stmt.WindowDefs = p.parseWindowDefs()
 	}
 
 	// ORDER BY clause
-	if p.curIs(token.ORDER) {
+	if p.want(token.ORDER) {
 		stmt.OrderBy = p.parseOrderBy()
 	}
 
 	// LIMIT clause
-	if p.curIs(token.LIMIT) {
+	if p.want(token.LIMIT) {
 		stmt.Limit = p.parseLimit()
 	}
 
 	// OFFSET clause (PostgreSQL style without LIMIT)
-	if p.curIs(token.OFFSET) && stmt.Limit == nil {
+	if p.want(token.OFFSET) && stmt.Limit == nil {
 		stmt.Limit = &ast.Limit{StartPos: p.cur.Pos}
 		p.advance()
 		stmt.Limit.Offset = p.parseExpr()
-		stmt.Limit.EndPos = p.cur.Pos
+		stmt.Limit.EndPos = p.prevPos
 	}
 
 	// FETCH clause (SQL standard)
-	if p.curIs(token.FETCH) {
+	if p.want(token.FETCH) {
 		if stmt.Limit == nil {
 			stmt.Limit = &ast.Limit{StartPos: p.cur.Pos}
 		}
@@ -103,18 +105,18 @@ func (p *Parser) parseSelect() *ast.SelectStmt {
 		if p.curIs(token.ONLY) {
 			p.advance()
 		}
-		stmt.Limit.EndPos = p.cur.Pos
+		stmt.Limit.EndPos = p.prevPos
 	}
 
 	// FOR UPDATE/SHARE
-	if p.curIs(token.FOR) {
+	if p.want(token.FOR) {
 		stmt.Lock = p.parseLockClause()
 	}
 
-	stmt.EndPos = p.cur.Pos
+	stmt.EndPos = p.prevPos
 
 	// Check for set operations (UNION, INTERSECT, EXCEPT)
-	if p.curIs(token.UNION) || p.curIs(token.INTERSECT) || p.curIs(token.EXCEPT) {
+	if p.want(token.UNION) || p.want(token.INTERSECT) || p.want(token.EXCEPT) {
 		return p.parseSetOp(stmt)
 	}
 
@@ -131,7 +133,7 @@ func (p *Parser) parseSelectExprs() []ast.SelectExpr {
 			break
 		}
 		exprs = append(exprs, expr)
-		if !p.curIs(token.COMMA) {
+		if !p.want(token.COMMA) {
 			break
 		}
 		p.advance() // consume comma
@@ -147,7 +149,7 @@ func (p *Parser) parseSelectExpr() ast.SelectExpr {
 	// Check for *
 	if p.curIs(token.ASTERISK) {
 		p.advance()
-		return &ast.StarExpr{StartPos: pos, EndPos: pos}
+		return p.parseStarModifiers(&ast.StarExpr{StartPos: pos, EndPos: pos})
 	}
 
 	// Parse as expression with optional alias
@@ -157,35 +159,83 @@ func (p *Parser) parseSelectExpr() ast.SelectExpr {
 	}
 
 	// Check if it's table.* (parseExpr returns StarExpr for this)
-	if _, ok := expr.(*ast.StarExpr); ok {
-		return expr.(*ast.StarExpr)
+	if star, ok := expr.(*ast.StarExpr); ok {
+		return p.parseStarModifiers(star)
 	}
 
 	alias := ""
-	if p.curIs(token.AS) {
+	if p.want(token.AS) {
 		p.advance()
-		if !p.curIs(token.IDENT) && !p.curIs(token.STRING) {
+		// After AS any keyword but one starting a clause names the column
+		// (SELECT 1 AS value); EOF and ; are neither and report an error.
+		if !(p.curIsIdent() && !isClauseKeyword(p.cur.Type)) && !p.curIs(token.STRING) {
 			p.errorf("expected alias after AS")
 			return nil
 		}
-		alias = p.cur.Value
+		alias = p.curIdentValue()
 		p.advance()
 	} else if p.curIs(token.IDENT) {
-		// Check if this looks like an alias (not a keyword that starts a clause)
-		if !isClauseKeyword(p.cur.Type) {
-			alias = p.cur.Value
-			p.advance()
-		}
+		// Without AS only a plain identifier is an alias: keywords lex as
+		// their own tokens, so clause keywords, EOF and ; never match.
+		alias = p.cur.Value
+		p.advance()
 	}
 
 	ae := ast.GetAliasedExpr()
 	ae.StartPos = pos
-	ae.EndPos = p.cur.Pos
+	ae.EndPos = p.prevPos
 	ae.Expr = expr
 	ae.Alias = alias
 	return ae
 }
 
+// parseStarModifiers parses the EXCEPT/EXCLUDE and REPLACE lists that may
+// follow a star in a select list (BigQuery, DuckDB). EXCEPT only counts
+// when followed by a parenthesized list, since it is otherwise a set
+// operation.
+func (p *Parser) parseStarModifiers(star *ast.StarExpr) ast.SelectExpr {
+	if (p.curIs(token.EXCEPT) || p.curIsWord("EXCLUDE")) && p.peekIs(token.LPAREN) {
+		star.Exclude = !p.curIs(token.EXCEPT)
+		p.advance()
+		star.Except = p.parseColumnNameList()
+		if len(star.Except) == 0 {
+			p.errorf("expected column name")
+			return nil
+		}
+		star.EndPos = p.prevPos
+	}
+
+	if p.curIs(token.REPLACE) && p.peekIs(token.LPAREN) {
+		p.advance()
+		p.advance()
+		for {
+			expr := p.parseExpr()
+			if expr == nil {
+				return nil
+			}
+			if !p.expect(token.AS) {
+				return nil
+			}
+			if !p.curIsIdent() {
+				p.errorf("expected column name after AS")
+				return nil
+			}
+			star.Replace = append(star.Replace, ast.ReplaceItem{Expr: expr, Column: p.cur.Value})
+			p.advance()
+			if !p.curIs(token.COMMA) {
+				break
+			}
+			p.advance()
+		}
+		star.EndPos = p.cur.Pos
+		if !p.expect(token.RPAREN) {
+			return nil
+		}
+	}
+
+	return star
+}
+
 func (p *Parser) parseSelectInto() *ast.SelectInto {
 	p.advance() // consume INTO
 
@@ -263,7 +313,7 @@ func (p *Parser) parseTableExpr() ast.TableExpr {
 			}
 		}
 
-		join.EndPos = p.cur.Pos
+		join.EndPos = p.prevPos
 		left = join
 	}
 
@@ -271,6 +321,11 @@ func (p *Parser) parseTableExpr() ast.TableExpr {
 }
 
 func (p *Parser) parseTablePrimary() ast.TableExpr {
+	if !p.enter() {
+		return nil
+	}
+	defer p.leave()
+
 	var expr ast.TableExpr
 
 	// Check for LATERAL
@@ -302,34 +357,42 @@ func (p *Parser) parseTablePrimary() ast.TableExpr {
 				p.errorf("expected SELECT statement in subquery")
 				return nil
 			}
-			expr = &ast.Subquery{StartPos: pos, EndPos: p.cur.Pos, Select: sel}
+			expr = &ast.Subquery{StartPos: pos, EndPos: p.prevPos, Select: sel}
 		} else {
 			// Parenthesized table expression
 			inner := p.parseTableExpr()
 			if !p.expect(token.RPAREN) {
 				return nil
 			}
-			expr = &ast.ParenTableExpr{StartPos: pos, EndPos: p.cur.Pos, Expr: inner}
+			expr = &ast.ParenTableExpr{StartPos: pos, EndPos: p.prevPos, Expr: inner}
 		}
+	} else if p.curIs(token.VALUES) {
+		// Checked before identifiers since VALUES is a keyword
+		expr = p.parseValuesClause()
 	} else if p.curIsIdent() {
-		tn := p.parseTableName()
+		tn := p.parseTableNameOnly()
 		if tn == nil {
 			return nil
 		}
 		expr = tn
-	} else if p.curIs(token.VALUES) {
-		expr = p.parseValuesClause()
 	} else {
 		p.errorf("expected table name or subquery")
 		return nil
 	}
 
+	return p.parseTableAlias(expr, lateral)
+}
+
+// parseTableAlias parses what may follow a table reference: an alias,
+// column aliases, index hints and a TABLESAMPLE clause.
+func (p *Parser) parseTableAlias(expr ast.TableExpr, lateral bool) ast.TableExpr {
 	// Parse optional alias
 	alias := ""
-	if p.curIs(token.AS) {
+	hasAs := p.curIs(token.AS)
+	if hasAs {
 		p.advance()
 	}
-	if p.curIs(token.IDENT) && !isClauseKeyword(p.cur.Type) {
+	if p.curIs(token.IDENT) && (hasAs || !p.curIsSemiAntiJoin() && !p.curIsTableSample()) {
 		alias = p.cur.Value
 		p.advance()
 	}
@@ -347,13 +410,22 @@ func (p *Parser) parseTablePrimary() ast.TableExpr {
 		hints = append(hints, p.parseIndexHint())
 	}
 
-	if alias != "" || len(hints) > 0 || lateral {
+	var sample *ast.TableSample
+	if p.curIsTableSample() || p.curIs(token.SAMPLE) {
+		sample = p.parseTableSample()
+		if sample == nil {
+			return nil
+		}
+	}
+
+	if alias != "" || len(hints) > 0 || lateral || sample != nil {
 		aliased := ast.GetAliasedTableExpr()
 		aliased.StartPos = expr.Pos()
-		aliased.EndPos = p.cur.Pos
+		aliased.EndPos = p.prevPos
 		aliased.Expr = expr
 		aliased.Alias = alias
 		aliased.Hints = hints
+		aliased.Sample = sample
 		if lateral {
 			if join, ok := expr.(*ast.JoinExpr); ok {
 				join.Lateral = true
@@ -365,42 +437,104 @@ func (p *Parser) parseTablePrimary() ast.TableExpr {
 	return expr
 }
 
+// curIsTableSample reports whether the current word starts a TABLESAMPLE
+// clause: TABLESAMPLE followed by a sampling method or its parenthesized
+// size. TABLESAMPLE is not a keyword, so elsewhere it is a name.
+func (p *Parser) curIsTableSample() bool {
+	if !p.curIsWord("TABLESAMPLE") {
+		return false
+	}
+	next := p.peek().Type
+	return next == token.LPAREN || next == token.IDENT || next.IsKeyword() && !isClauseKeyword(next)
+}
+
+// parseTableSample parses a TABLESAMPLE or SAMPLE clause after a table
+// reference and its alias.
+func (p *Parser) parseTableSample() *ast.TableSample {
+	sample := &ast.TableSample{StartPos: p.cur.Pos, Keyword: strings.ToUpper(p.cur.Value)}
+	p.advance() // consume TABLESAMPLE or SAMPLE
+
+	if p.curIsIdent() {
+		sample.Method = strings.ToUpper(p.cur.Value)
+		p.advance()
+	}
+
+	if !p.expect(token.LPAREN) {
+		return nil
+	}
+	sample.Size = p.parseExpr()
+	if sample.Size == nil {
+		return nil
+	}
+	if p.curIs(token.PERCENT_KW) {
+		sample.Percent = true
+		p.advance()
+	} else if p.curIs(token.ROWS) {
+		sample.Rows = true
+		p.advance()
+	}
+	if !p.expect(token.RPAREN) {
+		return nil
+	}
+
+	if p.curIs(token.REPEATABLE) || p.curIs(token.SEED) {
+		repeatable := p.curIs(token.REPEATABLE)
+		p.advance()
+		if !p.expect(token.LPAREN) {
+			return nil
+		}
+		seed := p.parseExpr()
+		if seed == nil || !p.expect(token.RPAREN) {
+			return nil
+		}
+		if repeatable {
+			sample.Repeatable = seed
+		} else {
+			sample.Seed = seed
+		}
+	}
+	return sample
+}
+
 func (p *Parser) parseValuesClause() *ast.ValuesStmt {
 	pos := p.cur.Pos
 	p.advance() // consume VALUES
 
 	stmt := &ast.ValuesStmt{StartPos: pos}
+	if !p.curIs(token.LPAREN) {
+		p.errorf("expected ( after VALUES")
+		return stmt
+	}
+	stmt.Rows = p.parseValuesList()
+	stmt.EndPos = p.prevPos
+	return stmt
+}
 
-	for {
-		if !p.expect(token.LPAREN) {
-			break
-		}
-
-		var row []ast.Expr
-		for {
-			expr := p.parseExpr()
-			if expr == nil {
-				break
-			}
-			row = append(row, expr)
-			if !p.curIs(token.COMMA) {
-				break
-			}
-			p.advance()
-		}
-		stmt.Rows = append(stmt.Rows, row)
+// parseTableStmt parses TABLE t with an optional ORDER BY, LIMIT and
+// OFFSET.
+func (p *Parser) parseTableStmt() *ast.TableStmt {
+	stmt := &ast.TableStmt{StartPos: p.cur.Pos}
+	p.advance() // consume TABLE
 
-		if !p.expect(token.RPAREN) {
-			break
-		}
+	stmt.Table = p.parseTableNameOnly()
+	if stmt.Table == nil {
+		return nil
+	}
 
-		if !p.curIs(token.COMMA) {
-			break
-		}
+	if p.want(token.ORDER) {
+		stmt.OrderBy = p.parseOrderBy()
+	}
+	if p.want(token.LIMIT) {
+		stmt.Limit = p.parseLimit()
+	}
+	if p.want(token.OFFSET) && stmt.Limit == nil {
+		stmt.Limit = &ast.Limit{StartPos: p.cur.Pos}
 		p.advance()
+		stmt.Limit.Offset = p.parseExpr()
+		stmt.Limit.EndPos = p.prevPos
 	}
 
-	stmt.EndPos = p.cur.Pos
+	stmt.EndPos = p.prevPos
 	return stmt
 }
 
@@ -481,9 +615,11 @@ func (p *Parser) parseOrderBy() []*ast.OrderByExpr {
 		item.Expr = expr
 
 		if p.curIs(token.ASC) {
+			item.Direction = ast.SortAsc
 			p.advance()
 		} else if p.curIs(token.DESC) {
 			item.Desc = true
+			item.Direction = ast.SortDesc
 			p.advance()
 		}
 
@@ -501,7 +637,7 @@ func (p *Parser) parseOrderBy() []*ast.OrderByExpr {
 			}
 		}
 
-		item.EndPos = p.cur.Pos
+		item.EndPos = p.prevPos
 		items = append(items, item)
 
 		if !p.curIs(token.COMMA) {
@@ -532,7 +668,7 @@ func (p *Parser) parseLimit() *ast.Limit {
 		limit.Count = p.parseExpr()
 	}
 
-	limit.EndPos = p.cur.Pos
+	limit.EndPos = p.prevPos
 	return limit
 }
 
@@ -596,6 +732,10 @@ func (p *Parser) parseSetOp(left *ast.SelectStmt) *ast.SelectStmt {
 	// return a SetOp node, but for now we'll just parse the right side
 	// and return the left
 	for p.curIs(token.UNION) || p.curIs(token.INTERSECT) || p.curIs(token.EXCEPT) {
+		if p.Strict {
+			p.unsupportedf(p.cur.Type, "set operation %v is not supported", p.cur.Type)
+			return left
+		}
 		p.advance()
 		if p.curIs(token.ALL) || p.curIs(token.DISTINCT) {
 			p.advance()
@@ -624,9 +764,30 @@ func (p *Parser) checkJoinKeyword() (ast.JoinType, bool, bool) {
 	case token.INNER:
 		return ast.JoinInner, natural, true
 	case token.LEFT:
+		switch {
+		case p.peekIsWord("SEMI"):
+			return ast.JoinLeftSemi, false, true
+		case p.peekIsWord("ANTI"):
+			return ast.JoinLeftAnti, false, true
+		}
 		return ast.JoinLeft, natural, true
 	case token.RIGHT:
+		switch {
+		case p.peekIsWord("SEMI"):
+			return ast.JoinRightSemi, false, true
+		case p.peekIsWord("ANTI"):
+			return ast.JoinRightAnti, false, true
+		}
 		return ast.JoinRight, natural, true
+	case token.IDENT:
+		// Spark SQL: SEMI JOIN is LEFT SEMI JOIN
+		if p.curIsSemiAntiJoin() {
+			if p.curIsWord("SEMI") {
+				return ast.JoinLeftSemi, false, true
+			}
+			return ast.JoinLeftAnti, false, true
+		}
+		return 0, false, false
 	case token.FULL:
 		return ast.JoinFull, natural, true
 	case token.CROSS:
@@ -648,11 +809,17 @@ func (p *Parser) consumeJoinKeywords() {
 	for p.curIs(token.NATURAL) || p.curIs(token.INNER) || p.curIs(token.LEFT) ||
 		p.curIs(token.RIGHT) || p.curIs(token.FULL) || p.curIs(token.OUTER) ||
 		p.curIs(token.CROSS) || p.curIs(token.JOIN) || p.curIs(token.STRAIGHT_JOIN) ||
-		p.curIs(token.COMMA) {
+		p.curIsSemiAntiJoin() || p.curIs(token.COMMA) {
 		p.advance()
 	}
 }
 
+// curIsSemiAntiJoin reports whether the current word is SEMI or ANTI
+// before JOIN. They are not keywords, so elsewhere they are names.
+func (p *Parser) curIsSemiAntiJoin() bool {
+	return (p.curIsWord("SEMI") || p.curIsWord("ANTI")) && p.peekIs(token.JOIN)
+}
+
 func isClauseKeyword(t token.Token) bool {
 	switch t {
 	case token.FROM, token.WHERE, token.GROUP, token.HAVING, token.ORDER,
@@ -666,3 +833,101 @@ func isClauseKeyword(t token.Token) bool {
 		return false
 	}
 }
+
+// parseGroupByList parses GROUP BY items, including ROLLUP, CUBE, and
+// GROUPING SETS.
+func (p *Parser) parseGroupByList() []ast.Expr {
+	slicePtr := ast.GetExprSlice()
+	exprs := *slicePtr
+	for {
+		expr := p.parseGroupingElement()
+		if expr == nil {
+			break
+		}
+		exprs = append(exprs, expr)
+		if !p.curIs(token.COMMA) {
+			break
+		}
+		p.advance()
+	}
+	return exprs
+}
+
+// parseGroupingElement parses a single GROUP BY item. GROUPING SETS,
+// ROLLUP and CUBE are not keywords and are only recognized here, so they
+// remain usable as names.
+func (p *Parser) parseGroupingElement() ast.Expr {
+	pos := p.cur.Pos
+	var typ ast.GroupingSetType
+
+	switch {
+	case p.curIsWord("GROUPING") && p.peekIsWord("SETS"):
+		p.advance() // consume GROUPING
+		typ = ast.GroupingSets
+	case (p.curIsWord("ROLLUP") || p.curIsWord("CUBE")) && p.peekIs(token.LPAREN):
+		typ = ast.Rollup
+		if p.curIsWord("CUBE") {
+			typ = ast.Cube
+		}
+	default:
+		return p.parseExpr()
+	}
+	p.advance() // consume SETS, ROLLUP, or CUBE
+
+	gs := &ast.GroupingSetExpr{StartPos: pos, Type: typ}
+	if !p.expect(token.LPAREN) {
+		return nil
+	}
+	for {
+		set, ok := p.parseGroupingSet(typ == ast.GroupingSets)
+		if !ok {
+			return nil
+		}
+		gs.Sets = append(gs.Sets, set)
+		if !p.curIs(token.COMMA) {
+			break
+		}
+		p.advance()
+	}
+	if !p.expect(token.RPAREN) {
+		return nil
+	}
+	gs.EndPos = p.prevPos
+	return gs
+}
+
+// parseGroupingSet parses one grouping list: a bare expression or a
+// parenthesized, possibly empty, expression list. Within GROUPING SETS,
+// elements may themselves be ROLLUP, CUBE, or GROUPING SETS.
+func (p *Parser) parseGroupingSet(nested bool) ([]ast.Expr, bool) {
+	parseElem := p.parseExpr
+	if nested {
+		parseElem = p.parseGroupingElement
+	}
+
+	if !p.curIs(token.LPAREN) {
+		expr := parseElem()
+		if expr == nil {
+			return nil, false
+		}
+		return []ast.Expr{expr}, true
+	}
+
+	p.advance() // consume '('
+	set := []ast.Expr{}
+	for !p.curIs(token.RPAREN) {
+		expr := parseElem()
+		if expr == nil {
+			return nil, false
+		}
+		set = append(set, expr)
+		if !p.curIs(token.COMMA) {
+			break
+		}
+		p.advance()
+	}
+	if !p.expect(token.RPAREN) {
+		return nil, false
+	}
+	return set, true
+}
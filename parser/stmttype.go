@@ -0,0 +1,135 @@
+package parser
+
+import "github.com/freeeve/machparse/token"
+
+// StmtType classifies a statement by what it does, for routing queries
+// without parsing them.
+type StmtType int
+
+const (
+	StmtUnknown StmtType = iota
+	StmtSelect           // SELECT, VALUES, TABLE and parenthesized queries, including locking reads
+	StmtInsert           // INSERT and REPLACE
+	StmtUpdate
+	StmtDelete
+	StmtDDL     // CREATE, ALTER, DROP and TRUNCATE
+	StmtExplain // EXPLAIN, including EXPLAIN ANALYZE of a write
+	StmtShow    // SHOW
+	StmtSet     // SET and USE, which change session state
+	StmtOther   // COPY, VACUUM, ANALYZE, FETCH and DECLARE
+)
+
+func (t StmtType) String() string {
+	switch t {
+	case StmtSelect:
+		return "SELECT"
+	case StmtInsert:
+		return "INSERT"
+	case StmtUpdate:
+		return "UPDATE"
+	case StmtDelete:
+		return "DELETE"
+	case StmtDDL:
+		return "DDL"
+	case StmtExplain:
+		return "EXPLAIN"
+	case StmtShow:
+		return "SHOW"
+	case StmtSet:
+		return "SET"
+	case StmtOther:
+		return "OTHER"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// StatementType classifies the first statement in the input from its
+// leading keywords, without building an AST. It skips comments and
+// opening parentheses, and looks past a WITH clause to the statement it
+// introduces; a WITH clause with a data-modifying common table expression
+// is classified by that expression, since the statement writes even when
+// it ends in a SELECT. The rest of the statement is not checked, so input
+// StatementType accepts may still fail to parse. Input holding only
+// comments returns StmtUnknown and a nil error, as Parse returns nil.
+//
+// Because only the leading keywords are read, two kinds of statement that
+// take locks or change data are not classified as writes: SELECT ... FOR
+// UPDATE or FOR SHARE is StmtSelect, and EXPLAIN
+// ANALYZE, which runs the statement it explains, is StmtExplain even when
+// that statement is an INSERT, UPDATE or DELETE. Callers routing reads to
+// a replica must treat StmtExplain as a possible write and parse a
+// StmtSelect to check SelectStmt.Lock when locking reads matter.
+func (p *Parser) StatementType() (StmtType, error) {
+	p.skipComments()
+	for p.curIs(token.LPAREN) {
+		p.advance()
+		p.skipComments()
+	}
+
+	switch p.cur.Type {
+	case token.EOF:
+		return StmtUnknown, nil
+	case token.WITH:
+		return p.withStatementType()
+	case token.SELECT, token.VALUES, token.TABLE:
+		return StmtSelect, nil
+	case token.INSERT, token.REPLACE:
+		return StmtInsert, nil
+	case token.UPDATE:
+		return StmtUpdate, nil
+	case token.DELETE:
+		return StmtDelete, nil
+	case token.CREATE, token.ALTER, token.DROP, token.TRUNCATE:
+		return StmtDDL, nil
+	case token.EXPLAIN:
+		return StmtExplain, nil
+	case token.SET, token.USE:
+		return StmtSet, nil
+	case token.COPY, token.VACUUM, token.ANALYZE, token.FETCH:
+		return StmtOther, nil
+	}
+	if p.curIsWord("SHOW") {
+		return StmtShow, nil
+	}
+	if p.curIsWord("DECLARE") {
+		return StmtOther, nil
+	}
+	p.wantAny(statementStart...)
+	p.unsupportedf(p.cur.Type, "unexpected token %v at start of statement", p.cur.Type)
+	return StmtUnknown, p.errors[0]
+}
+
+// withStatementType scans past a WITH clause to the first statement
+// keyword outside parentheses. A common table expression whose body
+// starts with INSERT, UPDATE or DELETE takes precedence over it.
+func (p *Parser) withStatementType() (StmtType, error) {
+	modifying := StmtUnknown
+	depth, opened := 0, false
+	for p.advance(); !p.curIs(token.EOF); p.advance() {
+		if p.curIs(token.COMMENT) {
+			continue
+		}
+		switch p.cur.Type {
+		case token.SELECT, token.INSERT, token.REPLACE, token.UPDATE, token.DELETE:
+			if depth == 0 {
+				if modifying != StmtUnknown {
+					return modifying, nil
+				}
+				return p.StatementType()
+			}
+			// REPLACE after a parenthesis is the string function; MySQL
+			// has no data-modifying common table expressions.
+			if opened && modifying == StmtUnknown && !p.curIs(token.SELECT) && !p.curIs(token.REPLACE) {
+				modifying, _ = p.StatementType()
+			}
+		case token.LPAREN:
+			depth++
+		case token.RPAREN:
+			depth--
+		}
+		opened = p.curIs(token.LPAREN)
+	}
+	p.errorf("expected SELECT, INSERT, UPDATE, or DELETE after WITH")
+	return StmtUnknown, p.errors[0]
+}
@@ -16,6 +16,7 @@ TESTS=(
     "FuzzWalk"
     "FuzzRewrite"
     "FuzzFormat"
+    "FuzzStringLiteral"
     "FuzzPooling"
     "FuzzDialects"
 )
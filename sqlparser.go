@@ -30,6 +30,10 @@
 package machparse
 
 import (
+	"context"
+	"io"
+	"strings"
+
 	"github.com/freeeve/machparse/ast"
 	"github.com/freeeve/machparse/format"
 	"github.com/freeeve/machparse/parser"
@@ -56,6 +60,83 @@ func ParseAll(sql string) ([]ast.Statement, error) {
 	return stmts, err
 }
 
+// ParseAllWithSpans is like ParseAll but pairs each statement with the
+// byte range [Start, End) it occupies in sql, including the semicolon that
+// ends it, e.g. to find the statement under an editor's cursor.
+func ParseAllWithSpans(sql string) ([]StatementSpan, error) {
+	p := parser.Get(sql)
+	spans, err := p.ParseAllWithSpans()
+	parser.Put(p)
+	return spans, err
+}
+
+// ParseContext is like Parse but returns ctx.Err() if ctx is cancelled
+// before parsing finishes. Cancellation is checked cooperatively every few
+// hundred tokens, bounding the time spent on large or adversarial input.
+func ParseContext(ctx context.Context, sql string) (ast.Statement, error) {
+	p := parser.Get(sql)
+	stmt, err := p.ParseContext(ctx)
+	parser.Put(p)
+	return stmt, err
+}
+
+// ParseAllContext is like ParseAll but returns ctx.Err() if ctx is
+// cancelled before parsing finishes.
+func ParseAllContext(ctx context.Context, sql string) ([]ast.Statement, error) {
+	p := parser.Get(sql)
+	stmts, err := p.ParseAllContext(ctx)
+	parser.Put(p)
+	return stmts, err
+}
+
+// StatementType classifies the first statement in sql as a SELECT,
+// INSERT, UPDATE, DELETE, DDL and so on from its leading keywords, without
+// parsing the rest. It is much cheaper than Parse for routing queries,
+// e.g. splitting reads from writes, but does not validate the statement.
+// SELECT ... FOR UPDATE is StmtSelect and EXPLAIN ANALYZE of a write is
+// StmtExplain; see parser.Parser.StatementType.
+func StatementType(sql string) (StmtType, error) {
+	p := parser.Get(sql)
+	typ, err := p.StatementType()
+	parser.Put(p)
+	return typ, err
+}
+
+// Minify parses the statements in sql and formats them back in compact
+// form: comments and line breaks are dropped, tokens are separated by
+// single spaces, and parentheses are kept only where operator precedence
+// needs them, so WHERE ((a = 1) OR (b = 2)) becomes WHERE a = 1 OR b = 2.
+// Keywords are uppercased as by String; casing doesn't change the length.
+// Statements are joined by semicolons.
+//
+// Set operations (UNION, INTERSECT and EXCEPT, including in subqueries)
+// are not supported: the AST doesn't model them, so Minify returns an
+// *UnsupportedStatementError rather than dropping their other arms. Input
+// is parsed in strict mode, so other constructs the AST would drop are
+// errors as well.
+func Minify(sql string) (string, error) {
+	p := parser.Get(sql)
+	p.Strict = true
+	stmts, err := p.ParseAll()
+	parser.Put(p)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	f := format.Get(format.Options{Uppercase: true, MinimalParens: true})
+	for i, stmt := range stmts {
+		if i > 0 {
+			b.WriteByte(';')
+		}
+		f.Reset()
+		f.Format(visitor.StripParens(stmt))
+		b.WriteString(f.String())
+	}
+	format.Put(f)
+	return b.String(), nil
+}
+
 // Repool returns AST nodes to internal pools for reuse.
 // This is optional - if not called, nodes are garbage collected normally.
 // Calling Repool after you're done with a statement improves performance
@@ -78,19 +159,104 @@ func String(node ast.Node) string {
 	return format.String(node)
 }
 
+// Fprint formats an AST node to w without building the whole SQL string,
+// for streaming large generated statements to a file or connection.
+func Fprint(w io.Writer, node ast.Node) error {
+	return format.Fprint(w, node)
+}
+
 // Walk traverses the AST calling the function for each node.
-// If the function returns false, children are not visited.
+// If the function returns false, children are not visited; the walk
+// continues with the node's siblings. Use WalkErr to stop the walk.
 func Walk(node ast.Node, fn func(ast.Node) bool) {
 	visitor.WalkFunc(node, fn)
 }
 
+// WalkErr traverses the AST like Walk until the function returns an
+// error. Returning SkipChildren skips the node's children and SkipAll
+// stops the walk; WalkErr returns any other error.
+func WalkErr(node ast.Node, fn func(ast.Node) error) error {
+	return visitor.WalkErr(node, fn)
+}
+
+// Sentinel errors for WalkErr.
+var (
+	SkipChildren = visitor.SkipChildren
+	SkipAll      = visitor.SkipAll
+)
+
 // Rewrite traverses the AST allowing node replacement.
 // The function is called in post-order (children first, then parent).
 // Return the replacement node or the original to keep it.
+//
+// Rewrite modifies node in place; the returned root shares structure with
+// it. Use RewriteCopy when node must stay unchanged, e.g. when it is a
+// cached statement rewritten per request.
 func Rewrite(node ast.Node, fn func(ast.Node) ast.Node) ast.Node {
 	return visitor.Rewrite(node, fn)
 }
 
+// RewriteCopy is like Rewrite but rewrites a deep copy of node, leaving
+// node untouched.
+func RewriteCopy(node ast.Node, fn func(ast.Node) ast.Node) ast.Node {
+	return visitor.RewriteCopy(node, fn)
+}
+
+// Clone returns a deep copy of node.
+func Clone(node ast.Node) ast.Node {
+	return ast.Clone(node)
+}
+
+// ExtractColumns returns every column reference in node, including those in
+// RETURNING clauses.
+func ExtractColumns(node ast.Node) []*ast.ColName {
+	return visitor.ExtractColumns(node)
+}
+
+// OutputColumns returns the select list of a SELECT or the RETURNING list
+// of an INSERT, UPDATE or DELETE.
+func OutputColumns(stmt Statement) []ast.SelectExpr {
+	return visitor.OutputColumns(stmt)
+}
+
+// CaseBranches returns the operand of a simple CASE followed by its THEN
+// and ELSE results, for type inference and lineage.
+func CaseBranches(c *ast.CaseExpr) []ast.Expr {
+	return visitor.CaseBranches(c)
+}
+
+// ReplaceTable renames every reference to table oldName as newName,
+// including column qualifiers that name the table rather than an alias.
+// References to a CTE of the same name are left alone.
+// Like Rewrite, it modifies stmt in place.
+func ReplaceTable(stmt Statement, oldName, newName string) Statement {
+	return visitor.ReplaceTable(stmt, oldName, newName).(Statement)
+}
+
+// ExpandStars replaces * and qualified stars in select and RETURNING lists
+// with the columns listed for each table in schema, resolving table
+// aliases: SELECT u.* FROM users u expands to u.id, u.name, ... Include
+// generated columns in schema for RETURNING * to list them. Like Rewrite,
+// it modifies stmt in place.
+func ExpandStars(stmt Statement, schema map[string][]string) Statement {
+	return visitor.ExpandStars(stmt, schema).(Statement)
+}
+
+// ExpandGroupingSets rewrites ROLLUP and CUBE in GROUP BY clauses into the
+// equivalent explicit GROUPING SETS: ROLLUP (a, b) becomes
+// GROUPING SETS ((a, b), a, ()). Like Rewrite, it modifies stmt in place.
+func ExpandGroupingSets(stmt Statement) Statement {
+	return visitor.ExpandGroupingSets(stmt).(Statement)
+}
+
+// Validate reports semantic errors the parser does not catch, such as an
+// interval with an unknown unit in a hand-built AST. It returns nil if
+// node is valid; otherwise each error joined in the result is a
+// *ValidationError.
+func Validate(node ast.Node) error {
+	return visitor.Validate(node)
+}
+
 // Statement is the interface for all SQL statements.
 type Statement = ast.Statement
 
@@ -102,56 +268,120 @@ type Node = ast.Node
 
 // Common type aliases for convenience.
 type (
-	SelectStmt       = ast.SelectStmt
-	InsertStmt       = ast.InsertStmt
-	UpdateStmt       = ast.UpdateStmt
-	DeleteStmt       = ast.DeleteStmt
-	CreateTableStmt  = ast.CreateTableStmt
-	AlterTableStmt   = ast.AlterTableStmt
-	DropTableStmt    = ast.DropTableStmt
-	CreateIndexStmt  = ast.CreateIndexStmt
-	DropIndexStmt    = ast.DropIndexStmt
-	TruncateStmt     = ast.TruncateStmt
-	ExplainStmt      = ast.ExplainStmt
-	ColName          = ast.ColName
-	TableName        = ast.TableName
-	Literal          = ast.Literal
-	BinaryExpr       = ast.BinaryExpr
-	UnaryExpr        = ast.UnaryExpr
-	FuncExpr         = ast.FuncExpr
-	CaseExpr         = ast.CaseExpr
-	CastExpr         = ast.CastExpr
-	Subquery         = ast.Subquery
-	JoinExpr         = ast.JoinExpr
-	AliasedExpr      = ast.AliasedExpr
-	AliasedTableExpr = ast.AliasedTableExpr
-	StarExpr         = ast.StarExpr
-	ParenExpr        = ast.ParenExpr
-	InExpr           = ast.InExpr
-	BetweenExpr      = ast.BetweenExpr
-	LikeExpr         = ast.LikeExpr
-	IsExpr           = ast.IsExpr
-	ExistsExpr       = ast.ExistsExpr
-	OrderByExpr      = ast.OrderByExpr
-	Limit            = ast.Limit
-	WithClause       = ast.WithClause
-	CTE              = ast.CTE
+	SelectStmt         = ast.SelectStmt
+	TableStmt          = ast.TableStmt
+	InsertStmt         = ast.InsertStmt
+	UpdateStmt         = ast.UpdateStmt
+	DeleteStmt         = ast.DeleteStmt
+	CreateTableStmt    = ast.CreateTableStmt
+	AlterTableStmt     = ast.AlterTableStmt
+	DropTableStmt      = ast.DropTableStmt
+	CreateDatabaseStmt = ast.CreateDatabaseStmt
+	DropDatabaseStmt   = ast.DropDatabaseStmt
+	CreateSchemaStmt   = ast.CreateSchemaStmt
+	DropSchemaStmt     = ast.DropSchemaStmt
+	CreateSequenceStmt = ast.CreateSequenceStmt
+	CreateFunctionStmt = ast.CreateFunctionStmt
+	FuncArg            = ast.FuncArg
+	CreateIndexStmt    = ast.CreateIndexStmt
+	DropIndexStmt      = ast.DropIndexStmt
+	TruncateStmt       = ast.TruncateStmt
+	VacuumStmt         = ast.VacuumStmt
+	AnalyzeStmt        = ast.AnalyzeStmt
+	ShowStmt           = ast.ShowStmt
+	UseStmt            = ast.UseStmt
+	SetSessionStmt     = ast.SetSessionStmt
+	DeclareCursorStmt  = ast.DeclareCursorStmt
+	FetchStmt          = ast.FetchStmt
+	CopyStmt           = ast.CopyStmt
+	ExplainStmt        = ast.ExplainStmt
+	ColName            = ast.ColName
+	TableName          = ast.TableName
+	Literal            = ast.Literal
+	TypedLiteral       = ast.TypedLiteral
+	BinaryExpr         = ast.BinaryExpr
+	UnaryExpr          = ast.UnaryExpr
+	PostfixExpr        = ast.PostfixExpr
+	FuncExpr           = ast.FuncExpr
+	CaseExpr           = ast.CaseExpr
+	CastExpr           = ast.CastExpr
+	ConvertExpr        = ast.ConvertExpr
+	Subquery           = ast.Subquery
+	JoinExpr           = ast.JoinExpr
+	GroupingSetExpr    = ast.GroupingSetExpr
+	AliasedExpr        = ast.AliasedExpr
+	AliasedTableExpr   = ast.AliasedTableExpr
+	TableSample        = ast.TableSample
+	StarExpr           = ast.StarExpr
+	ReplaceItem        = ast.ReplaceItem
+	ParenExpr          = ast.ParenExpr
+	InExpr             = ast.InExpr
+	BetweenExpr        = ast.BetweenExpr
+	LikeExpr           = ast.LikeExpr
+	IsExpr             = ast.IsExpr
+	PeriodExpr         = ast.PeriodExpr
+	ExistsExpr         = ast.ExistsExpr
+	UniqueExpr         = ast.UniqueExpr
+	OrderByExpr        = ast.OrderByExpr
+	Limit              = ast.Limit
+	WithClause         = ast.WithClause
+	CTE                = ast.CTE
+)
+
+// Parse error types. Parse returns a ParseError; use errors.As to reach
+// its category.
+type (
+	ParseError                = parser.ParseError
+	UnexpectedTokenError      = parser.UnexpectedTokenError
+	UnterminatedStringError   = parser.UnterminatedStringError
+	UnsupportedStatementError = parser.UnsupportedStatementError
+	DepthLimitExceededError   = parser.DepthLimitExceededError
+)
+
+// ValidationError is the error type joined in Validate's result.
+type ValidationError = visitor.ValidationError
+
+// StatementSpan is a statement and its range in the input, returned by
+// ParseAllWithSpans.
+type StatementSpan = parser.StatementSpan
+
+// StmtType is the statement classification returned by StatementType.
+type StmtType = parser.StmtType
+
+// Statement types
+const (
+	StmtUnknown = parser.StmtUnknown
+	StmtSelect  = parser.StmtSelect
+	StmtInsert  = parser.StmtInsert
+	StmtUpdate  = parser.StmtUpdate
+	StmtDelete  = parser.StmtDelete
+	StmtDDL     = parser.StmtDDL
+	StmtExplain = parser.StmtExplain
+	StmtShow    = parser.StmtShow
+	StmtSet     = parser.StmtSet
+	StmtOther   = parser.StmtOther
 )
 
 // Join types
 const (
-	JoinInner = ast.JoinInner
-	JoinLeft  = ast.JoinLeft
-	JoinRight = ast.JoinRight
-	JoinFull  = ast.JoinFull
-	JoinCross = ast.JoinCross
+	JoinInner     = ast.JoinInner
+	JoinLeft      = ast.JoinLeft
+	JoinRight     = ast.JoinRight
+	JoinFull      = ast.JoinFull
+	JoinCross     = ast.JoinCross
+	JoinLeftSemi  = ast.JoinLeftSemi
+	JoinLeftAnti  = ast.JoinLeftAnti
+	JoinRightSemi = ast.JoinRightSemi
+	JoinRightAnti = ast.JoinRightAnti
 )
 
 // Literal types
 const (
-	LiteralNull   = ast.LiteralNull
-	LiteralInt    = ast.LiteralInt
-	LiteralFloat  = ast.LiteralFloat
-	LiteralString = ast.LiteralString
-	LiteralBool   = ast.LiteralBool
+	LiteralNull    = ast.LiteralNull
+	LiteralInt     = ast.LiteralInt
+	LiteralFloat   = ast.LiteralFloat
+	LiteralString  = ast.LiteralString
+	LiteralBool    = ast.LiteralBool
+	LiteralBlob    = ast.LiteralBlob
+	LiteralDefault = ast.LiteralDefault
 )
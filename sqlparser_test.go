@@ -1,9 +1,16 @@
 package machparse
 
 import (
+	"errors"
+	"strings"
 	"testing"
+	"testing/quick"
 
 	"github.com/freeeve/machparse/ast"
+	"github.com/freeeve/machparse/format"
+	"github.com/freeeve/machparse/parser"
+	"github.com/freeeve/machparse/token"
+	"github.com/freeeve/machparse/visitor"
 )
 
 func TestParseAndFormat(t *testing.T) {
@@ -32,6 +39,10 @@ func TestParseAndFormat(t *testing.T) {
 			name:  "select with subquery",
 			input: "SELECT * FROM users WHERE id IN (SELECT user_id FROM orders)",
 		},
+		{
+			name:  "schema qualified star",
+			input: "SELECT db.users.*, o.* FROM db.users JOIN orders AS o ON o.user_id = db.users.id",
+		},
 		{
 			name:  "insert",
 			input: "INSERT INTO users (id, name) VALUES (1, 'test')",
@@ -44,6 +55,414 @@ func TestParseAndFormat(t *testing.T) {
 			name:  "delete",
 			input: "DELETE FROM users WHERE id = 1",
 		},
+		{
+			name:  "order by explicit asc",
+			input: "SELECT a FROM t ORDER BY a ASC, b, c DESC",
+		},
+		{
+			name:  "update order by nulls",
+			input: "UPDATE t SET a = 1 ORDER BY b DESC NULLS LAST LIMIT 1",
+		},
+		{
+			name:  "delete order by nulls",
+			input: "DELETE FROM t ORDER BY b NULLS FIRST LIMIT 1",
+		},
+		{
+			name:  "copy from stdin",
+			input: "COPY t (a, b) FROM STDIN WITH (FORMAT csv, HEADER true, DELIMITER ';')",
+		},
+		{
+			name:     "copy query to stdout",
+			input:    "COPY (SELECT a FROM t) TO stdout (FORMAT binary)",
+			expected: "COPY (SELECT a FROM t) TO STDOUT WITH (FORMAT binary)",
+		},
+		{
+			name:     "copy legacy options",
+			input:    "COPY t TO '/tmp/t.csv' WITH CSV HEADER DELIMITER AS ','",
+			expected: "COPY t TO '/tmp/t.csv' CSV HEADER DELIMITER ','",
+		},
+		{
+			name:  "copy program",
+			input: "COPY t FROM PROGRAM 'gunzip -c /tmp/t.gz'",
+		},
+		{
+			name:     "create database",
+			input:    "CREATE DATABASE IF NOT EXISTS shop DEFAULT CHARACTER SET utf8mb4 COLLATE utf8mb4_bin",
+			expected: "CREATE DATABASE IF NOT EXISTS shop DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin",
+		},
+		{
+			name:  "drop database",
+			input: "DROP DATABASE IF EXISTS shop",
+		},
+		{
+			name:  "create schema",
+			input: "CREATE SCHEMA IF NOT EXISTS sales AUTHORIZATION joe",
+		},
+		{
+			name:  "drop schema",
+			input: "DROP SCHEMA a, b RESTRICT",
+		},
+		{
+			name:     "show tables",
+			input:    "show full tables in shop like 'u%'",
+			expected: "SHOW FULL TABLES FROM shop LIKE 'u%'",
+		},
+		{
+			name:  "show create table",
+			input: "SHOW CREATE TABLE shop.users",
+		},
+		{
+			name:  "show variables",
+			input: "SHOW SESSION VARIABLES WHERE Variable_name = 'sql_mode'",
+		},
+		{
+			name:  "use database",
+			input: "USE shop",
+		},
+		{
+			name:  "set search path",
+			input: "SET search_path = app, audit",
+		},
+		{
+			name:     "set session",
+			input:    "set session sql_mode = 'ANSI', @@global.max_connections = 100",
+			expected: "SET SESSION sql_mode = 'ANSI', @@global.max_connections = 100",
+		},
+		{
+			name:  "set to",
+			input: "SET LOCAL statement_timeout TO 5000",
+		},
+		{
+			name:     "interval units",
+			input:    "SELECT INTERVAL 1 day, INTERVAL '1:30' hour_minute, INTERVAL '1 2' Day To Hour",
+			expected: "SELECT INTERVAL 1 DAY, INTERVAL '1:30' HOUR_MINUTE, INTERVAL '1 2' DAY TO HOUR",
+		},
+		{
+			name:  "declare cursor",
+			input: "DECLARE c NO SCROLL CURSOR WITH HOLD FOR SELECT id FROM users",
+		},
+		{
+			name:     "fetch cursor",
+			input:    "fetch forward 10 in c",
+			expected: "FETCH FORWARD 10 IN c",
+		},
+		{
+			name:  "fetch into",
+			input: "FETCH NEXT FROM c INTO v_id, v_name",
+		},
+		{
+			name:     "typed literals",
+			input:    "SELECT * FROM t WHERE d >= date '2024-01-01' AND ts < TIMESTAMP '2024-01-01 00:00:00' AND t = TIME '12:00:00'",
+			expected: "SELECT * FROM t WHERE d >= DATE '2024-01-01' AND ts < TIMESTAMP '2024-01-01 00:00:00' AND t = TIME '12:00:00'",
+		},
+		{
+			name:  "date column and function",
+			input: "SELECT date, DATE(created_at) FROM t",
+		},
+		{
+			name:     "distinct aggregate with multiple args",
+			input:    "select count(distinct a, b), count(distinct (a, b)) from t",
+			expected: "SELECT COUNT(DISTINCT a, b), COUNT(DISTINCT (a, b)) FROM t",
+		},
+		{
+			name:  "distinct aggregate with filter and window",
+			input: "SELECT COUNT(DISTINCT a, b) FILTER (WHERE c > 1) OVER (PARTITION BY d) FROM t",
+		},
+		{
+			name:  "unique predicate",
+			input: "SELECT * FROM t WHERE NOT UNIQUE (SELECT a FROM u WHERE u.t_id = t.id)",
+		},
+		{
+			name:  "unique constraint",
+			input: "CREATE TABLE t (a INT DEFAULT 0 UNIQUE, b INT, UNIQUE (a, b))",
+		},
+		{
+			name:  "parenthesized is operand",
+			input: "SELECT (a OR b) IS TRUE, (a AND b) IS NOT FALSE, (a = b) IS NULL FROM t",
+		},
+		{
+			name:  "scalar subquery in arithmetic",
+			input: "SELECT (SELECT 1) + 2, 2 * (SELECT MAX(id) FROM t2), -(SELECT MIN(id) FROM t2) FROM t",
+		},
+		{
+			name:  "scalar subquery in comparison",
+			input: "SELECT * FROM t WHERE (SELECT COUNT(*) FROM u WHERE u.t_id = t.id) > 3 AND a = (SELECT MAX(a) FROM u)",
+		},
+		{
+			name:  "redundant parens around scalar subquery",
+			input: "SELECT ((SELECT 1)) + 2",
+		},
+		{
+			name:  "period overlaps",
+			input: "SELECT * FROM t WHERE (start_at, end_at) OVERLAPS (DATE '2024-01-01', DATE '2024-02-01')",
+		},
+		{
+			name:     "period predicates",
+			input:    "select * from t where p contains (a, b) and p precedes q or p succeeds q and p immediately precedes q and p immediately succeeds q",
+			expected: "SELECT * FROM t WHERE p CONTAINS (a, b) AND p PRECEDES q OR p SUCCEEDS q AND p IMMEDIATELY PRECEDES q AND p IMMEDIATELY SUCCEEDS q",
+		},
+		{
+			name:  "period keywords as identifiers",
+			input: "SELECT contains, precedes FROM t WHERE overlaps = 1",
+		},
+		{
+			name:  "returning generated and computed columns",
+			input: "INSERT INTO items (price, qty) VALUES (2, 3) RETURNING id, total, price * qty AS computed, *",
+		},
+		{
+			name:  "left semi join",
+			input: "SELECT a.id FROM a LEFT SEMI JOIN b ON a.id = b.a_id",
+		},
+		{
+			name:     "semi join shorthand",
+			input:    "SELECT * FROM a SEMI JOIN b ON a.id = b.a_id",
+			expected: "SELECT * FROM a LEFT SEMI JOIN b ON a.id = b.a_id",
+		},
+		{
+			name:  "anti joins",
+			input: "SELECT * FROM a LEFT ANTI JOIN b USING (id) RIGHT ANTI JOIN c ON c.id = a.id RIGHT SEMI JOIN d ON d.id = a.id",
+		},
+		{
+			name:  "grouping words as names",
+			input: "SELECT cube, sets, GROUPING(cube) FROM t GROUP BY cube, sets, ROLLUP (rollup)",
+		},
+		{
+			name:  "semi and anti as names",
+			input: "SELECT * FROM a AS semi JOIN anti ON semi.id = anti.id, semi LEFT SEMI JOIN b ON b.id = semi.id",
+		},
+		{
+			name:  "insert empty column list and row",
+			input: "INSERT INTO t () VALUES ()",
+		},
+		{
+			name:  "insert empty rows",
+			input: "INSERT INTO t VALUES (), (1, 2)",
+		},
+		{
+			name:  "insert default values",
+			input: "INSERT INTO t DEFAULT VALUES RETURNING id",
+		},
+		{
+			name:  "star except and replace",
+			input: "SELECT * EXCEPT (a, b) REPLACE (x * 2 AS x), u.* EXCLUDE (id) FROM t JOIN u ON u.id = t.id",
+		},
+		{
+			name:     "adjacent string literals",
+			input:    "SELECT 'a' 'b'\n'c' AS s",
+			expected: "SELECT 'abc' AS s",
+		},
+		{
+			name:  "like any array",
+			input: "SELECT * FROM t WHERE a LIKE ANY (ARRAY['x%', 'y%']) AND b NOT ILIKE ALL (SELECT p FROM pats)",
+		},
+		{
+			name:  "like any pattern list",
+			input: "SELECT * FROM t WHERE a LIKE SOME ('x%', 'y%') ESCAPE '!'",
+		},
+		{
+			name:  "like empty escape",
+			input: "SELECT * FROM t WHERE a LIKE 'x' ESCAPE '' OR b NOT ILIKE 'y' ESCAPE ''",
+		},
+		{
+			name:  "format function",
+			input: "SELECT FORMAT(price, 2), FORMAT('%s-%s', a, b) FROM t",
+		},
+		{
+			name:     "explain format",
+			input:    "EXPLAIN FORMAT = JSON SELECT FORMAT(price, 2) FROM t",
+			expected: "EXPLAIN FORMAT=JSON SELECT FORMAT(price, 2) FROM t",
+		},
+		{
+			name:  "explain analyze format",
+			input: "EXPLAIN ANALYZE FORMAT=TREE SELECT * FROM t",
+		},
+		{
+			name:     "explain option list",
+			input:    "EXPLAIN (ANALYZE, VERBOSE false, FORMAT JSON) DELETE FROM t",
+			expected: "EXPLAIN (ANALYZE, FORMAT JSON) DELETE FROM t",
+		},
+		{
+			name:  "named arguments",
+			input: "SELECT make_interval(days => 10, hours => h + 1), f(1, \"limit\" => 2) FROM t",
+		},
+		{
+			name:  "within group",
+			input: "SELECT PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY total) AS median, LISTAGG(name, ', ') WITHIN GROUP (ORDER BY name DESC) FROM t",
+		},
+		{
+			name:  "tablesample",
+			input: "SELECT * FROM t AS x TABLESAMPLE SYSTEM (10) REPEATABLE (42) JOIN u TABLESAMPLE (50 PERCENT) ON u.id = x.id",
+		},
+		{
+			name:  "sample seed",
+			input: "SELECT * FROM t SAMPLE BLOCK (5) SEED (1), u SAMPLE (10 ROWS)",
+		},
+		{
+			name:  "keyword functions",
+			input: "SELECT LEFT(name, 3), RIGHT(name, 2), RANK() OVER (ORDER BY x) FROM t LEFT JOIN u ON LEFT(t.a, 1) = u.b",
+		},
+		{
+			name:  "json path operators",
+			input: "SELECT doc #> '{a,b}', doc #>> '{0,name}', doc #> '{a, \"b c\"}' #>> '{0}' FROM t",
+		},
+		{
+			name:     "json arrow operators",
+			input:    "SELECT doc->'a'->>'b' FROM t WHERE doc#>>'{a,b}' = 'x'",
+			expected: "SELECT doc -> 'a' ->> 'b' FROM t WHERE doc #>> '{a,b}' = 'x'",
+		},
+		{
+			name:     "insert keyword columns",
+			input:    "INSERT INTO t (order, key, value) VALUES (1, 2, 3)",
+			expected: `INSERT INTO t ("order", "key", "value") VALUES (1, 2, 3)`,
+		},
+		{
+			name:     "keyword cte names",
+			input:    "WITH data (value, key) AS (SELECT 1, 2) SELECT * FROM data JOIN other USING (key)",
+			expected: `WITH "data" ("value", "key") AS (SELECT 1, 2) SELECT * FROM "data" JOIN other USING ("key")`,
+		},
+		{
+			name:  "cte materialization hints",
+			input: "WITH a AS MATERIALIZED (SELECT 1), b AS NOT MATERIALIZED (SELECT 2) SELECT * FROM a, b",
+		},
+		{
+			name:     "extract keyword fields",
+			input:    "SELECT EXTRACT(year FROM d), EXTRACT(EPOCH FROM d), DATE_PART('month', d) FROM t",
+			expected: "SELECT EXTRACT(YEAR FROM d), EXTRACT(EPOCH FROM d), DATE_PART('month', d) FROM t",
+		},
+		{
+			name:     "select from only",
+			input:    "SELECT * FROM ONLY parent p JOIN ONLY child c ON p.id = c.id",
+			expected: "SELECT * FROM ONLY parent AS p JOIN ONLY child AS c ON p.id = c.id",
+		},
+		{
+			name:     "select from parenthesized only",
+			input:    "SELECT * FROM ONLY (s.parent) AS p",
+			expected: "SELECT * FROM ONLY s.parent AS p",
+		},
+		{
+			name:     "delete qualified table with alias",
+			input:    "DELETE s.t x WHERE x.a = 1",
+			expected: "DELETE FROM s.t AS x WHERE x.a = 1",
+		},
+		{
+			name:  "delete from only",
+			input: "DELETE FROM ONLY t WHERE a = 1",
+		},
+		{
+			name:     "truncate only",
+			input:    "TRUNCATE ONLY a, b",
+			expected: "TRUNCATE TABLE ONLY a, b",
+		},
+		{
+			name:  "create table tablespace",
+			input: "CREATE TABLE t (id INT, CONSTRAINT pk PRIMARY KEY (id) USING INDEX TABLESPACE fast) WITH (fillfactor=70) TABLESPACE pg_default",
+		},
+		{
+			name:  "create table tablespace storage",
+			input: "CREATE TABLE t (id INT) TABLESPACE ts1 STORAGE DISK ENGINE=NDB",
+		},
+		{
+			name:  "create index tablespace",
+			input: "CREATE INDEX i ON t (a) TABLESPACE fast WHERE a > 0",
+		},
+		{
+			name:  "create index include",
+			input: "CREATE INDEX idx ON t (a) INCLUDE (b, c) WITH (fillfactor=90) WHERE a > 0",
+		},
+		{
+			name:  "index column opclass and nulls",
+			input: "CREATE INDEX idx ON t (name text_pattern_ops, created_at DESC NULLS LAST, b NULLS FIRST)",
+		},
+		{
+			name:  "window null treatment",
+			input: "SELECT LAST_VALUE(x) IGNORE NULLS OVER (ORDER BY t), LAG(x) RESPECT NULLS OVER (ORDER BY t) FROM tbl",
+		},
+		{
+			name:     "select without from",
+			input:    "SELECT 1 x, 2 AS y, 3 AS value",
+			expected: "SELECT 1 AS x, 2 AS y, 3 AS \"value\"",
+		},
+		{
+			name:  "bit and hex literal defaults",
+			input: "CREATE TABLE t (a BIT(3) DEFAULT b'101', f BOOLEAN DEFAULT TRUE, h BINARY(2) DEFAULT X'0A1F')",
+		},
+		{
+			name:  "table shorthand",
+			input: "TABLE ONLY s.t ORDER BY a DESC LIMIT 5 OFFSET 2",
+		},
+		{
+			name:  "dollar-quoted strings",
+			input: "SELECT $$it's$$, $fn$ a $$ b $fn$, 'plain' FROM t",
+		},
+		{
+			name:     "create function",
+			input:    "CREATE OR REPLACE FUNCTION f(a INT, b TEXT DEFAULT 'x') RETURNS INT AS $$ SELECT a $$ LANGUAGE sql STABLE",
+			expected: "CREATE OR REPLACE FUNCTION f(a INT, b TEXT DEFAULT 'x') RETURNS INT LANGUAGE sql STABLE AS $$ SELECT a $$",
+		},
+		{
+			name:  "create function returns table",
+			input: "CREATE FUNCTION f(n INT) RETURNS TABLE (a INT, \"b c\" TEXT) LANGUAGE sql AS 'SELECT n, ''x'''",
+		},
+		{
+			name:  "create procedure",
+			input: "CREATE PROCEDURE p(IN a INT, OUT b INT) LANGUAGE plpgsql SECURITY DEFINER AS 'BEGIN b := a; END'",
+		},
+		{
+			name:  "column comments",
+			input: "CREATE TABLE t (id INT NOT NULL COMMENT 'the id', note TEXT DEFAULT '' COMMENT 'it''s free text') COMMENT='notes'",
+		},
+		{
+			name:     "create sequence",
+			input:    "CREATE TEMP SEQUENCE IF NOT EXISTS s INCREMENT 2 START = 10, NOCACHE NOCYCLE",
+			expected: "CREATE TEMPORARY SEQUENCE IF NOT EXISTS s INCREMENT BY 2 START WITH 10 NOCACHE NO CYCLE",
+		},
+		{
+			name:  "convert using charset",
+			input: "SELECT CONVERT(name USING utf8mb4), CONVERT(x, CHAR) FROM t",
+		},
+		{
+			name:  "convert type first",
+			input: "SELECT CONVERT(INT, x), CONVERT(NVARCHAR(50), d, 120) FROM t",
+		},
+		{
+			name:     "keyword type names",
+			input:    "CREATE TABLE t (id int, d date)",
+			expected: "CREATE TABLE t (id INT, d DATE)",
+		},
+		{
+			name:  "text search match",
+			input: "SELECT * FROM t WHERE doc @@ to_tsquery('x') AND to_tsvector(title) @@ plainto_tsquery('y z')",
+		},
+		{
+			name:     "existence operators",
+			input:    "SELECT doc ? 'a', doc ?| ARRAY['a', 'b'], doc ?& ARRAY['c'] FROM t WHERE tags ? ? AND id = ?",
+			expected: "SELECT doc ? 'a', doc ?| ARRAY[ 'a', 'b' ], doc ?& ARRAY[ 'c' ] FROM t WHERE tags ? ? AND id = ?",
+		},
+		{
+			name:  "default in values and assignments",
+			input: "INSERT INTO t (a, b) VALUES (DEFAULT, 1), (2, DEFAULT) ON CONFLICT (a) DO UPDATE SET b = DEFAULT",
+		},
+		{
+			name:  "update set default",
+			input: "UPDATE t SET a = DEFAULT, b = NULL WHERE id = 1",
+		},
+		{
+			name:  "analyze table",
+			input: "ANALYZE users",
+		},
+		{
+			name:  "analyze mysql",
+			input: "ANALYZE TABLE a, b",
+		},
+		{
+			name:     "vacuum",
+			input:    "vacuum full verbose analyze users;",
+			expected: "VACUUM FULL VERBOSE ANALYZE users",
+		},
+		{
+			name:  "window order by nulls",
+			input: "SELECT ROW_NUMBER() OVER (PARTITION BY a ORDER BY b DESC NULLS LAST) FROM t",
+		},
 	}
 
 	for _, tt := range tests {
@@ -93,6 +512,87 @@ func TestWalk(t *testing.T) {
 	}
 }
 
+func TestWalkSkipChildren(t *testing.T) {
+	stmt, err := Parse("SELECT a FROM t WHERE b = (SELECT c FROM u) AND d = 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// false skips the subquery's columns but not the siblings after it
+	var columns []string
+	Walk(stmt, func(node Node) bool {
+		if col, ok := node.(*ColName); ok {
+			columns = append(columns, col.Name())
+		}
+		_, ok := node.(*Subquery)
+		return !ok
+	})
+	if got := strings.Join(columns, ","); got != "a,b,d" {
+		t.Errorf("Expected columns a,b,d, got %s", got)
+	}
+
+	columns = nil
+	err = WalkErr(stmt, func(node Node) error {
+		if col, ok := node.(*ColName); ok {
+			columns = append(columns, col.Name())
+		}
+		if _, ok := node.(*Subquery); ok {
+			return SkipChildren
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkErr: %v", err)
+	}
+	if got := strings.Join(columns, ","); got != "a,b,d" {
+		t.Errorf("Expected columns a,b,d, got %s", got)
+	}
+}
+
+func TestWalkErr(t *testing.T) {
+	stmt, err := Parse("SELECT a, b, c FROM t WHERE d = 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// SkipAll stops the walk and is not returned
+	var columns []string
+	err = WalkErr(stmt, func(node Node) error {
+		if col, ok := node.(*ColName); ok {
+			columns = append(columns, col.Name())
+			if col.Name() == "b" {
+				return SkipAll
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Expected nil error after SkipAll, got %v", err)
+	}
+	if got := strings.Join(columns, ","); got != "a,b" {
+		t.Errorf("Expected columns a,b, got %s", got)
+	}
+
+	// Any other error stops the walk and is returned
+	errFound := errors.New("found")
+	visited := 0
+	err = WalkErr(stmt, func(node Node) error {
+		visited++
+		if _, ok := node.(*TableName); ok {
+			return errFound
+		}
+		return nil
+	})
+	if err != errFound {
+		t.Errorf("Expected errFound, got %v", err)
+	}
+	total := 0
+	Walk(stmt, func(Node) bool { total++; return true })
+	if visited >= total {
+		t.Errorf("Expected the walk to stop early, visited %d of %d nodes", visited, total)
+	}
+}
+
 func TestRewrite(t *testing.T) {
 	stmt, err := Parse("SELECT id, name FROM users WHERE status = 'active'")
 	if err != nil {
@@ -211,6 +711,1327 @@ func TestDDL(t *testing.T) {
 	}
 }
 
+func TestDDLOptionOrder(t *testing.T) {
+	tests := []struct {
+		input      string
+		wantSuffix string
+	}{
+		{
+			"CREATE TABLE t (id INT) COMMENT='it''s' ENGINE=InnoDB DEFAULT CHARSET=utf8mb4",
+			"COMMENT='it''s' ENGINE=InnoDB DEFAULT CHARSET=utf8mb4",
+		},
+		{
+			"CREATE TABLE t (a INT) DEFAULT CHARSET=utf8 ENGINE=InnoDB DEFAULT COLLATE utf8_bin",
+			"DEFAULT CHARSET=utf8 ENGINE=InnoDB DEFAULT COLLATE=utf8_bin",
+		},
+		{
+			"CREATE TABLE t (id INT) ROW_FORMAT=DYNAMIC, AUTO_INCREMENT=100, ENGINE=MyISAM",
+			"ROW_FORMAT=DYNAMIC AUTO_INCREMENT=100 ENGINE=MyISAM",
+		},
+		{
+			"CREATE TABLE t (id INT) WITH (fillfactor = 70, autovacuum_enabled = false)",
+			"WITH (fillfactor=70, autovacuum_enabled=false)",
+		},
+		{
+			"CREATE INDEX idx ON t (a) WITH (fillfactor = 70, deduplicate_items = off)",
+			"WITH (fillfactor=70, deduplicate_items=off)",
+		},
+		{
+			"CREATE INDEX idx ON t (a) COMMENT 'by name' ALGORITHM = INPLACE",
+			"COMMENT 'by name' ALGORITHM=INPLACE",
+		},
+		{
+			"CREATE INDEX idx ON t (a) KEY_BLOCK_SIZE 8 LOCK=NONE",
+			"KEY_BLOCK_SIZE=8 LOCK=NONE",
+		},
+		{
+			"CREATE SEQUENCE s CACHE 5 START WITH 1 MAXVALUE 99 INCREMENT BY 3",
+			"CACHE 5 START WITH 1 MAXVALUE 99 INCREMENT BY 3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			stmt, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			formatted := String(stmt)
+			if !strings.HasSuffix(formatted, tt.wantSuffix) {
+				t.Errorf("Options out of order:\nGot:  %s\nWant suffix: %s", formatted, tt.wantSuffix)
+			}
+
+			stmt2, err := Parse(formatted)
+			if err != nil {
+				t.Fatalf("Re-parse error: %v\nFormatted: %s", err, formatted)
+			}
+			if formatted2 := String(stmt2); formatted != formatted2 {
+				t.Errorf("Round-trip mismatch:\nFirst:  %s\nSecond: %s", formatted, formatted2)
+			}
+		})
+	}
+}
+
+func TestFormatParens(t *testing.T) {
+	col := func(name string) ast.Expr { return &ast.ColName{Parts: []string{name}} }
+	bin := func(op token.Token, l, r ast.Expr) ast.Expr { return &ast.BinaryExpr{Op: op, Left: l, Right: r} }
+	one := &ast.Literal{Type: ast.LiteralInt, Value: "1"}
+	scalar := &ast.Subquery{Select: &ast.SelectStmt{Columns: []ast.SelectExpr{&ast.AliasedExpr{Expr: one}}}}
+
+	minimal := format.Options{Uppercase: true, MinimalParens: true}
+	full := format.Options{Uppercase: true, FullParens: true}
+
+	tests := []struct {
+		name string
+		expr ast.Expr
+		opts format.Options
+		want string
+	}{
+		{"built tree default", bin(token.ASTERISK, bin(token.PLUS, col("a"), col("b")), col("c")), format.DefaultOptions, "a + b * c"},
+		{"built tree minimal", bin(token.ASTERISK, bin(token.PLUS, col("a"), col("b")), col("c")), minimal, "(a + b) * c"},
+		{"built tree full", bin(token.PLUS, col("a"), bin(token.ASTERISK, col("b"), col("c"))), full, "a + (b * c)"},
+		{"right associativity", bin(token.MINUS, col("a"), bin(token.MINUS, col("b"), col("c"))), minimal, "a - (b - c)"},
+		{"left associativity", bin(token.MINUS, bin(token.MINUS, col("a"), col("b")), col("c")), minimal, "a - b - c"},
+		{"not over or", &ast.UnaryExpr{Op: token.NOT, Operand: bin(token.OR, col("a"), col("b"))}, minimal, "NOT (a OR b)"},
+		{"redundant parens dropped", &ast.ParenExpr{Expr: bin(token.AND, &ast.ParenExpr{Expr: col("a")}, &ast.ParenExpr{Expr: bin(token.EQ, col("b"), col("c"))})}, minimal, "(a AND b = c)"},
+		{"is over or", &ast.IsExpr{Expr: bin(token.OR, col("a"), col("b")), What: ast.IsTrue}, minimal, "(a OR b) IS TRUE"},
+		{"is over comparison", &ast.IsExpr{Expr: bin(token.EQ, col("a"), col("b")), Not: true, What: ast.IsFalse}, minimal, "(a = b) IS NOT FALSE"},
+		{"between over and", &ast.BetweenExpr{Expr: bin(token.PLUS, col("a"), col("b")), Low: bin(token.AND, col("c"), col("d")), High: col("e")}, minimal, "a + b BETWEEN (c AND d) AND e"},
+		{"like over or", &ast.LikeExpr{Expr: bin(token.OR, col("a"), col("b")), Pattern: col("c")}, minimal, "(a OR b) LIKE c"},
+		{"in over and", &ast.InExpr{Expr: bin(token.AND, col("a"), col("b")), Values: []ast.Expr{col("c")}}, minimal, "(a AND b) IN (c)"},
+		{"subquery operand minimal", bin(token.ASTERISK, bin(token.PLUS, scalar, one), scalar), minimal, "((SELECT 1) + 1) * (SELECT 1)"},
+		{"subquery operand full", bin(token.GT, scalar, bin(token.MINUS, one, scalar)), full, "(SELECT 1) > (1 - (SELECT 1))"},
+		{"subquery under unary minus", &ast.UnaryExpr{Op: token.MINUS, Operand: scalar}, minimal, "-(SELECT 1)"},
+		{"nested unary minus", &ast.UnaryExpr{Op: token.MINUS, Operand: &ast.ParenExpr{Expr: &ast.UnaryExpr{Op: token.MINUS, Operand: col("a")}}}, minimal, "- -a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := format.New(tt.opts)
+			f.Format(tt.expr)
+			got := f.String()
+			if got != tt.want {
+				t.Errorf("Got %q, want %q", got, tt.want)
+			}
+
+			// Default options trust ParenExpr markers, so a built tree
+			// without them may not survive a round trip.
+			if !tt.opts.MinimalParens && !tt.opts.FullParens {
+				return
+			}
+
+			// The output must parse back to the same grouping
+			stmt, err := Parse("SELECT " + got)
+			if err != nil {
+				t.Fatalf("Re-parse error: %v", err)
+			}
+			reparsed := stmt.(*ast.SelectStmt).Columns[0].(*ast.AliasedExpr).Expr
+			f1, f2 := format.New(full), format.New(full)
+			f1.Format(tt.expr)
+			f2.Format(reparsed)
+			if f1.String() != f2.String() {
+				t.Errorf("Grouping changed:\nBuilt:    %s\nReparsed: %s", f1.String(), f2.String())
+			}
+		})
+	}
+}
+
+func TestFormatSubqueryParens(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"SELECT * FROM t WHERE price > (SELECT AVG(price) FROM products)", "SELECT * FROM t WHERE price > (SELECT AVG(price) FROM products)"},
+		{"SELECT * FROM t WHERE price > ((SELECT AVG(price) FROM products))", "SELECT * FROM t WHERE price > (SELECT AVG(price) FROM products)"},
+		{"SELECT * FROM t WHERE (((SELECT 1))) < price", "SELECT * FROM t WHERE (SELECT 1) < price"},
+		{"SELECT -((SELECT 1)), ((SELECT 1)) + 1", "SELECT -(SELECT 1), (SELECT 1) + 1"},
+	}
+
+	opts := []format.Options{
+		format.DefaultOptions,
+		{Uppercase: true, MinimalParens: true},
+		{Uppercase: true, FullParens: true},
+	}
+	for _, tt := range tests {
+		for _, o := range opts {
+			stmt, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.input, err)
+			}
+			f := format.New(o)
+			f.Format(stmt)
+			got := f.String()
+			if got != tt.want {
+				t.Errorf("Format(%q, %+v) = %q, want %q", tt.input, o, got, tt.want)
+			}
+
+			stmt, err = Parse(got)
+			if err != nil {
+				t.Fatalf("Reparse(%q) error: %v", got, err)
+			}
+			f.Reset()
+			f.Format(stmt)
+			if again := f.String(); again != got {
+				t.Errorf("Format not idempotent: %q became %q", got, again)
+			}
+		}
+	}
+}
+
+func TestFormatPostgresCast(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"SELECT a::citext::regclass FROM t", "SELECT a::CITEXT::REGCLASS FROM t"},
+		{"SELECT CAST(CAST(a AS citext) AS regclass) FROM t", "SELECT CAST(CAST(a AS CITEXT) AS REGCLASS) FROM t"},
+		{"SELECT CAST(a AS citext)::regclass FROM t", "SELECT CAST(a AS CITEXT)::REGCLASS FROM t"},
+		{"SELECT (a || b)::citext, -a::citext, (1, 2)::point FROM t", "SELECT (a || b)::CITEXT, -a::CITEXT, (1, 2)::POINT FROM t"},
+	}
+
+	opts := []format.Options{
+		format.DefaultOptions,
+		{Uppercase: true, MinimalParens: true},
+	}
+	for _, tt := range tests {
+		for _, o := range opts {
+			stmt, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.input, err)
+			}
+			f := format.New(o)
+			f.Format(stmt)
+			if got := f.String(); got != tt.want {
+				t.Errorf("Format(%q, %+v) = %q, want %q", tt.input, o, got, tt.want)
+			}
+		}
+	}
+
+	// A hand-built operand is parenthesized as :: binds tightest.
+	cast := &ast.CastExpr{
+		Expr:     &ast.BinaryExpr{Op: token.PLUS, Left: &ast.ColName{Parts: []string{"a"}}, Right: &ast.ColName{Parts: []string{"b"}}},
+		Type:     &ast.DataType{Name: "citext"},
+		Postgres: true,
+	}
+	if got := String(cast); got != "(a + b)::CITEXT" {
+		t.Errorf("Got %q, want %q", got, "(a + b)::CITEXT")
+	}
+}
+
+func TestFormatUnaryParens(t *testing.T) {
+	col := func(name string) ast.Expr { return &ast.ColName{Parts: []string{name}} }
+	bin := func(op token.Token, l, r ast.Expr) ast.Expr { return &ast.BinaryExpr{Op: op, Left: l, Right: r} }
+	un := func(op token.Token, x ast.Expr) ast.Expr { return &ast.UnaryExpr{Op: op, Operand: x} }
+	paren := func(x ast.Expr) ast.Expr { return &ast.ParenExpr{Expr: x} }
+
+	tests := []struct {
+		expr ast.Expr
+		want string
+	}{
+		{un(token.NOT, bin(token.OR, col("a"), col("b"))), "NOT (a OR b)"},
+		{un(token.NOT, paren(bin(token.OR, col("a"), col("b")))), "NOT (a OR b)"},
+		{un(token.NOT, bin(token.EQ, col("a"), col("b"))), "NOT a = b"},
+		{un(token.MINUS, bin(token.PLUS, col("a"), col("b"))), "-(a + b)"},
+		{un(token.MINUS, paren(bin(token.PLUS, col("a"), col("b")))), "-(a + b)"},
+		{un(token.BITNOT, bin(token.BITOR, col("a"), col("b"))), "~(a | b)"},
+		{un(token.BITNOT, col("a")), "~a"},
+	}
+
+	opts := []format.Options{
+		format.DefaultOptions,
+		{Uppercase: true, MinimalParens: true},
+	}
+	for _, tt := range tests {
+		for _, o := range opts {
+			f := format.New(o)
+			f.Format(tt.expr)
+			got := f.String()
+			if got != tt.want {
+				t.Errorf("Got %q, want %q", got, tt.want)
+				continue
+			}
+
+			// The output parses back to a unary expression over the
+			// whole operand.
+			stmt, err := Parse("SELECT " + got)
+			if err != nil {
+				t.Fatalf("Re-parse error: %v", err)
+			}
+			if _, ok := stmt.(*ast.SelectStmt).Columns[0].(*ast.AliasedExpr).Expr.(*ast.UnaryExpr); !ok {
+				t.Errorf("%q: grouping changed on re-parse", got)
+			}
+		}
+	}
+}
+
+func TestFormatPostfixParens(t *testing.T) {
+	five := &ast.Literal{Type: ast.LiteralInt, Value: "5"}
+	fact := func(x ast.Expr) ast.Expr { return &ast.PostfixExpr{Op: token.BANG, Operand: x} }
+
+	tests := []struct {
+		expr ast.Expr
+		want string
+	}{
+		{fact(&ast.UnaryExpr{Op: token.MINUS, Operand: five}), "(-5)!"},
+		{fact(&ast.BinaryExpr{Op: token.PLUS, Left: five, Right: five}), "(5 + 5)!"},
+		{fact(fact(five)), "(5!)!"},
+		{fact(five), "5!"},
+	}
+	for _, tt := range tests {
+		for _, o := range []format.Options{format.DefaultOptions, {MinimalParens: true}} {
+			f := format.New(o)
+			f.Format(tt.expr)
+			if got := f.String(); got != tt.want {
+				t.Errorf("Got %q, want %q", got, tt.want)
+				continue
+			}
+
+			// The output parses back to a postfix expression over the
+			// whole operand.
+			stmt, err := Parse("SELECT " + f.String())
+			if err != nil {
+				t.Fatalf("Re-parse error: %v", err)
+			}
+			if _, ok := stmt.(*ast.SelectStmt).Columns[0].(*ast.AliasedExpr).Expr.(*ast.PostfixExpr); !ok {
+				t.Errorf("%q: grouping changed on re-parse", f.String())
+			}
+		}
+	}
+}
+
+func TestMinify(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"SELECT  a,\n\tb FROM t -- trailing", "SELECT a, b FROM t"},
+		{"SELECT * FROM t WHERE ((a = 1) OR (b = 2)) AND (c)", "SELECT * FROM t WHERE (a = 1 OR b = 2) AND c"},
+		{"SELECT ((a + b) * (c)), (a * b) + c, a - (b - c) FROM t", "SELECT (a + b) * c, a * b + c, a - (b - c) FROM t"},
+		{"SELECT NOT (a = b), -(-a), (a || b)::text FROM t", "SELECT NOT a = b, - -a, (a || b)::TEXT FROM t"},
+		{"UPDATE t SET a = (b + 1) WHERE (id IN ((1), (2)))", "UPDATE t SET a = b + 1 WHERE id IN (1, 2)"},
+		{"SELECT 1; /* second */ SELECT (2)", "SELECT 1;SELECT 2"},
+		{"CREATE TABLE t (a INT DEFAULT ((1 + 2)), b INT DEFAULT (0))", "CREATE TABLE t (a INT DEFAULT (1 + 2), b INT DEFAULT (0))"},
+		{"SELECT * FROM t WHERE (a = b) = c AND ((a < b) < c)", "SELECT * FROM t WHERE (a = b) = c AND (a < b) < c"},
+		{"SELECT (a = b) IS TRUE, a = (b <> c) FROM t", "SELECT (a = b) IS TRUE, a = (b <> c) FROM t"},
+		{"SELECT (a -> 'x') + 1, (a ->> 'x') = 'y' FROM t", "SELECT (a -> 'x') + 1, a ->> 'x' = 'y' FROM t"},
+	}
+	full := format.Options{Uppercase: true, FullParens: true}
+	fullString := func(sql string) string {
+		stmt, err := Parse(sql)
+		if err != nil {
+			t.Fatalf("Parse(%q) error: %v", sql, err)
+		}
+		f := format.New(full)
+		f.Format(visitor.StripParens(stmt))
+		return f.String()
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Minify(tt.input)
+			if err != nil {
+				t.Fatalf("Minify error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Got %q, want %q", got, tt.want)
+			}
+
+			// Each statement parses back to the same tree
+			inputs, outputs := strings.Split(tt.input, ";"), strings.Split(got, ";")
+			for i := range outputs {
+				if before, after := fullString(inputs[i]), fullString(outputs[i]); before != after {
+					t.Errorf("Grouping changed:\nInput:  %s\nOutput: %s", before, after)
+				}
+			}
+		})
+	}
+
+	if _, err := Minify("SELECT FROM WHERE"); err == nil {
+		t.Error("Expected error for invalid SQL")
+	}
+
+	// Set operations are not modeled, so minifying one must fail rather
+	// than drop its other arms.
+	for _, input := range []string{
+		"SELECT 1 UNION SELECT 2",
+		"SELECT a FROM t INTERSECT SELECT a FROM u",
+		"SELECT 1; SELECT a FROM t EXCEPT (SELECT a FROM u)",
+		"SELECT * FROM t WHERE a IN (SELECT 1 UNION ALL SELECT 2)",
+	} {
+		var unsupported *UnsupportedStatementError
+		if _, err := Minify(input); !errors.As(err, &unsupported) {
+			t.Errorf("Minify(%q): expected UnsupportedStatementError, got %v", input, err)
+		}
+	}
+}
+
+func TestParseAllWithSpans(t *testing.T) {
+	input := "-- first\nSELECT 'a;b' FROM t /* c */ ;\n\nINSERT INTO t VALUES (1);; UPDATE t SET a = 1 -- last\n"
+	spans, err := ParseAllWithSpans(input)
+	if err != nil {
+		t.Fatalf("ParseAllWithSpans error: %v", err)
+	}
+	want := []string{
+		"SELECT 'a;b' FROM t /* c */ ;",
+		"INSERT INTO t VALUES (1);",
+		"UPDATE t SET a = 1",
+	}
+	if len(spans) != len(want) {
+		t.Fatalf("Expected %d spans, got %d", len(want), len(spans))
+	}
+	for i, span := range spans {
+		if got := input[span.Start:span.End]; got != want[i] {
+			t.Errorf("Span %d: got %q, want %q", i, got, want[i])
+		}
+		if span.Start != span.Stmt.Pos().Offset {
+			t.Errorf("Span %d starts at %d, statement at %d", i, span.Start, span.Stmt.Pos().Offset)
+		}
+	}
+
+	if _, err := ParseAllWithSpans("SELECT 1; SELECT FROM WHERE"); err == nil {
+		t.Error("Expected error for invalid SQL")
+	}
+}
+
+// TestContextualWordsAsNames checks that words with meaning only in one
+// clause still work as column names, table aliases and column aliases.
+func TestContextualWordsAsNames(t *testing.T) {
+	words := []string{
+		"overlaps", "contains", "precedes", "succeeds", "immediately",
+		"semi", "anti", "exclude", "others",
+		"grouping", "sets", "rollup", "cube", "show", "tablesample",
+	}
+	for _, w := range words {
+		tests := []struct {
+			input string
+			want  string
+		}{
+			{"CREATE TABLE t (" + w + " INT)", "CREATE TABLE t (" + w + " INT)"},
+			{"SELECT " + w + ".a FROM t " + w, "SELECT " + w + ".a FROM t AS " + w},
+			{"SELECT a " + w + " FROM t", "SELECT a AS " + w + " FROM t"},
+			{"SELECT a " + w + ", b FROM t WHERE " + w + " = 1", "SELECT a AS " + w + ", b FROM t WHERE " + w + " = 1"},
+		}
+		for _, tt := range tests {
+			stmt, err := Parse(tt.input)
+			if err != nil {
+				t.Errorf("Parse(%q) error: %v", tt.input, err)
+				continue
+			}
+			if got := String(stmt); got != tt.want {
+				t.Errorf("Parse(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestFormatDollarQuotes(t *testing.T) {
+	lit := func(value, tag string, dollar bool) *ast.Literal {
+		return &ast.Literal{Type: ast.LiteralString, Value: value, DollarQuoted: dollar, DollarTag: tag}
+	}
+	dollar := format.Options{Uppercase: true, DollarQuotes: true}
+
+	tests := []struct {
+		lit  *ast.Literal
+		opts format.Options
+		want string
+	}{
+		{lit("it's", "", false), format.DefaultOptions, "'it''s'"},
+		{lit("it's", "", false), dollar, "$$it's$$"},
+		{lit("plain", "", false), dollar, "'plain'"},
+		{lit("plain", "body", true), format.DefaultOptions, "$body$plain$body$"},
+		{lit("a $$ b", "", true), format.DefaultOptions, "$q$a $$ b$q$"},
+		{lit("ends in $", "", true), format.DefaultOptions, "$q$ends in $$q$"},
+		{lit("$q$ and $$", "", true), format.DefaultOptions, "$q1$$q$ and $$$q1$"},
+	}
+
+	for _, tt := range tests {
+		f := format.New(tt.opts)
+		f.Format(tt.lit)
+		got := f.String()
+		if got != tt.want {
+			t.Errorf("Format(%q) = %s, want %s", tt.lit.Value, got, tt.want)
+			continue
+		}
+
+		stmt, err := Parse("SELECT " + got)
+		if err != nil {
+			t.Fatalf("Parse(%q) error: %v", got, err)
+		}
+		parsed := stmt.(*ast.SelectStmt).Columns[0].(*ast.AliasedExpr).Expr.(*ast.Literal)
+		if parsed.Value != tt.lit.Value {
+			t.Errorf("Round trip of %s gave %q, want %q", got, parsed.Value, tt.lit.Value)
+		}
+	}
+}
+
+// chunkWriter records the size of each write and fails after limit bytes.
+type chunkWriter struct {
+	strings.Builder
+	writes []int
+	limit  int
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	if w.limit > 0 && w.Len()+len(p) > w.limit {
+		return 0, errors.New("write limit reached")
+	}
+	w.writes = append(w.writes, len(p))
+	return w.Builder.Write(p)
+}
+
+func TestFprint(t *testing.T) {
+	stmt, err := Parse(generateInList(5000))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	want := String(stmt)
+
+	var w chunkWriter
+	if err := Fprint(&w, stmt); err != nil {
+		t.Fatalf("Fprint error: %v", err)
+	}
+	if w.String() != want {
+		t.Errorf("Fprint output differs from String")
+	}
+	if len(w.writes) < 2 {
+		t.Errorf("Expected output in several chunks, got %d writes", len(w.writes))
+	}
+	for _, n := range w.writes {
+		if n > 8192 {
+			t.Errorf("Expected chunks near the flush size, got a write of %d bytes", n)
+		}
+	}
+
+	// A formatter used with FormatTo can still be used for Format after.
+	f := format.New(format.DefaultOptions)
+	var small strings.Builder
+	if err := f.FormatTo(&small, stmt); err != nil || small.String() != want {
+		t.Errorf("FormatTo = %v, output matches: %v", err, small.String() == want)
+	}
+	f.Format(stmt)
+	if f.String() != want {
+		t.Errorf("Format after FormatTo differs from String")
+	}
+
+	failing := chunkWriter{limit: 100}
+	if err := Fprint(&failing, stmt); err == nil {
+		t.Error("Expected the writer's error")
+	}
+}
+
+func TestFormatterReuse(t *testing.T) {
+	a, _ := Parse("SELECT a FROM t WHERE x = 1 AND y = 2")
+	b, _ := Parse("DELETE FROM u")
+
+	opts := format.Options{Uppercase: false, Indent: "  ", AlignConditions: true}
+	f := format.Get(opts)
+	f.Format(a)
+	first := f.String()
+	f.Reset()
+	f.Format(b)
+	if got, want := f.String(), "delete from u"; got != want {
+		t.Errorf("After Reset got %q, want %q", got, want)
+	}
+	format.Put(f)
+
+	// Pooled formatters come back empty with the requested options.
+	f = format.Get(format.DefaultOptions)
+	f.Format(a)
+	if got, want := f.String(), "SELECT a FROM t WHERE x = 1 AND y = 2"; got != want {
+		t.Errorf("Got %q, want %q", got, want)
+	}
+	format.Put(f)
+	if first != "select a from t\nwhere x = 1\n  and y = 2" {
+		t.Errorf("Got %q before Reset", first)
+	}
+}
+
+func TestFormatAlignConditions(t *testing.T) {
+	opts := format.Options{Uppercase: true, Indent: "  ", AlignConditions: true}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "and chain",
+			input: "SELECT * FROM t WHERE a = 1 AND b = 2 AND c IS NULL ORDER BY a LIMIT 10",
+			want: `SELECT * FROM t
+WHERE a = 1
+  AND b = 2
+  AND c IS NULL
+ORDER BY a
+LIMIT 10`,
+		},
+		{
+			name:  "mixed and or",
+			input: "select id from t where status = 'active' and (kind = 'admin' or (kind = 'user' and verified)) and created_at > now() group by id having count(*) > 1 or max(x) = 2",
+			want: `SELECT id FROM t
+WHERE status = 'active'
+  AND (
+    kind = 'admin'
+    OR (
+      kind = 'user'
+      AND verified
+    )
+  )
+  AND created_at > NOW()
+GROUP BY id
+HAVING COUNT(*) > 1
+  OR MAX(x) = 2`,
+		},
+		{
+			name:  "nested subquery",
+			input: "SELECT * FROM t WHERE a = 1 AND b IN (SELECT b FROM u WHERE c = 1 AND d = 2)",
+			want: `SELECT * FROM t
+WHERE a = 1
+  AND b IN (SELECT b FROM u
+  WHERE c = 1
+    AND d = 2)`,
+		},
+		{
+			name:  "single condition stays inline",
+			input: "SELECT * FROM t WHERE (a = 1 OR b = 2) ORDER BY a",
+			want:  "SELECT * FROM t WHERE (a = 1 OR b = 2) ORDER BY a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			f := format.New(opts)
+			f.Format(stmt)
+			got := f.String()
+			if got != tt.want {
+				t.Errorf("Got:\n%s\nwant:\n%s", got, tt.want)
+			}
+
+			// The layout only changes whitespace.
+			reparsed, err := Parse(got)
+			if err != nil {
+				t.Fatalf("Reparse error: %v", err)
+			}
+			if String(reparsed) != String(stmt) {
+				t.Errorf("Round trip changed the query: %q", String(reparsed))
+			}
+		})
+	}
+}
+
+func TestExpandGroupingSets(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{
+			"SELECT a, b, SUM(x) FROM t GROUP BY ROLLUP (a, b)",
+			"SELECT a, b, SUM(x) FROM t GROUP BY GROUPING SETS ((a, b), a, ())",
+		},
+		{
+			"SELECT a, b FROM t GROUP BY CUBE (a, b)",
+			"SELECT a, b FROM t GROUP BY GROUPING SETS ((a, b), a, b, ())",
+		},
+		{
+			"SELECT a, b, c FROM t GROUP BY CUBE (a, b, c)",
+			"SELECT a, b, c FROM t GROUP BY GROUPING SETS ((a, b, c), (a, b), (a, c), a, (b, c), b, c, ())",
+		},
+		{
+			// Composite elements stay together.
+			"SELECT a, b, c FROM t GROUP BY ROLLUP ((a, b), c)",
+			"SELECT a, b, c FROM t GROUP BY GROUPING SETS ((a, b, c), (a, b), ())",
+		},
+		{
+			// Several items multiply out.
+			"SELECT a, b, c FROM t GROUP BY a, ROLLUP (b), CUBE (c)",
+			"SELECT a, b, c FROM t GROUP BY GROUPING SETS ((a, b, c), (a, b), (a, c), a)",
+		},
+		{
+			"SELECT a, b FROM t GROUP BY GROUPING SETS (ROLLUP (a, b), (b))",
+			"SELECT a, b FROM t GROUP BY GROUPING SETS ((a, b), a, (), b)",
+		},
+		{
+			"SELECT a FROM t GROUP BY a, b",
+			"SELECT a FROM t GROUP BY a, b",
+		},
+		{
+			"SELECT * FROM (SELECT a FROM t GROUP BY ROLLUP (a)) AS s",
+			"SELECT * FROM (SELECT a FROM t GROUP BY GROUPING SETS (a, ())) AS s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			stmt, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			if got := String(ExpandGroupingSets(stmt)); got != tt.want {
+				t.Errorf("Got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// ROLLUP (a, b) and its explicit form produce the same tree.
+	rollup, _ := Parse("SELECT a, b FROM t GROUP BY ROLLUP (a, b)")
+	explicit, _ := Parse("SELECT a, b FROM t GROUP BY GROUPING SETS ((a, b), (a), ())")
+	got := ExpandGroupingSets(rollup).(*SelectStmt).GroupBy[0].(*GroupingSetExpr)
+	want := explicit.(*SelectStmt).GroupBy[0].(*GroupingSetExpr)
+	if len(got.Sets) != len(want.Sets) {
+		t.Fatalf("Got %d sets, want %d", len(got.Sets), len(want.Sets))
+	}
+	for i := range want.Sets {
+		if len(got.Sets[i]) != len(want.Sets[i]) {
+			t.Errorf("Set %d: got %d expressions, want %d", i, len(got.Sets[i]), len(want.Sets[i]))
+		}
+	}
+
+	// Each set holds its own copy of a repeated expression.
+	if got.Sets[0][0] == got.Sets[1][0] {
+		t.Error("Expected repeated expressions to be cloned")
+	}
+}
+
+func TestCaseBranches(t *testing.T) {
+	stmt, err := Parse("SELECT CASE status WHEN 1 THEN 'new' WHEN 2 THEN CASE WHEN paid THEN amount ELSE 0 END ELSE NULL END FROM t")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	outer := stmt.(*SelectStmt).Columns[0].(*AliasedExpr).Expr.(*CaseExpr)
+
+	branches := func(c *CaseExpr) string {
+		var out []string
+		for _, b := range CaseBranches(c) {
+			out = append(out, String(b))
+		}
+		return strings.Join(out, ", ")
+	}
+	want := "status, 'new', CASE WHEN paid THEN amount ELSE 0 END, NULL"
+	if got := branches(outer); got != want {
+		t.Errorf("Got %q, want %q", got, want)
+	}
+	inner := outer.Whens[1].Result.(*CaseExpr)
+	if got, want := branches(inner), "amount, 0"; got != want {
+		t.Errorf("Got %q, want %q", got, want)
+	}
+}
+
+func TestReturningColumns(t *testing.T) {
+	tests := []struct {
+		input   string
+		columns string // all column references
+		outputs string // output columns
+	}{
+		{"INSERT INTO users (name) VALUES ('a') RETURNING id, name", "name,id,name", "id,name"},
+		{"UPDATE users SET n = n + 1 WHERE id = 1 RETURNING n AS total", "n,n,id,n", "n"},
+		{"DELETE FROM users WHERE id = 1 RETURNING users.id", "id,users.id", "users.id"},
+		{"DELETE FROM users WHERE id = 1", "id", ""},
+		{"INSERT INTO users (email) VALUES ('a') ON CONFLICT ((lower(email))) DO NOTHING", "email,email", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			stmt, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			var cols []string
+			for _, c := range ExtractColumns(stmt) {
+				cols = append(cols, String(c))
+			}
+			if got := strings.Join(cols, ","); got != tt.columns {
+				t.Errorf("ExtractColumns = %q, want %q", got, tt.columns)
+			}
+			var outs []string
+			for _, se := range OutputColumns(stmt) {
+				if ae, ok := se.(*AliasedExpr); ok {
+					outs = append(outs, String(ae.Expr))
+				} else {
+					outs = append(outs, String(se))
+				}
+			}
+			if got := strings.Join(outs, ","); got != tt.outputs {
+				t.Errorf("OutputColumns = %q, want %q", got, tt.outputs)
+			}
+		})
+	}
+
+	// Rewrite descends into RETURNING as well.
+	stmt, _ := Parse("INSERT INTO t (a) VALUES (1) RETURNING a")
+	Rewrite(stmt, func(n Node) Node {
+		if c, ok := n.(*ColName); ok {
+			c.Parts = []string{"x"}
+		}
+		return n
+	})
+	if got, want := String(stmt), "INSERT INTO t (x) VALUES (1) RETURNING x"; got != want {
+		t.Errorf("Got %q, want %q", got, want)
+	}
+}
+
+func TestReplaceTable(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "unaliased",
+			input: "SELECT users.id, users.* FROM users WHERE users.active = 1",
+			want:  "SELECT accounts.id, accounts.* FROM accounts WHERE accounts.active = 1",
+		},
+		{
+			name:  "aliased keeps alias qualifiers",
+			input: "SELECT u.id FROM users AS u JOIN orders AS o ON o.user_id = u.id",
+			want:  "SELECT u.id FROM accounts AS u JOIN orders AS o ON o.user_id = u.id",
+		},
+		{
+			name:  "old name used as another table's alias",
+			input: "SELECT users.id FROM people AS users JOIN users AS x ON x.id = users.id",
+			want:  "SELECT users.id FROM people AS users JOIN accounts AS x ON x.id = users.id",
+		},
+		{
+			name:  "schema qualified",
+			input: "SELECT app.users.id FROM app.users",
+			want:  "SELECT app.accounts.id FROM app.accounts",
+		},
+		{
+			name:  "case insensitive",
+			input: "SELECT Users.id FROM USERS",
+			want:  "SELECT accounts.id FROM accounts",
+		},
+		{
+			name:  "unqualified columns untouched",
+			input: "SELECT users FROM t WHERE id IN (SELECT user_id FROM users)",
+			want:  "SELECT users FROM t WHERE id IN (SELECT user_id FROM accounts)",
+		},
+		{
+			name:  "update",
+			input: "UPDATE users SET users.n = users.n + 1 WHERE users.id = 1 RETURNING users.n",
+			want:  "UPDATE accounts SET accounts.n = accounts.n + 1 WHERE accounts.id = 1 RETURNING accounts.n",
+		},
+		{
+			name:  "insert select",
+			input: "INSERT INTO users (id) SELECT old_users.id FROM old_users",
+			want:  "INSERT INTO accounts (id) SELECT old_users.id FROM old_users",
+		},
+		{
+			name:  "on conflict index expression",
+			input: "INSERT INTO users (email) VALUES ('a') ON CONFLICT ((LOWER(users.email))) DO NOTHING",
+			want:  "INSERT INTO accounts (email) VALUES ('a') ON CONFLICT ((LOWER(accounts.email))) DO NOTHING",
+		},
+		{
+			name:  "cte shadows the table",
+			input: "WITH users AS (SELECT * FROM users WHERE users.active = 1) SELECT users.id FROM users JOIN app.users AS a ON a.id = users.id",
+			want:  "WITH users AS (SELECT * FROM accounts WHERE accounts.active = 1) SELECT users.id FROM users JOIN app.accounts AS a ON a.id = users.id",
+		},
+		{
+			name:  "recursive cte body reads itself",
+			input: "WITH RECURSIVE users AS (SELECT users.id FROM users) SELECT * FROM users",
+			want:  "WITH RECURSIVE users AS (SELECT users.id FROM users) SELECT * FROM users",
+		},
+		{
+			name:  "other cte reads the table",
+			input: "WITH active AS (SELECT users.id FROM users) SELECT * FROM active",
+			want:  "WITH active AS (SELECT accounts.id FROM accounts) SELECT * FROM active",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			if got := String(ReplaceTable(stmt, "users", "accounts")); got != tt.want {
+				t.Errorf("Got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandStars(t *testing.T) {
+	schema := map[string][]string{
+		"users":      {"id", "name"},
+		"app.orders": {"id", "user_id"},
+	}
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "aliased qualified star",
+			input: "SELECT u.* FROM users u",
+			want:  "SELECT u.id, u.name FROM users AS u",
+		},
+		{
+			name:  "table qualified star",
+			input: "SELECT users.*, 1 FROM users",
+			want:  "SELECT users.id, users.name, 1 FROM users",
+		},
+		{
+			name:  "bare star single table",
+			input: "SELECT * FROM USERS",
+			want:  "SELECT id, name FROM USERS",
+		},
+		{
+			name:  "bare star join",
+			input: "SELECT * FROM users AS u JOIN app.orders ON orders.user_id = u.id",
+			want:  "SELECT u.id, u.name, orders.id, orders.user_id FROM users AS u JOIN app.orders ON orders.user_id = u.id",
+		},
+		{
+			name:  "semi join keeps left columns",
+			input: "SELECT * FROM users AS u LEFT SEMI JOIN orders AS o ON o.user_id = u.id",
+			want:  "SELECT id, name FROM users AS u LEFT SEMI JOIN orders AS o ON o.user_id = u.id",
+		},
+		{
+			name:  "except and replace",
+			input: "SELECT u.* EXCEPT (id) REPLACE (UPPER(u.name) AS name) FROM users AS u",
+			want:  "SELECT UPPER(u.name) AS name FROM users AS u",
+		},
+		{
+			name:  "subquery",
+			input: "SELECT * FROM t WHERE id IN (SELECT u.* FROM users AS u)",
+			want:  "SELECT * FROM t WHERE id IN (SELECT u.id, u.name FROM users AS u)",
+		},
+		{
+			name:  "unknown alias left alone",
+			input: "SELECT x.* FROM users AS u",
+			want:  "SELECT x.* FROM users AS u",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			if got := String(ExpandStars(stmt, schema)); got != tt.want {
+				t.Errorf("Got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// RETURNING * includes generated columns when the schema lists them.
+	returning := map[string][]string{"items": {"id", "price", "qty", "total"}}
+	for _, tt := range []struct{ input, want string }{
+		{"INSERT INTO items (price, qty) VALUES (2, 3) RETURNING *", "INSERT INTO items (price, qty) VALUES (2, 3) RETURNING id, price, qty, total"},
+		{"UPDATE items AS i SET qty = 4 FROM users WHERE users.id = i.id RETURNING i.*", "UPDATE items AS i SET qty = 4 FROM users WHERE users.id = i.id RETURNING i.id, i.price, i.qty, i.total"},
+		{"DELETE FROM items WHERE id = 1 RETURNING *, price * qty AS computed", "DELETE FROM items WHERE id = 1 RETURNING id, price, qty, total, price * qty AS computed"},
+	} {
+		stmt, err := Parse(tt.input)
+		if err != nil {
+			t.Fatalf("Parse error: %v", err)
+		}
+		if got := String(ExpandStars(stmt, returning)); got != tt.want {
+			t.Errorf("Got %q, want %q", got, tt.want)
+		}
+	}
+
+	// Renaming the table afterwards keeps the alias-qualified columns.
+	stmt, _ := Parse("SELECT u.* FROM users u")
+	stmt = ReplaceTable(ExpandStars(stmt, schema), "users", "accounts")
+	if got, want := String(stmt), "SELECT u.id, u.name FROM accounts AS u"; got != want {
+		t.Errorf("Got %q, want %q", got, want)
+	}
+}
+
+func TestFoldNegativeLiterals(t *testing.T) {
+	tests := []string{
+		"CREATE TABLE t (a INT DEFAULT -1, b FLOAT DEFAULT -1.5)",
+		"SELECT * FROM t LIMIT -1",
+		"SELECT a - -1, - -2, -(3) FROM t",
+	}
+
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			stmt, err := Parse(input)
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			before := String(stmt)
+			folded := visitor.FoldNegativeLiterals(stmt).(Statement)
+			if got := String(folded); got != before {
+				t.Errorf("Folding changed output: %q, want %q", got, before)
+			}
+			Walk(folded, func(n Node) bool {
+				if u, ok := n.(*UnaryExpr); ok {
+					if lit, ok := u.Operand.(*Literal); ok && !strings.HasPrefix(lit.Value, "-") {
+						t.Errorf("Unfolded negative literal in %q", String(folded))
+					}
+				}
+				return true
+			})
+		})
+	}
+
+	stmt, _ := Parse("SELECT * FROM t LIMIT -1")
+	stmt = visitor.FoldNegativeLiterals(stmt).(Statement)
+	lit, ok := stmt.(*SelectStmt).Limit.Count.(*Literal)
+	if !ok || lit.Value != "-1" || lit.Type != LiteralInt {
+		t.Errorf("Expected Literal -1, got %#v", stmt.(*SelectStmt).Limit.Count)
+	}
+}
+
+func TestValidateIntervalUnits(t *testing.T) {
+	stmt, err := Parse("SELECT INTERVAL 1 DAY, INTERVAL '1-2' YEAR TO MONTH FROM t")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if err := Validate(stmt); err != nil {
+		t.Errorf("Unexpected validation error: %v", err)
+	}
+
+	cols := stmt.(*SelectStmt).Columns
+	cols[0].(*AliasedExpr).Expr.(*ast.IntervalExpr).Unit = "fortnight"
+	cols[1].(*AliasedExpr).Expr.(*ast.IntervalExpr).Unit = "day_month"
+	err = Validate(stmt)
+	if err == nil {
+		t.Fatal("Expected validation errors for unknown units")
+	}
+	var verr *ValidationError
+	if !errors.As(err, &verr) || !strings.Contains(verr.Msg, "fortnight") {
+		t.Errorf("Expected ValidationError for fortnight, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "day_month") {
+		t.Errorf("Expected both units reported, got %v", err)
+	}
+}
+
+func TestValidateLikeEscape(t *testing.T) {
+	for _, input := range []string{
+		"SELECT * FROM t WHERE a LIKE 'x!%' ESCAPE '!'",
+		"SELECT * FROM t WHERE a LIKE 'x%' ESCAPE ''",
+		"SELECT * FROM t WHERE a NOT ILIKE 'x§%' ESCAPE '§'",
+	} {
+		stmt, err := Parse(input)
+		if err != nil {
+			t.Fatalf("%q: Parse error: %v", input, err)
+		}
+		if err := Validate(stmt); err != nil {
+			t.Errorf("%q: Unexpected validation error: %v", input, err)
+		}
+	}
+
+	stmt, err := Parse("SELECT * FROM t WHERE a LIKE 'x' ESCAPE '!!'")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	var verr *ValidationError
+	if err := Validate(stmt); !errors.As(err, &verr) || !strings.Contains(verr.Msg, "single character") {
+		t.Errorf("Expected ValidationError for a multi-character escape, got %v", err)
+	}
+	if verr != nil && verr.Pos.Column != 41 {
+		t.Errorf("Expected error at column 41, got %d", verr.Pos.Column)
+	}
+}
+
+func TestLikeEscapeRoundTrip(t *testing.T) {
+	tests := []struct {
+		input   string
+		pattern string
+		escape  string
+	}{
+		{`SELECT * FROM t WHERE a LIKE '%x%' ESCAPE '\'`, "%x%", `\`},
+		{`SELECT * FROM t WHERE a LIKE '%\_x%' ESCAPE '\'`, `%\_x%`, `\`},
+		{`SELECT * FROM t WHERE a LIKE '%!_x%' ESCAPE '!'`, "%!_x%", "!"},
+		{`SELECT * FROM t WHERE a LIKE '[a-c]%'`, "[a-c]%", ""},
+		{`SELECT * FROM t WHERE a NOT LIKE '[^0-9]\[%' ESCAPE '\'`, `[^0-9]\[%`, `\`},
+	}
+	likeOf := func(sql string) *ast.LikeExpr {
+		stmt, err := Parse(sql)
+		if err != nil {
+			t.Fatalf("Parse(%q) error: %v", sql, err)
+		}
+		return stmt.(*ast.SelectStmt).Where.(*ast.LikeExpr)
+	}
+	valueOf := func(e ast.Expr) string {
+		if e == nil {
+			return ""
+		}
+		return e.(*ast.Literal).Value
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			like := likeOf(tt.input)
+			formatted := String(like)
+			for i, l := range []*ast.LikeExpr{like, likeOf("SELECT * FROM t WHERE " + formatted)} {
+				if got := valueOf(l.Pattern); got != tt.pattern {
+					t.Errorf("Pass %d: pattern %q, want %q", i, got, tt.pattern)
+				}
+				if got := valueOf(l.Escape); got != tt.escape {
+					t.Errorf("Pass %d: escape %q, want %q", i, got, tt.escape)
+				}
+			}
+		})
+	}
+
+	// With backslash escapes, as in MySQL, '\\' is the one backslash and
+	// the formatter doubles it again.
+	p := parser.New(`SELECT * FROM t WHERE a LIKE '%\_x%' ESCAPE '\\'`)
+	p.SetStandardConformingStrings(false)
+	stmt, err := p.Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	like := stmt.(*ast.SelectStmt).Where.(*ast.LikeExpr)
+	if got := valueOf(like.Escape); got != `\` {
+		t.Errorf("Escape %q, want %q", got, `\`)
+	}
+	f := format.New(format.Options{Uppercase: true, BackslashEscapes: true})
+	f.Format(like)
+	if want := `a LIKE '%\\_x%' ESCAPE '\\'`; f.String() != want {
+		t.Errorf("Got %s, want %s", f.String(), want)
+	}
+}
+
+func TestValidateWithinGroup(t *testing.T) {
+	stmt, err := Parse("SELECT PERCENTILE_DISC(0.9) WITHIN GROUP (ORDER BY a), LISTAGG(b, ',') WITHIN GROUP (ORDER BY b) FROM t")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if err := Validate(stmt); err != nil {
+		t.Errorf("Unexpected validation error: %v", err)
+	}
+
+	stmt, err = Parse("SELECT a, SUM(x) WITHIN GROUP (ORDER BY y) FROM t")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	var verr *ValidationError
+	if err := Validate(stmt); !errors.As(err, &verr) || !strings.Contains(verr.Msg, "SUM") {
+		t.Fatalf("Expected ValidationError for SUM, got %v", err)
+	}
+	if verr.Pos.Column != 11 {
+		t.Errorf("Expected error at column 11, got %d", verr.Pos.Column)
+	}
+}
+
+func TestValidateTableSample(t *testing.T) {
+	for _, input := range []string{
+		"SELECT * FROM t TABLESAMPLE SYSTEM (0)",
+		"SELECT * FROM t TABLESAMPLE BERNOULLI (100)",
+		"SELECT * FROM t TABLESAMPLE (2.5 PERCENT)",
+		"SELECT * FROM t TABLESAMPLE (5000 ROWS)",
+		"SELECT * FROM t TABLESAMPLE SYSTEM ($1)",
+		"SELECT * FROM t TABLESAMPLE SYSTEM (pct * 2)",
+	} {
+		stmt, err := Parse(input)
+		if err != nil {
+			t.Fatalf("%q: Parse error: %v", input, err)
+		}
+		if err := Validate(stmt); err != nil {
+			t.Errorf("%q: Unexpected validation error: %v", input, err)
+		}
+	}
+
+	stmt, err := Parse("SELECT * FROM t TABLESAMPLE SYSTEM (150)")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	var verr *ValidationError
+	if err := Validate(stmt); !errors.As(err, &verr) || !strings.Contains(verr.Msg, "150") {
+		t.Fatalf("Expected ValidationError for 150, got %v", err)
+	}
+	if verr.Pos.Column != 37 {
+		t.Errorf("Expected error at column 37, got %d", verr.Pos.Column)
+	}
+}
+
+func TestStringLiteralRoundTrip(t *testing.T) {
+	roundTrip := func(value string) bool {
+		stmt, err := Parse("SELECT " + String(&Literal{Type: LiteralString, Value: value}))
+		if err != nil {
+			return false
+		}
+		lit, ok := stmt.(*SelectStmt).Columns[0].(*AliasedExpr).Expr.(*Literal)
+		return ok && lit.Value == value
+	}
+	if err := quick.Check(roundTrip, &quick.Config{MaxCount: 2000}); err != nil {
+		t.Error(err)
+	}
+	for _, value := range []string{`a\`, "it's", "  two  spaces\n\ttab ", `\'`, "\x00\xff"} {
+		if !roundTrip(value) {
+			t.Errorf("Round trip changed %q", value)
+		}
+	}
+}
+
+func TestRewriteCopy(t *testing.T) {
+
+	inputs := []string{
+		"SELECT u.id, COUNT(*) FROM users AS u LEFT JOIN orders AS o ON o.user_id = u.id WHERE u.a IN (1, 2) GROUP BY u.id HAVING COUNT(*) > 1 ORDER BY u.id DESC LIMIT 10",
+		"WITH x AS (SELECT a FROM t) SELECT * FROM x WHERE a BETWEEN 1 AND 2",
+		"INSERT INTO t (a, b) VALUES (1, DEFAULT), (2, 3) ON CONFLICT (a) DO UPDATE SET b = EXCLUDED.b RETURNING a",
+		"INSERT INTO t (a) VALUES (1) ON CONFLICT ((lower(a))) DO NOTHING",
+		"UPDATE t SET a = CASE WHEN b THEN 1 ELSE 2 END WHERE c = 'x'",
+		"CREATE TABLE t (id INT PRIMARY KEY, n INT DEFAULT -1)",
+	}
+
+	rename := func(n Node) Node {
+		if c, ok := n.(*ColName); ok {
+			c.Parts[len(c.Parts)-1] = "renamed"
+		}
+		return n
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			stmt, err := Parse(input)
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			before := String(stmt)
+
+			if got := String(Clone(stmt)); got != before {
+				t.Errorf("Clone formats as %q, want %q", got, before)
+			}
+
+			rewritten := RewriteCopy(stmt, rename)
+			if got := String(stmt); got != before {
+				t.Errorf("RewriteCopy modified input: %q, want %q", got, before)
+			}
+			if len(ExtractColumns(stmt)) > 0 && !strings.Contains(String(rewritten), "renamed") {
+				t.Errorf("RewriteCopy did not rewrite the copy: %q", String(rewritten))
+			}
+		})
+	}
+
+	if Clone(nil) != nil {
+		t.Error("Clone(nil) should be nil")
+	}
+}
+
+func TestIdentHook(t *testing.T) {
+	stmt, err := Parse(`SELECT "first name", t.id FROM "my table" AS t WHERE "last name" = 'x'`)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	var rejected []string
+	noSpaces := func(name string) string {
+		if strings.Contains(name, " ") {
+			rejected = append(rejected, name)
+			return strings.ReplaceAll(name, " ", "_")
+		}
+		return format.QuoteIdent(name)
+	}
+
+	f := format.New(format.Options{Uppercase: true, IdentHook: noSpaces})
+	f.Format(stmt)
+	want := "SELECT first_name, t.id FROM my_table AS t WHERE last_name = 'x'"
+	if got := f.String(); got != want {
+		t.Errorf("Got %q, want %q", got, want)
+	}
+	if strings.Join(rejected, ",") != "first name,my table,last name" {
+		t.Errorf("Unexpected rejected identifiers: %q", rejected)
+	}
+
+	if got := format.QuoteIdent("my table"); got != `"my table"` {
+		t.Errorf("QuoteIdent = %q", got)
+	}
+	if got := format.QuoteIdent("id"); got != "id" {
+		t.Errorf("QuoteIdent = %q", got)
+	}
+}
+
+func TestConcatTranslation(t *testing.T) {
+	toFunc := []struct {
+		input string
+		want  string
+	}{
+		{"SELECT a || b FROM t", "SELECT CONCAT(a, b) FROM t"},
+		{"SELECT a || ' ' || b || c FROM t", "SELECT CONCAT(a, ' ', b, c) FROM t"},
+		{"SELECT UPPER(a || b) FROM t", "SELECT UPPER(CONCAT(a, b)) FROM t"},
+	}
+	for _, tt := range toFunc {
+		t.Run(tt.input, func(t *testing.T) {
+			stmt, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			f := format.New(format.Options{Uppercase: true, ConcatFunction: true})
+			f.Format(stmt)
+			if got := f.String(); got != tt.want {
+				t.Errorf("Got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	toOperator := []struct {
+		input string
+		want  string
+	}{
+		{"SELECT CONCAT(a, b) FROM t", "SELECT a || b FROM t"},
+		{"SELECT CONCAT(a, ' ', b) FROM t", "SELECT a || ' ' || b FROM t"},
+		{"SELECT CONCAT(a, b + 1, c = d) FROM t", "SELECT a || (b + 1) || (c = d) FROM t"},
+		{"SELECT CONCAT(a) FROM t", "SELECT CONCAT(a) FROM t"},
+	}
+	for _, tt := range toOperator {
+		t.Run(tt.input, func(t *testing.T) {
+			stmt, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			got := String(visitor.ConcatFuncToOperator(stmt).(Statement))
+			if got != tt.want {
+				t.Errorf("Got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDatePartExtract(t *testing.T) {
+	toExtract := []struct {
+		input string
+		want  string
+	}{
+		{"SELECT DATE_PART('year', created_at) FROM t", "SELECT EXTRACT(YEAR FROM created_at) FROM t"},
+		{"SELECT date_part('epoch', a - b) FROM t", "SELECT EXTRACT(EPOCH FROM a - b) FROM t"},
+		{"SELECT DATE_PART('day', CAST(DATE_PART('month', d) AS TEXT)) FROM t", "SELECT EXTRACT(DAY FROM CAST(EXTRACT(MONTH FROM d) AS TEXT)) FROM t"},
+		// Not a field name: left as a call.
+		{"SELECT DATE_PART(unit, d), DATE_PART('no such', d) FROM t", "SELECT DATE_PART(unit, d), DATE_PART('no such', d) FROM t"},
+	}
+	for _, tt := range toExtract {
+		t.Run(tt.input, func(t *testing.T) {
+			stmt, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			got := String(visitor.DatePartToExtract(stmt).(Statement))
+			if got != tt.want {
+				t.Errorf("Got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	toDatePart := []struct {
+		input string
+		want  string
+	}{
+		{"SELECT EXTRACT(YEAR FROM created_at) FROM t", "SELECT DATE_PART('year', created_at) FROM t"},
+		{"SELECT EXTRACT(epoch FROM EXTRACT(hour FROM d)) FROM t", "SELECT DATE_PART('epoch', DATE_PART('hour', d)) FROM t"},
+	}
+	for _, tt := range toDatePart {
+		t.Run(tt.input, func(t *testing.T) {
+			stmt, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			got := String(visitor.ExtractToDatePart(stmt).(Statement))
+			if got != tt.want {
+				t.Errorf("Got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestMultiDialect(t *testing.T) {
 	queries := []struct {
 		name  string
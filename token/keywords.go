@@ -1,5 +1,11 @@
 package token
 
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
 // keywords maps lowercase keyword strings to token types.
 var keywords map[string]Token
 
@@ -204,6 +210,8 @@ func init() {
 		"recursive":    RECURSIVE,
 		"materialized": MATERIALIZED,
 
+		// Table sampling
+
 		// Locking
 		"for":    FOR,
 		"share":  SHARE,
@@ -435,6 +443,13 @@ func init() {
 		"collect":      COLLECT,
 		"pipelined":    PIPELINED,
 	}
+
+	// Keyword tokens not named in tokenNames print as their keyword.
+	for word, tok := range keywords {
+		if tokenNames[tok] == "" {
+			tokenNames[tok] = strings.ToUpper(word)
+		}
+	}
 }
 
 // LookupIdent returns the token type for an identifier.
@@ -492,3 +507,82 @@ func isLowercase(s string) bool {
 func IsKeyword(ident string) bool {
 	return LookupIdent(ident) != IDENT
 }
+
+// Category classifies a keyword by the part of SQL it belongs to.
+type Category int
+
+const (
+	CategoryNone        Category = iota // not a keyword
+	CategoryDML                         // queries and data modification: SELECT, JOIN, ORDER, INSERT, ...
+	CategoryDDL                         // schema changes and constraints: CREATE, TABLE, PRIMARY, ...
+	CategoryDataType                    // type names: INTEGER, VARCHAR, TIMESTAMP, ...
+	CategoryExpression                  // expression syntax: CASE, OVER, INTERVAL, GLOB, ...
+	CategoryFunction                    // functions with special syntax: COUNT, COALESCE, SUBSTRING, ...
+	CategoryTransaction                 // BEGIN, COMMIT, ISOLATION, ...
+	CategoryUtility                     // EXPLAIN, VACUUM, GRANT, ...
+	CategoryDialect                     // keywords specific to one dialect
+)
+
+var categoryNames = [...]string{
+	CategoryNone:        "none",
+	CategoryDML:         "DML",
+	CategoryDDL:         "DDL",
+	CategoryDataType:    "data type",
+	CategoryExpression:  "expression",
+	CategoryFunction:    "function",
+	CategoryTransaction: "transaction",
+	CategoryUtility:     "utility",
+	CategoryDialect:     "dialect",
+}
+
+func (c Category) String() string {
+	if c >= 0 && int(c) < len(categoryNames) {
+		return categoryNames[c]
+	}
+	return "unknown"
+}
+
+// categoryRanges maps the first token of each keyword group in token.go
+// to its category. A keyword belongs to the last range starting at or
+// before it, so new keywords take the category of the group they are
+// declared in.
+var categoryRanges = []struct {
+	first    Token
+	category Category
+}{
+	{SELECT, CategoryDML},
+	{CREATE, CategoryDDL},
+	{INT_TYPE, CategoryDataType},
+	{CASE, CategoryExpression},
+	{COUNT, CategoryFunction},
+	{LATERAL, CategoryDML},
+	{BEGIN, CategoryTransaction},
+	{ORDINALITY, CategoryDML},
+	{ANALYZE, CategoryUtility},
+	{INTERVAL, CategoryExpression},
+	{SUBSTRING, CategoryFunction},
+	{SYMMETRIC, CategoryExpression},
+	{AUTOINCREMENT, CategoryDialect},
+}
+
+// KeywordCategory returns the category of keyword token t, or
+// CategoryNone if t is not a keyword.
+func KeywordCategory(t Token) Category {
+	if !t.IsKeyword() {
+		return CategoryNone
+	}
+	category := CategoryNone
+	for _, r := range categoryRanges {
+		if t < r.first {
+			break
+		}
+		category = r.category
+	}
+	return category
+}
+
+// Keywords returns every keyword recognized by LookupIdent, in lower case
+// and sorted.
+func Keywords() []string {
+	return slices.Sorted(maps.Keys(keywords))
+}
@@ -0,0 +1,78 @@
+package token
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKeywordCategory(t *testing.T) {
+	tests := []struct {
+		tok  Token
+		want Category
+	}{
+		{SELECT, CategoryDML},
+		{JOIN, CategoryDML},
+		{DELETE, CategoryDML},
+		{CREATE, CategoryDDL},
+		{PRIMARY, CategoryDDL},
+		{VARCHAR, CategoryDataType},
+		{ZONE, CategoryDataType},
+		{CASE, CategoryExpression},
+		{COUNT, CategoryFunction},
+		{COALESCE, CategoryFunction},
+		{COMMIT, CategoryTransaction},
+		{EXPLAIN, CategoryUtility},
+		{SUBSTRING, CategoryFunction},
+		{AUTO_INCREMENT, CategoryDialect},
+		{ILIKE_KW, CategoryDialect},
+		{IDENT, CategoryNone},
+		{PLUS, CategoryNone},
+	}
+	for _, tt := range tests {
+		if got := KeywordCategory(tt.tok); got != tt.want {
+			t.Errorf("KeywordCategory(%v) = %v, want %v", tt.tok, got, tt.want)
+		}
+	}
+}
+
+func TestKeywordsCategorized(t *testing.T) {
+	words := Keywords()
+	if len(words) != len(keywords) {
+		t.Fatalf("Keywords() returned %d words, want %d", len(words), len(keywords))
+	}
+	for i, word := range words {
+		if i > 0 && words[i-1] >= word {
+			t.Errorf("Keywords() not sorted at %q", word)
+		}
+		if KeywordCategory(LookupIdent(word)) == CategoryNone {
+			t.Errorf("keyword %q (%d) has no category", word, LookupIdent(word))
+		}
+	}
+}
+
+func TestTokenString(t *testing.T) {
+	tests := []struct {
+		tok  Token
+		want string
+	}{
+		{SELECT, "SELECT"},
+		{PLUS, "+"},
+		{IDENT, "IDENT"},
+		{MEASURES, "MEASURES"},
+		{AUTO_INCREMENT, "AUTO_INCREMENT"},
+		{literalBeg, "UNKNOWN"},
+		{keywordEnd + 1, "UNKNOWN"},
+	}
+	for _, tt := range tests {
+		if got := tt.tok.String(); got != tt.want {
+			t.Errorf("Token(%d).String() = %q, want %q", tt.tok, got, tt.want)
+		}
+	}
+
+	// Every keyword prints as itself.
+	for _, word := range Keywords() {
+		if got := LookupIdent(word).String(); got != strings.ToUpper(word) {
+			t.Errorf("%q prints as %q", word, got)
+		}
+	}
+}
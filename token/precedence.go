@@ -0,0 +1,60 @@
+package token
+
+// Operator precedence levels (higher = tighter binding).
+//
+// The bitwise levels follow MySQL: | binds loosest, then &, then the
+// shifts, all below + and -. Bitwise XOR (^) binds tighter than * and /,
+// so a * b ^ c is a * (b ^ c). The JSON path and existence operators
+// follow PostgreSQL's "any other operator" level: they bind tighter than
+// comparisons, so data ->> 'a' = 'x' compares the extracted value, but
+// looser than arithmetic, so data -> 'a' + 1 is data -> ('a' + 1). All
+// binary operators are left-associative.
+const (
+	PrecLowest     = 0
+	PrecOr         = 1  // OR
+	PrecXor        = 2  // XOR
+	PrecAnd        = 3  // AND
+	PrecNot        = 4  // NOT (prefix)
+	PrecComparison = 5  // =, <>, <, >, <=, >=, @@, IS, LIKE, IN, BETWEEN
+	PrecJSON       = 6  // ->, ->>, #>, #>>, ?, ?|, ?&
+	PrecBitOr      = 7  // |
+	PrecBitAnd     = 8  // &
+	PrecShift      = 9  // <<, >>
+	PrecAdditive   = 10 // +, -, ||
+	PrecMultiply   = 11 // *, /, %
+	PrecBitXor     = 12 // ^
+	PrecUnary      = 13 // -, ~, !
+	PrecCollate    = 14 // COLLATE
+	PrecHighest    = 15
+)
+
+// Precedence returns the precedence of a binary operator,
+// or PrecLowest if t is not a binary operator.
+func Precedence(t Token) int {
+	switch t {
+	case OR:
+		return PrecOr
+	case XOR:
+		return PrecXor
+	case AND:
+		return PrecAnd
+	case EQ, NEQ, LT, GT, LTE, GTE, ATAT:
+		return PrecComparison
+	case BITOR:
+		return PrecBitOr
+	case BITXOR:
+		return PrecBitXor
+	case BITAND:
+		return PrecBitAnd
+	case LSHIFT, RSHIFT:
+		return PrecShift
+	case PLUS, MINUS, CONCAT:
+		return PrecAdditive
+	case ASTERISK, SLASH, PERCENT:
+		return PrecMultiply
+	case ARROW, DARROW, HASHGT, HASHDGT, QUESTION, QUESTIONOR, QUESTIONAND:
+		return PrecJSON
+	default:
+		return PrecLowest
+	}
+}
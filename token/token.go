@@ -44,10 +44,12 @@ const (
 	BITOR       // |
 	BITXOR      // ^
 	BITNOT      // ~
+	BANG        // ! (postfix factorial)
 	LSHIFT      // <<
 	RSHIFT      // >>
 	ARROW       // -> (JSON)
 	DARROW      // ->> (JSON)
+	FATARROW    // => (PostgreSQL named argument)
 	HASHGT      // #> (PostgreSQL JSON)
 	HASHDGT     // #>> (PostgreSQL JSON)
 	QUESTION    // ? (PostgreSQL JSON/HSTORE)
@@ -55,6 +57,7 @@ const (
 	QUESTIONAND // ?& (PostgreSQL HSTORE)
 	AT          // @
 	ATAT        // @@ (PostgreSQL text search)
+	OUTERJOIN   // (+) (Oracle outer join marker)
 	operatorEnd
 
 	keywordBeg
@@ -258,6 +261,8 @@ const (
 	RECURSIVE
 	MATERIALIZED
 
+	// Table sampling
+
 	// Locking
 	FOR
 	SHARE
@@ -513,6 +518,10 @@ const (
 	COLLECT
 	PIPELINED
 
+	// RESERVED is the token for words reserved by callers through
+	// Lexer.SetKeywords, such as a vendor's VECTOR type.
+	RESERVED
+
 	keywordEnd
 )
 
@@ -537,7 +546,7 @@ type Item struct {
 
 // String returns the token type as a string.
 func (t Token) String() string {
-	if int(t) < len(tokenNames) {
+	if int(t) < len(tokenNames) && tokenNames[t] != "" {
 		return tokenNames[t]
 	}
 	return "UNKNOWN"
@@ -558,7 +567,7 @@ func (t Token) IsKeyword() bool {
 	return t > keywordBeg && t < keywordEnd
 }
 
-var tokenNames = [...]string{
+var tokenNames = [keywordEnd]string{
 	ILLEGAL:    "ILLEGAL",
 	EOF:        "EOF",
 	COMMENT:    "COMMENT",
@@ -593,15 +602,21 @@ var tokenNames = [...]string{
 	BITOR:      "|",
 	BITXOR:     "^",
 	BITNOT:     "~",
+	BANG:       "!",
 	LSHIFT:     "<<",
 	RSHIFT:     ">>",
 	ARROW:      "->",
 	DARROW:     "->>",
+	HASHGT:     "#>",
+	HASHDGT:    "#>>",
+	FATARROW:   "=>",
+	OUTERJOIN:  "(+)",
 	SELECT:     "SELECT",
 	FROM:       "FROM",
 	WHERE:      "WHERE",
 	AND:        "AND",
 	OR:         "OR",
+	XOR:        "XOR",
 	NOT:        "NOT",
 	IN:         "IN",
 	LIKE:       "LIKE",
@@ -667,10 +682,22 @@ var tokenNames = [...]string{
 	ELSE:       "ELSE",
 	END:        "END",
 	CAST:       "CAST",
+	CONVERT:    "CONVERT",
 	OVER:       "OVER",
 	PARTITION:  "PARTITION",
 	WINDOW:     "WINDOW",
 	FILTER:     "FILTER",
+	WITHIN:     "WITHIN",
 	FOR:        "FOR",
 	WITH:       "WITH",
+
+	QUESTION:    "?",
+	QUESTIONOR:  "?|",
+	QUESTIONAND: "?&",
+	ATAT:        "@@",
+
+	TABLESPACE: "TABLESPACE",
+	STORAGE:    "STORAGE",
+
+	RESERVED: "RESERVED",
 }
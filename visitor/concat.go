@@ -0,0 +1,46 @@
+package visitor
+
+import (
+	"strings"
+
+	"github.com/freeeve/machparse/ast"
+	"github.com/freeeve/machparse/token"
+)
+
+// ConcatFuncToOperator rewrites CONCAT(a, b, ...) function calls into
+// a || b || ... operator chains. It is the inverse of the formatter's
+// ConcatFunction option. Calls with fewer than two arguments, DISTINCT,
+// FILTER, or OVER are left unchanged.
+//
+// Note that NULL handling differs between dialects: PostgreSQL's CONCAT
+// ignores NULL arguments while || yields NULL.
+func ConcatFuncToOperator(node ast.Node) ast.Node {
+	return Rewrite(node, func(n ast.Node) ast.Node {
+		fn, ok := n.(*ast.FuncExpr)
+		if !ok || !strings.EqualFold(fn.Name, "CONCAT") || len(fn.Args) < 2 ||
+			fn.Distinct || fn.Filter != nil || fn.Over != nil {
+			return n
+		}
+
+		result := concatOperand(fn.Args[0], false)
+		for _, arg := range fn.Args[1:] {
+			result = &ast.BinaryExpr{
+				StartPos: fn.StartPos,
+				EndPos:   fn.EndPos,
+				Op:       token.CONCAT,
+				Left:     result,
+				Right:    concatOperand(arg, true),
+			}
+		}
+		return result
+	})
+}
+
+// concatOperand parenthesizes arg if it would otherwise bind looser than ||.
+func concatOperand(arg ast.Expr, right bool) ast.Expr {
+	prec := ast.ExprPrecedence(arg)
+	if prec < token.PrecAdditive || (right && prec == token.PrecAdditive) {
+		return &ast.ParenExpr{StartPos: arg.Pos(), EndPos: arg.End(), Expr: arg}
+	}
+	return arg
+}
@@ -0,0 +1,71 @@
+package visitor
+
+import (
+	"strings"
+
+	"github.com/freeeve/machparse/ast"
+)
+
+// DatePartToExtract rewrites DATE_PART('field', source) calls into
+// EXTRACT(FIELD FROM source), for normalizing PostgreSQL date arithmetic
+// to the standard form. Calls whose first argument is not a string naming
+// a field, or that are not plain two-argument calls, are left unchanged.
+// It is the inverse of ExtractToDatePart.
+//
+// Note that PostgreSQL's EXTRACT returns numeric while DATE_PART returns
+// double precision.
+func DatePartToExtract(node ast.Node) ast.Node {
+	return Rewrite(node, func(n ast.Node) ast.Node {
+		fn, ok := n.(*ast.FuncExpr)
+		if !ok || !strings.EqualFold(fn.Name, "DATE_PART") || len(fn.Args) != 2 ||
+			fn.Distinct || fn.ArgNames != nil || fn.Filter != nil || fn.Over != nil {
+			return n
+		}
+		field, ok := fn.Args[0].(*ast.Literal)
+		if !ok || field.Type != ast.LiteralString || !isFieldName(field.Value) {
+			return n
+		}
+		return &ast.ExtractExpr{
+			StartPos: fn.StartPos,
+			EndPos:   fn.EndPos,
+			Field:    strings.ToUpper(field.Value),
+			Source:   fn.Args[1],
+		}
+	})
+}
+
+// ExtractToDatePart rewrites EXTRACT(FIELD FROM source) into
+// DATE_PART('field', source), the function form PostgreSQL and Redshift
+// also accept. It is the inverse of DatePartToExtract.
+func ExtractToDatePart(node ast.Node) ast.Node {
+	return Rewrite(node, func(n ast.Node) ast.Node {
+		e, ok := n.(*ast.ExtractExpr)
+		if !ok {
+			return n
+		}
+		return &ast.FuncExpr{
+			StartPos: e.StartPos,
+			EndPos:   e.EndPos,
+			Name:     "DATE_PART",
+			Args: []ast.Expr{
+				&ast.Literal{StartPos: e.StartPos, EndPos: e.StartPos, Type: ast.LiteralString, Value: strings.ToLower(e.Field)},
+				e.Source,
+			},
+		}
+	})
+}
+
+// isFieldName reports whether s can be written as an EXTRACT field: a
+// letter or underscore followed by letters, digits and underscores.
+func isFieldName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, ch := range s {
+		letter := ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch == '_'
+		if !letter && (i == 0 || ch < '0' || ch > '9') {
+			return false
+		}
+	}
+	return true
+}
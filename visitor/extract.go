@@ -0,0 +1,55 @@
+package visitor
+
+import "github.com/freeeve/machparse/ast"
+
+// ExtractColumns returns every column reference in node in walk order,
+// including references in RETURNING clauses. Duplicates are kept.
+func ExtractColumns(node ast.Node) []*ast.ColName {
+	var cols []*ast.ColName
+	WalkFunc(node, func(n ast.Node) bool {
+		if col, ok := n.(*ast.ColName); ok {
+			cols = append(cols, col)
+		}
+		return true
+	})
+	return cols
+}
+
+// OutputColumns returns the expressions a statement produces: the select
+// list of a SELECT (the leftmost branch of a set operation) or the
+// RETURNING list of an INSERT, UPDATE or DELETE. It returns nil for
+// statements that produce no rows.
+func OutputColumns(stmt ast.Statement) []ast.SelectExpr {
+	switch n := stmt.(type) {
+	case *ast.SelectStmt:
+		return n.Columns
+	case *ast.SetOp:
+		return OutputColumns(n.Left)
+	case *ast.InsertStmt:
+		return n.Returning
+	case *ast.UpdateStmt:
+		return n.Returning
+	case *ast.DeleteStmt:
+		return n.Returning
+	default:
+		return nil
+	}
+}
+
+// CaseBranches returns the operand of a simple CASE followed by the
+// result of each WHEN, in order, and the ELSE result if present. A CASE
+// nested in a result is returned as a single branch; call CaseBranches on
+// it to go further.
+func CaseBranches(c *ast.CaseExpr) []ast.Expr {
+	var branches []ast.Expr
+	if c.Operand != nil {
+		branches = append(branches, c.Operand)
+	}
+	for _, when := range c.Whens {
+		branches = append(branches, when.Result)
+	}
+	if c.Else != nil {
+		branches = append(branches, c.Else)
+	}
+	return branches
+}
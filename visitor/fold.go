@@ -0,0 +1,28 @@
+package visitor
+
+import (
+	"strings"
+
+	"github.com/freeeve/machparse/ast"
+	"github.com/freeeve/machparse/token"
+)
+
+// FoldNegativeLiterals replaces unary minus applied to a numeric literal,
+// such as the -1 in DEFAULT -1 or LIMIT -1, with a single negative Literal.
+// The parser always produces UnaryExpr(MINUS, Literal) for these; folding
+// is for consumers that want constant values as one node. The formatted
+// SQL is unchanged; in - -1 only the inner minus is folded.
+func FoldNegativeLiterals(node ast.Node) ast.Node {
+	return Rewrite(node, func(n ast.Node) ast.Node {
+		u, ok := n.(*ast.UnaryExpr)
+		if !ok || u.Op != token.MINUS {
+			return n
+		}
+		lit, ok := u.Operand.(*ast.Literal)
+		if !ok || (lit.Type != ast.LiteralInt && lit.Type != ast.LiteralFloat) ||
+			strings.HasPrefix(lit.Value, "-") {
+			return n
+		}
+		return &ast.Literal{StartPos: u.StartPos, EndPos: lit.EndPos, Type: lit.Type, Value: "-" + lit.Value}
+	})
+}
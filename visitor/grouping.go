@@ -0,0 +1,100 @@
+package visitor
+
+import (
+	"slices"
+
+	"github.com/freeeve/machparse/ast"
+)
+
+// ExpandGroupingSets rewrites ROLLUP and CUBE in GROUP BY clauses into the
+// equivalent explicit GROUPING SETS:
+//
+//	ROLLUP (a, b)  ->  GROUPING SETS ((a, b), a, ())
+//	CUBE (a, b)    ->  GROUPING SETS ((a, b), a, b, ())
+//
+// A GROUP BY that lists other items beside them becomes a single GROUPING
+// SETS holding the cross product of its items, as the standard defines:
+// GROUP BY a, ROLLUP (b) groups by (a, b) and then (a). GROUPING SETS
+// nested in GROUPING SETS are flattened. Each expression is cloned into
+// every set it appears in. GROUP BY clauses without ROLLUP, CUBE or
+// GROUPING SETS are left unchanged. Like Rewrite, it modifies node in
+// place.
+//
+// A CUBE of n elements expands to 2^n sets.
+func ExpandGroupingSets(node ast.Node) ast.Node {
+	return Rewrite(node, func(n ast.Node) ast.Node {
+		sel, ok := n.(*ast.SelectStmt)
+		if !ok || !slices.ContainsFunc(sel.GroupBy, isGroupingSet) {
+			return n
+		}
+
+		sets := [][]ast.Expr{{}}
+		for _, item := range sel.GroupBy {
+			var product [][]ast.Expr
+			for _, prefix := range sets {
+				for _, set := range groupingSetsOf(item) {
+					product = append(product, slices.Concat(prefix, set))
+				}
+			}
+			sets = product
+		}
+		for _, set := range sets {
+			for i, e := range set {
+				set[i] = ast.Clone(e).(ast.Expr)
+			}
+		}
+
+		sel.GroupBy = []ast.Expr{&ast.GroupingSetExpr{
+			StartPos: sel.GroupBy[0].Pos(),
+			EndPos:   sel.GroupBy[len(sel.GroupBy)-1].End(),
+			Type:     ast.GroupingSets,
+			Sets:     sets,
+		}}
+		return n
+	})
+}
+
+func isGroupingSet(e ast.Expr) bool {
+	_, ok := e.(*ast.GroupingSetExpr)
+	return ok
+}
+
+// groupingSetsOf returns the grouping sets a GROUP BY item stands for: a
+// plain expression is the single set containing it.
+func groupingSetsOf(e ast.Expr) [][]ast.Expr {
+	g, ok := e.(*ast.GroupingSetExpr)
+	if !ok {
+		return [][]ast.Expr{{e}}
+	}
+
+	var sets [][]ast.Expr
+	switch g.Type {
+	case ast.Rollup:
+		// Every prefix of the list, longest first.
+		for i := len(g.Sets); i >= 0; i-- {
+			sets = append(sets, slices.Concat(g.Sets[:i]...))
+		}
+	case ast.Cube:
+		// Every subset of the list, counting down in binary with the
+		// first element as the high bit.
+		n := len(g.Sets)
+		for mask := 1<<n - 1; mask >= 0; mask-- {
+			set := []ast.Expr{}
+			for i, elem := range g.Sets {
+				if mask&(1<<(n-1-i)) != 0 {
+					set = append(set, elem...)
+				}
+			}
+			sets = append(sets, set)
+		}
+	default:
+		for _, set := range g.Sets {
+			if len(set) == 1 && isGroupingSet(set[0]) {
+				sets = append(sets, groupingSetsOf(set[0])...)
+				continue
+			}
+			sets = append(sets, set)
+		}
+	}
+	return sets
+}
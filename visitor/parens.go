@@ -0,0 +1,31 @@
+package visitor
+
+import "github.com/freeeve/machparse/ast"
+
+// StripParens removes the parentheses written in the query (ParenExpr
+// nodes), for formatting with format.Options.MinimalParens, which puts
+// back only those operator precedence requires. Parentheses around a
+// column DEFAULT are kept, since MySQL requires them for any default that
+// is not a literal. Like Rewrite, it modifies node in place.
+func StripParens(node ast.Node) ast.Node {
+	keep := map[*ast.ParenExpr]bool{}
+	WalkFunc(node, func(n ast.Node) bool {
+		if ct, ok := n.(*ast.CreateTableStmt); ok {
+			for _, col := range ct.Columns {
+				for _, cons := range col.Constraints {
+					if paren, ok := cons.Default.(*ast.ParenExpr); ok {
+						keep[paren] = true
+					}
+				}
+			}
+		}
+		return true
+	})
+
+	return Rewrite(node, func(n ast.Node) ast.Node {
+		if paren, ok := n.(*ast.ParenExpr); ok && paren.Expr != nil && !keep[paren] {
+			return paren.Expr
+		}
+		return n
+	})
+}
@@ -8,6 +8,10 @@ type ApplyFunc func(ast.Node) ast.Node
 
 // Rewrite traverses the AST and allows modifying nodes.
 // The function is called in post-order (children first, then parent).
+//
+// Rewrite modifies the tree in place: replacements are stored into their
+// parent nodes, so node itself is changed even though the new root is
+// returned. Use RewriteCopy to leave the input untouched.
 func Rewrite(node ast.Node, f ApplyFunc) ast.Node {
 	if node == nil {
 		return nil
@@ -20,6 +24,12 @@ func Rewrite(node ast.Node, f ApplyFunc) ast.Node {
 	return f(node)
 }
 
+// RewriteCopy is like Rewrite but operates on a deep copy of node (see
+// ast.Clone), so the input tree is never modified.
+func RewriteCopy(node ast.Node, f ApplyFunc) ast.Node {
+	return Rewrite(ast.Clone(node), f)
+}
+
 func rewriteChildren(node ast.Node, f ApplyFunc) {
 	switch n := node.(type) {
 	case *ast.SelectStmt:
@@ -73,10 +83,37 @@ func rewriteChildren(node ast.Node, f ApplyFunc) {
 			}
 		}
 
+	case *ast.TableStmt:
+		if result := Rewrite(n.Table, f); result != nil {
+			n.Table = result.(*ast.TableName)
+		}
+		for i, ob := range n.OrderBy {
+			if result := Rewrite(ob.Expr, f); result != nil {
+				n.OrderBy[i].Expr = result.(ast.Expr)
+			}
+		}
+		if n.Limit != nil {
+			if n.Limit.Count != nil {
+				if result := Rewrite(n.Limit.Count, f); result != nil {
+					n.Limit.Count = result.(ast.Expr)
+				}
+			}
+			if n.Limit.Offset != nil {
+				if result := Rewrite(n.Limit.Offset, f); result != nil {
+					n.Limit.Offset = result.(ast.Expr)
+				}
+			}
+		}
+
 	case *ast.InsertStmt:
 		if result := Rewrite(n.Table, f); result != nil {
 			n.Table = result.(*ast.TableName)
 		}
+		for i, col := range n.Columns {
+			if result := Rewrite(col, f); result != nil {
+				n.Columns[i] = result.(*ast.ColName)
+			}
+		}
 		for i, row := range n.Values {
 			for j, val := range row {
 				if result := Rewrite(val, f); result != nil {
@@ -89,16 +126,39 @@ func rewriteChildren(node ast.Node, f ApplyFunc) {
 				n.Select = result.(*ast.SelectStmt)
 			}
 		}
+		rewriteUpdateExprs(n.SetAssignments, f)
+		rewriteUpdateExprs(n.OnDuplicateUpdate, f)
+		if oc := n.OnConflict; oc != nil {
+			for _, col := range oc.Columns {
+				if col.Expr != nil {
+					if result := Rewrite(col.Expr, f); result != nil {
+						col.Expr = result.(ast.Expr)
+					}
+				}
+			}
+			if oc.Where != nil {
+				if result := Rewrite(oc.Where, f); result != nil {
+					oc.Where = result.(ast.Expr)
+				}
+			}
+			rewriteUpdateExprs(oc.Updates, f)
+			if oc.UpdateWhere != nil {
+				if result := Rewrite(oc.UpdateWhere, f); result != nil {
+					oc.UpdateWhere = result.(ast.Expr)
+				}
+			}
+		}
+		for i, se := range n.Returning {
+			if result := Rewrite(se, f); result != nil {
+				n.Returning[i] = result.(ast.SelectExpr)
+			}
+		}
 
 	case *ast.UpdateStmt:
 		if result := Rewrite(n.Table, f); result != nil {
 			n.Table = result.(ast.TableExpr)
 		}
-		for i, ue := range n.Set {
-			if result := Rewrite(ue.Expr, f); result != nil {
-				n.Set[i].Expr = result.(ast.Expr)
-			}
-		}
+		rewriteUpdateExprs(n.Set, f)
 		if n.From != nil {
 			if result := Rewrite(n.From, f); result != nil {
 				n.From = result.(ast.TableExpr)
@@ -109,8 +169,18 @@ func rewriteChildren(node ast.Node, f ApplyFunc) {
 				n.Where = result.(ast.Expr)
 			}
 		}
+		for i, se := range n.Returning {
+			if result := Rewrite(se, f); result != nil {
+				n.Returning[i] = result.(ast.SelectExpr)
+			}
+		}
 
 	case *ast.DeleteStmt:
+		for i, target := range n.Targets {
+			if result := Rewrite(target, f); result != nil {
+				n.Targets[i] = result.(*ast.TableName)
+			}
+		}
 		if result := Rewrite(n.Table, f); result != nil {
 			n.Table = result.(ast.TableExpr)
 		}
@@ -124,6 +194,11 @@ func rewriteChildren(node ast.Node, f ApplyFunc) {
 				n.Where = result.(ast.Expr)
 			}
 		}
+		for i, se := range n.Returning {
+			if result := Rewrite(se, f); result != nil {
+				n.Returning[i] = result.(ast.SelectExpr)
+			}
+		}
 
 	case *ast.BinaryExpr:
 		if result := Rewrite(n.Left, f); result != nil {
@@ -138,17 +213,34 @@ func rewriteChildren(node ast.Node, f ApplyFunc) {
 			n.Operand = result.(ast.Expr)
 		}
 
+	case *ast.PostfixExpr:
+		if result := Rewrite(n.Operand, f); result != nil {
+			n.Operand = result.(ast.Expr)
+		}
+
 	case *ast.ParenExpr:
 		if result := Rewrite(n.Expr, f); result != nil {
 			n.Expr = result.(ast.Expr)
 		}
 
+	case *ast.TupleExpr:
+		for i, e := range n.Exprs {
+			if result := Rewrite(e, f); result != nil {
+				n.Exprs[i] = result.(ast.Expr)
+			}
+		}
+
 	case *ast.FuncExpr:
 		for i, arg := range n.Args {
 			if result := Rewrite(arg, f); result != nil {
 				n.Args[i] = result.(ast.Expr)
 			}
 		}
+		for i, ob := range n.WithinGroup {
+			if result := Rewrite(ob.Expr, f); result != nil {
+				n.WithinGroup[i].Expr = result.(ast.Expr)
+			}
+		}
 		if n.Filter != nil {
 			if result := Rewrite(n.Filter, f); result != nil {
 				n.Filter = result.(ast.Expr)
@@ -175,6 +267,15 @@ func rewriteChildren(node ast.Node, f ApplyFunc) {
 			}
 		}
 
+	case *ast.GroupingSetExpr:
+		for _, set := range n.Sets {
+			for j, expr := range set {
+				if result := Rewrite(expr, f); result != nil {
+					set[j] = result.(ast.Expr)
+				}
+			}
+		}
+
 	case *ast.InExpr:
 		if result := Rewrite(n.Expr, f); result != nil {
 			n.Expr = result.(ast.Expr)
@@ -205,8 +306,15 @@ func rewriteChildren(node ast.Node, f ApplyFunc) {
 		if result := Rewrite(n.Expr, f); result != nil {
 			n.Expr = result.(ast.Expr)
 		}
-		if result := Rewrite(n.Pattern, f); result != nil {
-			n.Pattern = result.(ast.Expr)
+		if n.Pattern != nil {
+			if result := Rewrite(n.Pattern, f); result != nil {
+				n.Pattern = result.(ast.Expr)
+			}
+		}
+		for i, pattern := range n.Patterns {
+			if result := Rewrite(pattern, f); result != nil {
+				n.Patterns[i] = result.(ast.Expr)
+			}
 		}
 		if n.Escape != nil {
 			if result := Rewrite(n.Escape, f); result != nil {
@@ -219,11 +327,34 @@ func rewriteChildren(node ast.Node, f ApplyFunc) {
 			n.Expr = result.(ast.Expr)
 		}
 
+	case *ast.PeriodExpr:
+		if result := Rewrite(n.Left, f); result != nil {
+			n.Left = result.(ast.Expr)
+		}
+		if result := Rewrite(n.Right, f); result != nil {
+			n.Right = result.(ast.Expr)
+		}
+
 	case *ast.CastExpr:
 		if result := Rewrite(n.Expr, f); result != nil {
 			n.Expr = result.(ast.Expr)
 		}
 
+	case *ast.ConvertExpr:
+		if result := Rewrite(n.Expr, f); result != nil {
+			n.Expr = result.(ast.Expr)
+		}
+		if n.Style != nil {
+			if result := Rewrite(n.Style, f); result != nil {
+				n.Style = result.(ast.Expr)
+			}
+		}
+
+	case *ast.ExtractExpr:
+		if result := Rewrite(n.Source, f); result != nil {
+			n.Source = result.(ast.Expr)
+		}
+
 	case *ast.Subquery:
 		if result := Rewrite(n.Select, f); result != nil {
 			n.Select = result.(*ast.SelectStmt)
@@ -234,15 +365,42 @@ func rewriteChildren(node ast.Node, f ApplyFunc) {
 			n.Subquery = result.(*ast.Subquery)
 		}
 
+	case *ast.UniqueExpr:
+		if result := Rewrite(n.Subquery, f); result != nil {
+			n.Subquery = result.(*ast.Subquery)
+		}
+
 	case *ast.AliasedExpr:
 		if result := Rewrite(n.Expr, f); result != nil {
 			n.Expr = result.(ast.Expr)
 		}
 
+	case *ast.StarExpr:
+		for i, item := range n.Replace {
+			if result := Rewrite(item.Expr, f); result != nil {
+				n.Replace[i].Expr = result.(ast.Expr)
+			}
+		}
+
 	case *ast.AliasedTableExpr:
 		if result := Rewrite(n.Expr, f); result != nil {
 			n.Expr = result.(ast.TableExpr)
 		}
+		if n.Sample != nil {
+			if result := Rewrite(n.Sample.Size, f); result != nil {
+				n.Sample.Size = result.(ast.Expr)
+			}
+			if n.Sample.Repeatable != nil {
+				if result := Rewrite(n.Sample.Repeatable, f); result != nil {
+					n.Sample.Repeatable = result.(ast.Expr)
+				}
+			}
+			if n.Sample.Seed != nil {
+				if result := Rewrite(n.Sample.Seed, f); result != nil {
+					n.Sample.Seed = result.(ast.Expr)
+				}
+			}
+		}
 
 	case *ast.JoinExpr:
 		if result := Rewrite(n.Left, f); result != nil {
@@ -261,6 +419,113 @@ func rewriteChildren(node ast.Node, f ApplyFunc) {
 		if result := Rewrite(n.Expr, f); result != nil {
 			n.Expr = result.(ast.TableExpr)
 		}
+
+	case *ast.ShowStmt:
+		if n.Table != nil {
+			if result := Rewrite(n.Table, f); result != nil {
+				n.Table = result.(*ast.TableName)
+			}
+		}
+		if n.Like != nil {
+			if result := Rewrite(n.Like, f); result != nil {
+				n.Like = result.(ast.Expr)
+			}
+		}
+		if n.Where != nil {
+			if result := Rewrite(n.Where, f); result != nil {
+				n.Where = result.(ast.Expr)
+			}
+		}
+
+	case *ast.SetSessionStmt:
+		for _, v := range n.Vars {
+			for i, value := range v.Values {
+				if result := Rewrite(value, f); result != nil {
+					v.Values[i] = result.(ast.Expr)
+				}
+			}
+		}
+
+	case *ast.DeclareCursorStmt:
+		if result := Rewrite(n.Query, f); result != nil {
+			n.Query = result.(ast.Statement)
+		}
+
+	case *ast.FetchStmt:
+		if n.Count != nil {
+			if result := Rewrite(n.Count, f); result != nil {
+				n.Count = result.(ast.Expr)
+			}
+		}
+
+	case *ast.VacuumStmt:
+		rewriteTableNames(n.Tables, f)
+
+	case *ast.AnalyzeStmt:
+		rewriteTableNames(n.Tables, f)
+
+	case *ast.CopyStmt:
+		if n.Table != nil {
+			if result := Rewrite(n.Table, f); result != nil {
+				n.Table = result.(*ast.TableName)
+			}
+		}
+		if n.Query != nil {
+			if result := Rewrite(n.Query, f); result != nil {
+				n.Query = result.(ast.Statement)
+			}
+		}
+
+	case *ast.CreateTableStmt:
+		if result := Rewrite(n.Table, f); result != nil {
+			n.Table = result.(*ast.TableName)
+		}
+		if n.As != nil {
+			if result := Rewrite(n.As, f); result != nil {
+				n.As = result.(*ast.SelectStmt)
+			}
+		}
+		for _, col := range n.Columns {
+			for _, cons := range col.Constraints {
+				if cons.Default != nil {
+					if result := Rewrite(cons.Default, f); result != nil {
+						cons.Default = result.(ast.Expr)
+					}
+				}
+				if cons.Check != nil {
+					if result := Rewrite(cons.Check, f); result != nil {
+						cons.Check = result.(ast.Expr)
+					}
+				}
+			}
+		}
+
+	case *ast.CreateSequenceStmt:
+		if result := Rewrite(n.Name, f); result != nil {
+			n.Name = result.(*ast.TableName)
+		}
+
+	case *ast.CreateFunctionStmt:
+		if result := Rewrite(n.Name, f); result != nil {
+			n.Name = result.(*ast.TableName)
+		}
+		for _, arg := range n.Args {
+			if arg.Default != nil {
+				if result := Rewrite(arg.Default, f); result != nil {
+					arg.Default = result.(ast.Expr)
+				}
+			}
+		}
+		if n.Body != nil {
+			if result := Rewrite(n.Body, f); result != nil {
+				n.Body = result.(*ast.Literal)
+			}
+		}
+		if n.Return != nil {
+			if result := Rewrite(n.Return, f); result != nil {
+				n.Return = result.(ast.Expr)
+			}
+		}
 	}
 }
 
@@ -277,3 +542,24 @@ func RewriteExpr(expr ast.Expr, f func(ast.Expr) ast.Expr) ast.Expr {
 	}
 	return result.(ast.Expr)
 }
+
+// rewriteUpdateExprs rewrites the target column and value of each assignment.
+func rewriteUpdateExprs(exprs []*ast.UpdateExpr, f ApplyFunc) {
+	for _, ue := range exprs {
+		if result := Rewrite(ue.Column, f); result != nil {
+			ue.Column = result.(*ast.ColName)
+		}
+		if result := Rewrite(ue.Expr, f); result != nil {
+			ue.Expr = result.(ast.Expr)
+		}
+	}
+}
+
+// rewriteTableNames rewrites each table name in place.
+func rewriteTableNames(tables []*ast.TableName, f ApplyFunc) {
+	for i, t := range tables {
+		if result := Rewrite(t, f); result != nil {
+			tables[i] = result.(*ast.TableName)
+		}
+	}
+}
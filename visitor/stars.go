@@ -0,0 +1,187 @@
+package visitor
+
+import (
+	"strings"
+
+	"github.com/freeeve/machparse/ast"
+)
+
+// tableRef is a table visible in a FROM clause: the name it is referenced
+// by (its alias, or its table name if unaliased) and the table it names.
+// table is nil for derived tables such as subqueries.
+type tableRef struct {
+	name  string
+	table *ast.TableName
+}
+
+// fromTableRefs returns the tables of a FROM clause in source order.
+func fromTableRefs(from ast.TableExpr) []tableRef {
+	switch t := from.(type) {
+	case *ast.TableName:
+		return []tableRef{{name: t.Name(), table: t}}
+	case *ast.AliasedTableExpr:
+		ref := tableRef{name: t.Alias}
+		if tn, ok := t.Expr.(*ast.TableName); ok {
+			ref.table = tn
+			if ref.name == "" {
+				ref.name = tn.Name()
+			}
+		}
+		return []tableRef{ref}
+	case *ast.JoinExpr:
+		// Semi and anti joins only filter one side; the other side's
+		// columns are not part of the result.
+		switch t.Type {
+		case ast.JoinLeftSemi, ast.JoinLeftAnti:
+			return fromTableRefs(t.Left)
+		case ast.JoinRightSemi, ast.JoinRightAnti:
+			return fromTableRefs(t.Right)
+		}
+		return append(fromTableRefs(t.Left), fromTableRefs(t.Right)...)
+	case *ast.ParenTableExpr:
+		return fromTableRefs(t.Expr)
+	default:
+		return nil
+	}
+}
+
+// ExpandStars replaces * and qualified stars in select lists and RETURNING
+// lists with the columns they stand for, taken from schema, which maps
+// table names to their columns in order. Table names are matched
+// case-insensitively, first as the full qualified name (app.users) and
+// then as the bare name.
+//
+// The parser cannot know a table's columns, so RETURNING * stays a star
+// until expanded here; list generated and computed columns in schema for
+// them to appear. RETURNING * covers the DML target and, for UPDATE ...
+// FROM and DELETE ... USING, the other listed tables.
+//
+// A qualified star resolves through table aliases: with schema
+// {"users": {"id", "name"}}, SELECT u.* FROM users u becomes
+// SELECT u.id, u.name FROM users u. A bare * expands every FROM table,
+// qualifying columns with the table's alias or name when there is more
+// than one. Stars over tables missing from schema, or over derived
+// tables, are left unexpanded. Like Rewrite, it modifies node in place.
+func ExpandStars(node ast.Node, schema map[string][]string) ast.Node {
+	lookup := make(map[string][]string, len(schema))
+	for name, cols := range schema {
+		lookup[strings.ToLower(name)] = cols
+	}
+	columnsOf := func(tn *ast.TableName) ([]string, bool) {
+		if tn == nil {
+			return nil, false
+		}
+		if cols, ok := lookup[strings.ToLower(strings.Join(tn.Parts, "."))]; ok {
+			return cols, true
+		}
+		cols, ok := lookup[strings.ToLower(tn.Name())]
+		return cols, ok
+	}
+
+	return Rewrite(node, func(n ast.Node) ast.Node {
+		switch n := n.(type) {
+		case *ast.SelectStmt:
+			if n.From != nil {
+				n.Columns = expandStars(n.Columns, fromTableRefs(n.From), columnsOf)
+			}
+		case *ast.InsertStmt:
+			if n.Table != nil {
+				refs := []tableRef{{name: n.Table.Name(), table: n.Table}}
+				n.Returning = expandStars(n.Returning, refs, columnsOf)
+			}
+		case *ast.UpdateStmt:
+			refs := append(fromTableRefs(n.Table), fromTableRefs(n.From)...)
+			n.Returning = expandStars(n.Returning, refs, columnsOf)
+		case *ast.DeleteStmt:
+			refs := append(fromTableRefs(n.Table), fromTableRefs(n.Using)...)
+			n.Returning = expandStars(n.Returning, refs, columnsOf)
+		}
+		return n
+	})
+}
+
+// expandStars returns list with each expandable star replaced by its
+// columns.
+func expandStars(list []ast.SelectExpr, refs []tableRef, columnsOf func(*ast.TableName) ([]string, bool)) []ast.SelectExpr {
+	var columns []ast.SelectExpr
+	for _, col := range list {
+		star, ok := col.(*ast.StarExpr)
+		if !ok {
+			columns = append(columns, col)
+			continue
+		}
+		expanded, ok := expandStar(star, refs, columnsOf)
+		if !ok {
+			columns = append(columns, col)
+			continue
+		}
+		columns = append(columns, expanded...)
+	}
+	return columns
+}
+
+// expandStar returns the columns star stands for, or false if any table
+// it covers is unknown. Columns in the star's EXCEPT list are left out and
+// those in its REPLACE list become their replacement, aliased to the
+// column name.
+func expandStar(star *ast.StarExpr, refs []tableRef, columnsOf func(*ast.TableName) ([]string, bool)) ([]ast.SelectExpr, bool) {
+	var exprs []ast.SelectExpr
+	add := func(qualifier []string, cols []string) {
+	columns:
+		for _, c := range cols {
+			for _, except := range star.Except {
+				if strings.EqualFold(c, except) {
+					continue columns
+				}
+			}
+			for _, item := range star.Replace {
+				if strings.EqualFold(c, item.Column) {
+					exprs = append(exprs, &ast.AliasedExpr{
+						StartPos: star.StartPos,
+						EndPos:   star.EndPos,
+						Expr:     item.Expr,
+						Alias:    item.Column,
+					})
+					continue columns
+				}
+			}
+			parts := append(append([]string(nil), qualifier...), c)
+			exprs = append(exprs, &ast.AliasedExpr{
+				StartPos: star.StartPos,
+				EndPos:   star.EndPos,
+				Expr:     &ast.ColName{StartPos: star.StartPos, EndPos: star.EndPos, Parts: parts},
+			})
+		}
+	}
+
+	if star.HasQualifier() {
+		for _, ref := range refs {
+			if !strings.EqualFold(ref.name, star.TableName()) {
+				continue
+			}
+			cols, ok := columnsOf(ref.table)
+			if !ok {
+				return nil, false
+			}
+			add(star.QualifierParts, cols)
+			return exprs, true
+		}
+		return nil, false
+	}
+
+	if len(refs) == 0 {
+		return nil, false
+	}
+	for _, ref := range refs {
+		cols, ok := columnsOf(ref.table)
+		if !ok {
+			return nil, false
+		}
+		var qualifier []string
+		if len(refs) > 1 {
+			qualifier = []string{ref.name}
+		}
+		add(qualifier, cols)
+	}
+	return exprs, true
+}
@@ -0,0 +1,92 @@
+package visitor
+
+import (
+	"strings"
+
+	"github.com/freeeve/machparse/ast"
+)
+
+// ReplaceTable renames every reference to table oldName as newName: table
+// names in FROM, JOIN and DML targets, and the table qualifier of column
+// references and qualified stars (oldName.col, schema.oldName.*).
+// Names are compared case-insensitively and only the last part of a
+// qualified table name is matched, so schema qualifiers are preserved.
+//
+// Qualifiers that name a table alias are left alone: in
+// SELECT o.id FROM orders AS o, renaming orders keeps o.id, and if some
+// other table is aliased as oldName, columns qualified with oldName refer
+// to that alias and are not renamed. Aliases are collected across the
+// whole statement, including subqueries.
+//
+// Likewise, when a WITH clause defines a CTE named oldName, unqualified
+// references to oldName and qualifiers using it name the CTE and are not
+// renamed. The body of a non-recursive CTE still reads the table, so
+// WITH orders AS (SELECT * FROM orders) renames the inner reference only.
+func ReplaceTable(node ast.Node, oldName, newName string) ast.Node {
+	aliased, shadowed := false, false
+	var bodies []*ast.CTE
+	WalkFunc(node, func(n ast.Node) bool {
+		if at, ok := n.(*ast.AliasedTableExpr); ok && strings.EqualFold(at.Alias, oldName) {
+			aliased = true
+		}
+		if with := withClause(n); with != nil {
+			for _, cte := range with.CTEs {
+				if strings.EqualFold(cte.Name, oldName) {
+					shadowed = true
+					if !with.Recursive {
+						bodies = append(bodies, cte)
+					}
+				}
+			}
+		}
+		return true
+	})
+
+	// A CTE body is outside the CTE's own scope, so rename it on its own.
+	for _, cte := range bodies {
+		if result := ReplaceTable(cte.Query, oldName, newName); result != nil {
+			cte.Query = result.(ast.Statement)
+		}
+	}
+
+	// renameQualifier renames the table part of a qualifier list.
+	renameQualifier := func(parts []string) {
+		if !aliased && !shadowed && len(parts) > 0 && strings.EqualFold(parts[len(parts)-1], oldName) {
+			parts[len(parts)-1] = newName
+		}
+	}
+
+	return Rewrite(node, func(n ast.Node) ast.Node {
+		switch n := n.(type) {
+		case *ast.TableName:
+			if shadowed && len(n.Parts) == 1 {
+				break
+			}
+			if len(n.Parts) > 0 && strings.EqualFold(n.Parts[len(n.Parts)-1], oldName) {
+				n.Parts[len(n.Parts)-1] = newName
+			}
+		case *ast.ColName:
+			if len(n.Parts) > 1 {
+				renameQualifier(n.Parts[:len(n.Parts)-1])
+			}
+		case *ast.StarExpr:
+			renameQualifier(n.QualifierParts)
+		}
+		return n
+	})
+}
+
+// withClause returns the WITH clause of a statement, or nil.
+func withClause(n ast.Node) *ast.WithClause {
+	switch n := n.(type) {
+	case *ast.SelectStmt:
+		return n.With
+	case *ast.InsertStmt:
+		return n.With
+	case *ast.UpdateStmt:
+		return n.With
+	case *ast.DeleteStmt:
+		return n.With
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package visitor
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/freeeve/machparse/ast"
+	"github.com/freeeve/machparse/token"
+)
+
+// ValidationError reports a node that is well formed but not valid SQL,
+// such as an interval with an unknown unit in a hand-built AST.
+type ValidationError struct {
+	Pos token.Pos
+	Msg string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Pos.Line, e.Pos.Column, e.Msg)
+}
+
+// Validate checks node for semantic errors the grammar does not catch and
+// returns them joined with errors.Join, or nil if there are none. Each
+// joined error is a *ValidationError.
+func Validate(node ast.Node) error {
+	var errs []error
+	WalkFunc(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.IntervalExpr:
+			if n.Unit != "" && !ast.IsIntervalUnit(n.Unit) {
+				errs = append(errs, &ValidationError{Pos: n.StartPos, Msg: fmt.Sprintf("unknown interval unit %q", n.Unit)})
+			}
+		case *ast.FuncExpr:
+			if len(n.WithinGroup) > 0 && !ast.IsOrderedSetAggregate(n.Name) {
+				errs = append(errs, &ValidationError{Pos: n.StartPos, Msg: fmt.Sprintf("WITHIN GROUP is not allowed with %s", n.Name)})
+			}
+		case *ast.AliasedTableExpr:
+			// Only literal percentages are checked; parameters and other
+			// expressions are left to the database.
+			if s := n.Sample; s != nil && !s.Rows {
+				if lit, ok := s.Size.(*ast.Literal); ok && (lit.Type == ast.LiteralInt || lit.Type == ast.LiteralFloat) {
+					if pct, err := strconv.ParseFloat(lit.Value, 64); err == nil && (pct < 0 || pct > 100) {
+						errs = append(errs, &ValidationError{Pos: lit.Pos(), Msg: fmt.Sprintf("sample percentage %s is out of range 0 to 100", lit.Value)})
+					}
+				}
+			}
+		case *ast.LikeExpr:
+			// An empty escape disables escaping; anything longer than one
+			// character is rejected by the standard.
+			if lit, ok := n.Escape.(*ast.Literal); ok && lit.Type == ast.LiteralString && utf8.RuneCountInString(lit.Value) > 1 {
+				errs = append(errs, &ValidationError{Pos: lit.Pos(), Msg: fmt.Sprintf("ESCAPE must be a single character, got %q", lit.Value)})
+			}
+		}
+		return true
+	})
+	return errors.Join(errs...)
+}
@@ -1,14 +1,20 @@
 // Package visitor provides AST traversal and rewriting utilities.
 package visitor
 
-import "github.com/freeeve/machparse/ast"
+import (
+	"errors"
+
+	"github.com/freeeve/machparse/ast"
+)
 
 // Visitor is the interface for AST traversal.
 type Visitor interface {
 	Visit(node ast.Node) Visitor
 }
 
-// Walk traverses an AST in depth-first order.
+// Walk traverses an AST in depth-first order. When v.Visit returns nil
+// the children of that node are skipped; the walk goes on with the next
+// sibling.
 func Walk(v Visitor, node ast.Node) {
 	if node == nil {
 		return
@@ -67,9 +73,31 @@ func walkChildren(v Visitor, node ast.Node) {
 		if n.Select != nil {
 			Walk(v, n.Select)
 		}
+		for _, ue := range n.SetAssignments {
+			Walk(v, ue.Column)
+			Walk(v, ue.Expr)
+		}
 		for _, ue := range n.OnDuplicateUpdate {
+			Walk(v, ue.Column)
 			Walk(v, ue.Expr)
 		}
+		if oc := n.OnConflict; oc != nil {
+			for _, col := range oc.Columns {
+				if col.Expr != nil {
+					Walk(v, col.Expr)
+				}
+			}
+			if oc.Where != nil {
+				Walk(v, oc.Where)
+			}
+			for _, ue := range oc.Updates {
+				Walk(v, ue.Column)
+				Walk(v, ue.Expr)
+			}
+			if oc.UpdateWhere != nil {
+				Walk(v, oc.UpdateWhere)
+			}
+		}
 		for _, se := range n.Returning {
 			Walk(v, se)
 		}
@@ -91,6 +119,9 @@ func walkChildren(v Visitor, node ast.Node) {
 		}
 
 	case *ast.DeleteStmt:
+		for _, target := range n.Targets {
+			Walk(v, target)
+		}
 		Walk(v, n.Table)
 		if n.Using != nil {
 			Walk(v, n.Using)
@@ -109,13 +140,24 @@ func walkChildren(v Visitor, node ast.Node) {
 	case *ast.UnaryExpr:
 		Walk(v, n.Operand)
 
+	case *ast.PostfixExpr:
+		Walk(v, n.Operand)
+
 	case *ast.ParenExpr:
 		Walk(v, n.Expr)
 
+	case *ast.TupleExpr:
+		for _, e := range n.Exprs {
+			Walk(v, e)
+		}
+
 	case *ast.FuncExpr:
 		for _, arg := range n.Args {
 			Walk(v, arg)
 		}
+		for _, ob := range n.WithinGroup {
+			Walk(v, ob.Expr)
+		}
 		if n.Filter != nil {
 			Walk(v, n.Filter)
 		}
@@ -140,6 +182,13 @@ func walkChildren(v Visitor, node ast.Node) {
 			Walk(v, n.Else)
 		}
 
+	case *ast.GroupingSetExpr:
+		for _, set := range n.Sets {
+			for _, expr := range set {
+				Walk(v, expr)
+			}
+		}
+
 	case *ast.InExpr:
 		Walk(v, n.Expr)
 		for _, val := range n.Values {
@@ -156,7 +205,12 @@ func walkChildren(v Visitor, node ast.Node) {
 
 	case *ast.LikeExpr:
 		Walk(v, n.Expr)
-		Walk(v, n.Pattern)
+		if n.Pattern != nil {
+			Walk(v, n.Pattern)
+		}
+		for _, pattern := range n.Patterns {
+			Walk(v, pattern)
+		}
 		if n.Escape != nil {
 			Walk(v, n.Escape)
 		}
@@ -164,23 +218,50 @@ func walkChildren(v Visitor, node ast.Node) {
 	case *ast.IsExpr:
 		Walk(v, n.Expr)
 
+	case *ast.PeriodExpr:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+
 	case *ast.CastExpr:
 		Walk(v, n.Expr)
 
+	case *ast.ConvertExpr:
+		Walk(v, n.Expr)
+		if n.Style != nil {
+			Walk(v, n.Style)
+		}
+
 	case *ast.Subquery:
 		Walk(v, n.Select)
 
 	case *ast.ExistsExpr:
 		Walk(v, n.Subquery)
 
+	case *ast.UniqueExpr:
+		Walk(v, n.Subquery)
+
 	case *ast.ColName:
 		// Parts are strings, not AST nodes - nothing to walk
 
 	case *ast.AliasedExpr:
 		Walk(v, n.Expr)
 
+	case *ast.StarExpr:
+		for _, item := range n.Replace {
+			Walk(v, item.Expr)
+		}
+
 	case *ast.AliasedTableExpr:
 		Walk(v, n.Expr)
+		if n.Sample != nil {
+			Walk(v, n.Sample.Size)
+			if n.Sample.Repeatable != nil {
+				Walk(v, n.Sample.Repeatable)
+			}
+			if n.Sample.Seed != nil {
+				Walk(v, n.Sample.Seed)
+			}
+		}
 
 	case *ast.JoinExpr:
 		Walk(v, n.Left)
@@ -259,13 +340,88 @@ func walkChildren(v Visitor, node ast.Node) {
 			Walk(v, n.Where)
 		}
 
+	case *ast.CreateSequenceStmt:
+		Walk(v, n.Name)
+
+	case *ast.CreateFunctionStmt:
+		Walk(v, n.Name)
+		for _, arg := range n.Args {
+			if arg.Default != nil {
+				Walk(v, arg.Default)
+			}
+		}
+		if n.Body != nil {
+			Walk(v, n.Body)
+		}
+		if n.Return != nil {
+			Walk(v, n.Return)
+		}
+
 	case *ast.ExplainStmt:
 		Walk(v, n.Stmt)
 
+	case *ast.ShowStmt:
+		if n.Table != nil {
+			Walk(v, n.Table)
+		}
+		if n.Like != nil {
+			Walk(v, n.Like)
+		}
+		if n.Where != nil {
+			Walk(v, n.Where)
+		}
+
+	case *ast.SetSessionStmt:
+		for _, sv := range n.Vars {
+			for _, value := range sv.Values {
+				Walk(v, value)
+			}
+		}
+
+	case *ast.DeclareCursorStmt:
+		Walk(v, n.Query)
+
+	case *ast.FetchStmt:
+		if n.Count != nil {
+			Walk(v, n.Count)
+		}
+
+	case *ast.VacuumStmt:
+		for _, t := range n.Tables {
+			Walk(v, t)
+		}
+
+	case *ast.AnalyzeStmt:
+		for _, t := range n.Tables {
+			Walk(v, t)
+		}
+
+	case *ast.CopyStmt:
+		if n.Table != nil {
+			Walk(v, n.Table)
+		}
+		if n.Query != nil {
+			Walk(v, n.Query)
+		}
+
 	case *ast.SetOp:
 		Walk(v, n.Left)
 		Walk(v, n.Right)
 
+	case *ast.TableStmt:
+		Walk(v, n.Table)
+		for _, ob := range n.OrderBy {
+			Walk(v, ob.Expr)
+		}
+		if n.Limit != nil {
+			if n.Limit.Count != nil {
+				Walk(v, n.Limit.Count)
+			}
+			if n.Limit.Offset != nil {
+				Walk(v, n.Limit.Offset)
+			}
+		}
+
 	case *ast.ValuesStmt:
 		for _, row := range n.Rows {
 			for _, val := range row {
@@ -276,10 +432,53 @@ func walkChildren(v Visitor, node ast.Node) {
 }
 
 // WalkFunc is a convenience wrapper that calls a function for each node.
+// Returning false skips the children of that node, as in go/ast.Inspect;
+// it does not end the walk. Use WalkErr to stop early.
 func WalkFunc(node ast.Node, fn func(ast.Node) bool) {
 	Walk(&funcVisitor{fn: fn}, node)
 }
 
+// SkipChildren is returned by a WalkErr function to skip the children of
+// the current node, like returning false from WalkFunc.
+var SkipChildren = errors.New("skip children")
+
+// SkipAll is returned by a WalkErr function to stop the walk without
+// visiting any further node. WalkErr then returns nil.
+var SkipAll = errors.New("skip all")
+
+// WalkErr calls fn for each node in depth-first order, like WalkFunc,
+// until fn returns an error. SkipChildren skips the children of the node
+// and SkipAll ends the walk; any other error ends the walk and is
+// returned.
+func WalkErr(node ast.Node, fn func(ast.Node) error) error {
+	v := &errVisitor{fn: fn}
+	Walk(v, node)
+	if v.err == SkipAll {
+		return nil
+	}
+	return v.err
+}
+
+type errVisitor struct {
+	fn  func(ast.Node) error
+	err error
+}
+
+func (v *errVisitor) Visit(node ast.Node) Visitor {
+	if v.err != nil {
+		return nil
+	}
+	switch err := v.fn(node); err {
+	case nil:
+		return v
+	case SkipChildren:
+		return nil
+	default:
+		v.err = err
+		return nil
+	}
+}
+
 type funcVisitor struct {
 	fn func(ast.Node) bool
 }
@@ -292,7 +491,7 @@ func (v *funcVisitor) Visit(node ast.Node) Visitor {
 }
 
 // Inspect calls f for each node in the AST.
-// If f returns false, children are not visited.
+// If f returns false, children are not visited; siblings still are.
 func Inspect(node ast.Node, f func(ast.Node) bool) {
 	WalkFunc(node, f)
 }